@@ -5,10 +5,22 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"burpui/internal/app"
 )
 
+type stringList []string
+
+func (l *stringList) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *stringList) Set(v string) error {
+	*l = append(*l, v)
+	return nil
+}
+
 func main() {
 	var listenAddr string
 	var maxBodyBytes int
@@ -17,16 +29,86 @@ func main() {
 	var exportCA string
 	var installCA bool
 	var uninstallCA bool
+	var caScope string
+	var caImport string
+	var caImportKey string
+	var caImportChain string
+	var caImportPass string
+	var caRegenerate bool
+	var dryRun bool
+	var mapLocal stringList
+	var mapRemote stringList
+	var mocksFile string
+	var throttle string
+	var throttleProfiles stringList
+	var throttleRules stringList
+	var hostMap stringList
+	var dnsServer string
+	var upstreamTLS string
+	var mirrorCerts bool
+	var maxLeaves int
+	var passthrough stringList
+	var passthroughAfter int
+	var scope stringList
+	var crawlDepth int
+	var crawlRate float64
+	var crawlMax int
+	var seqCount int
+	var seqRate float64
+	var authzSessions stringList
+	var authzRate float64
+	var macrosPath string
+	var sessionRules stringList
+	var cookieAttach bool
 
 	flag.StringVar(&listenAddr, "listen", ":8080", "endereço do proxy (ex: :8080)")
 	flag.IntVar(&maxBodyBytes, "max-body", 4<<20, "máximo de bytes capturados por body")
 	flag.BoolVar(&mitm, "mitm", false, "habilita MITM HTTPS (requer instalar o CA)")
 	flag.StringVar(&caDir, "ca-dir", filepath.Join(".", "ca"), "diretório para armazenar o CA")
 	flag.StringVar(&exportCA, "export-ca", "", "exporta o certificado raiz (PEM) e sai")
-	flag.BoolVar(&installCA, "install-ca", false, "instala o CA no Trusted Root (CurrentUser) e sai")
-	flag.BoolVar(&uninstallCA, "uninstall-ca", false, "remove o CA do Trusted Root (CurrentUser) e sai")
+	flag.BoolVar(&installCA, "install-ca", false, "instala o CA no trust store do sistema (Windows, macOS, Linux + NSS) e sai")
+	flag.BoolVar(&uninstallCA, "uninstall-ca", false, "remove o CA do trust store do sistema e sai")
+	flag.StringVar(&caScope, "ca-scope", "user", "escopo da instalação do CA no Windows/macOS (user|system)")
+	flag.StringVar(&caImport, "ca-import", "", "importa um CA existente (PEM, DER ou PKCS#12, chave RSA ou ECDSA) e sai")
+	flag.StringVar(&caImportKey, "ca-import-key", "", "chave privada do CA importado, se não estiver no mesmo arquivo")
+	flag.StringVar(&caImportChain, "ca-import-chain", "", "cadeia intermediária enviada junto com cada certificado forjado")
+	flag.StringVar(&caImportPass, "ca-import-pass", "", "senha do arquivo PKCS#12")
+	flag.BoolVar(&caRegenerate, "ca-regenerate", false, "gera um novo CA raiz (o atual vai para backup-*) e sai")
+	flag.BoolVar(&dryRun, "dry-run", false, "com --install-ca/--uninstall-ca, só mostra o que seria feito")
+	flag.Var(&mapLocal, "map-local", "serve arquivo/diretório local para URLs (glob=caminho), pode repetir")
+	flag.Var(&mapRemote, "map-remote", "reescreve destino de URLs (glob=url), pode repetir")
+	flag.StringVar(&mocksFile, "mocks", "", "arquivo JSON com regras de mock/auto-responder")
+	flag.StringVar(&throttle, "throttle", "", "perfil de rede inicial (3g, edge, lossy, hosts ou um --throttle-profile)")
+	flag.Var(&throttleProfiles, "throttle-profile", "perfil de rede (nome:latency=300ms,bw=96k,global,reset=2,5xx=5,timeout=1,timeout-after=30s), pode repetir")
+	flag.Var(&throttleRules, "throttle-rule", "aplica perfil de rede a hosts (glob=perfil), pode repetir")
+	flag.Var(&hostMap, "host-map", "resolve host para um IP fixo (host=ip, aceita *.dominio), pode repetir")
+	flag.StringVar(&dnsServer, "dns", "", "servidor DNS próprio (ex: 1.1.1.1 ou 10.0.0.2:53)")
+	flag.StringVar(&upstreamTLS, "upstream-tls", "", "arquivo JSON com regras de TLS por host (verificação, CA extra, cert cliente, versões)")
+	flag.BoolVar(&mirrorCerts, "mirror-certs", false, "no MITM, copia subject/SANs/validade do certificado real para o certificado forjado")
+	flag.IntVar(&maxLeaves, "leaf-cache-size", 0, "máximo de certificados forjados em cache (0 = padrão)")
+	flag.Var(&passthrough, "passthrough", "no MITM, hosts que passam em túnel sem decodificar (glob, aceita lista com vírgula), pode repetir")
+	flag.IntVar(&passthroughAfter, "passthrough-after", 3, "adiciona o host ao passthrough após N falhas de handshake TLS seguidas (0 = desliga)")
+	flag.Var(&scope, "scope", "prefixo no escopo do site map (host ou url, ! na frente exclui, aceita lista com vírgula), pode repetir")
+	flag.IntVar(&crawlDepth, "crawl-depth", 2, "profundidade máxima do crawler a partir do nó escolhido no site map")
+	flag.Float64Var(&crawlRate, "crawl-rate", 2, "requisições por segundo do crawler (0 = sem limite)")
+	flag.IntVar(&crawlMax, "crawl-max", 200, "máximo de páginas por execução do crawler")
+	flag.IntVar(&seqCount, "seq-count", 500, "tokens coletados pelo sequencer por execução")
+	flag.Float64Var(&seqRate, "seq-rate", 10, "requisições por segundo do sequencer (0 = sem limite)")
+	flag.Var(&authzSessions, "authz-session", "sessão da matriz de autorização (nome=Header: valor|Header: valor), pode repetir")
+	flag.Float64Var(&authzRate, "authz-rate", 5, "requisições por segundo da matriz de autorização (0 = sem limite)")
+	flag.StringVar(&macrosPath, "macros", "", "arquivo JSON com macros, regras de sessão e variáveis (padrão ao salvar: macros.json)")
+	flag.Var(&sessionRules, "session-rule", "regra de sessão (match=URL|header:Nome={{var}}|cookie:nome={{var}}|param:nome={{var}}|on=401|run=MACRO), pode repetir")
+	flag.BoolVar(&cookieAttach, "cookie-attach", false, "anexa os cookies do jar ao enviar pelo Repeater/Compose (alterna com t na tela de cookies)")
 	flag.Parse()
 
+	if flag.NArg() > 0 {
+		if err := runCommand(caDir, flag.Args()); err != nil {
+			fmt.Fprintln(os.Stderr, err.Error())
+			os.Exit(1)
+		}
+		return
+	}
+
 	if exportCA != "" {
 		if err := app.ExportCA(caDir, exportCA); err != nil {
 			fmt.Fprintln(os.Stderr, err.Error())
@@ -36,28 +118,136 @@ func main() {
 		return
 	}
 
+	if caImport != "" {
+		thumb, backup, err := app.ImportCA(caDir, caImport, caImportKey, caImportChain, caImportPass)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err.Error())
+			os.Exit(1)
+		}
+		if backup != "" {
+			fmt.Fprintf(os.Stdout, "backup do CA anterior: %s\n", backup)
+		}
+		fmt.Fprintf(os.Stdout, "importado (thumbprint): %s\n", thumb)
+		return
+	}
+
+	if caRegenerate {
+		thumb, backup, err := app.RegenerateCA(caDir)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err.Error())
+			os.Exit(1)
+		}
+		if backup != "" {
+			fmt.Fprintf(os.Stdout, "backup do CA anterior: %s\n", backup)
+		}
+		fmt.Fprintf(os.Stdout, "novo CA (thumbprint): %s\n", thumb)
+		return
+	}
+
 	if installCA {
-		thumb, err := app.InstallCA(caDir)
+		thumb, err := app.InstallCA(caDir, caScope, dryRun)
 		if err != nil {
 			fmt.Fprintln(os.Stderr, err.Error())
 			os.Exit(1)
 		}
+		if dryRun {
+			fmt.Fprintf(os.Stdout, "thumbprint: %s\n", thumb)
+			return
+		}
 		fmt.Fprintf(os.Stdout, "instalado (thumbprint): %s\n", thumb)
 		return
 	}
 
 	if uninstallCA {
-		thumb, err := app.UninstallCA(caDir)
+		thumb, err := app.UninstallCA(caDir, caScope, dryRun)
 		if err != nil {
 			fmt.Fprintln(os.Stderr, err.Error())
 			os.Exit(1)
 		}
+		if dryRun {
+			fmt.Fprintf(os.Stdout, "thumbprint: %s\n", thumb)
+			return
+		}
 		fmt.Fprintf(os.Stdout, "removido (thumbprint): %s\n", thumb)
 		return
 	}
 
-	if err := app.Run(app.Config{ListenAddr: listenAddr, MaxBodyBytes: maxBodyBytes, MITM: mitm, CADir: caDir}); err != nil {
+	cfg := app.Config{
+		ListenAddr:   listenAddr,
+		MaxBodyBytes: maxBodyBytes,
+		MITM:         mitm,
+		CADir:        caDir,
+		MapLocal:     mapLocal,
+		MapRemote:    mapRemote,
+		MocksFile:    mocksFile,
+
+		Throttle:         throttle,
+		ThrottleProfiles: throttleProfiles,
+		ThrottleRules:    throttleRules,
+
+		HostMap:   hostMap,
+		DNSServer: dnsServer,
+
+		UpstreamTLSFile: upstreamTLS,
+		MirrorCerts:     mirrorCerts,
+		MaxLeaves:       maxLeaves,
+
+		Passthrough:      passthrough,
+		PassthroughAfter: passthroughAfter,
+
+		Scope:      scope,
+		CrawlDepth: crawlDepth,
+		CrawlRate:  crawlRate,
+		CrawlMax:   crawlMax,
+
+		SeqCount: seqCount,
+		SeqRate:  seqRate,
+
+		AuthzSessions: authzSessions,
+		AuthzRate:     authzRate,
+
+		MacrosFile:   macrosPath,
+		SessionRules: sessionRules,
+
+		CookieAttach: cookieAttach,
+	}
+	if err := app.Run(cfg); err != nil {
 		fmt.Fprintln(os.Stderr, err.Error())
 		os.Exit(1)
 	}
 }
+
+func runCommand(caDir string, args []string) error {
+	switch args[0] {
+	case "leaves":
+		sub := "list"
+		if len(args) > 1 {
+			sub = args[1]
+		}
+		match := ""
+		if len(args) > 2 {
+			match = args[2]
+		}
+		switch sub {
+		case "list":
+			leaves, err := app.ListLeaves(caDir)
+			if err != nil {
+				return err
+			}
+			for _, l := range leaves {
+				fmt.Fprintf(os.Stdout, "%-40s expira %s  usado %s  %s\n", l.Key, l.NotAfter.Format("2006-01-02 15:04"), l.LastUsed.Format("2006-01-02 15:04"), strings.Join(l.DNSNames, ","))
+			}
+			fmt.Fprintf(os.Stdout, "%d certificados em cache\n", len(leaves))
+			return nil
+		case "purge":
+			n, err := app.PurgeLeaves(caDir, match)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(os.Stdout, "%d certificados removidos\n", n)
+			return nil
+		}
+		return fmt.Errorf("uso: burpui [--ca-dir dir] leaves list|purge [filtro]")
+	}
+	return fmt.Errorf("comando desconhecido: %s", args[0])
+}
@@ -5,12 +5,15 @@ import (
 	"fmt"
 	"os"
 	"os/signal"
+	"path/filepath"
 	"syscall"
 	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 
+	"burpui/internal/jar"
 	"burpui/internal/proxy"
+	"burpui/internal/repeater"
 	"burpui/internal/tui"
 )
 
@@ -19,12 +22,64 @@ type Config struct {
 	MaxBodyBytes int
 	MITM         bool
 	CADir        string
+	MapLocal     []string
+	MapRemote    []string
+	MocksFile    string
+
+	Throttle         string
+	ThrottleProfiles []string
+	ThrottleRules    []string
+
+	HostMap   []string
+	DNSServer string
+
+	UpstreamTLSFile string
+	MirrorCerts     bool
+	MaxLeaves       int
+
+	Passthrough      []string
+	PassthroughAfter int
+
+	Scope      []string
+	CrawlDepth int
+	CrawlRate  float64
+	CrawlMax   int
+
+	SeqCount int
+	SeqRate  float64
+
+	AuthzSessions []string
+	AuthzRate     float64
+
+	MacrosFile   string
+	SessionRules []string
+
+	CookieAttach bool
 }
 
 func Run(cfg Config) error {
 	flowCh := make(chan *proxy.FlowSnapshot, 1024)
 	ctrl := proxy.NewController()
-	px, err := proxy.New(proxy.Config{ListenAddr: cfg.ListenAddr, MaxBodyBytes: cfg.MaxBodyBytes, MITM: cfg.MITM, CADir: cfg.CADir}, ctrl, flowCh)
+	if err := applyMapRules(ctrl, cfg); err != nil {
+		return err
+	}
+	if err := loadMocks(ctrl, cfg.MocksFile); err != nil {
+		return err
+	}
+	if err := applyThrottle(ctrl, cfg); err != nil {
+		return err
+	}
+	applyPassthrough(ctrl, cfg)
+	res, err := newResolver(cfg)
+	if err != nil {
+		return err
+	}
+	upTLS, err := loadUpstreamTLS(cfg.UpstreamTLSFile)
+	if err != nil {
+		return err
+	}
+	cookies := jar.New()
+	px, err := proxy.New(proxy.Config{ListenAddr: cfg.ListenAddr, MaxBodyBytes: cfg.MaxBodyBytes, MITM: cfg.MITM, CADir: cfg.CADir, Resolver: res, UpstreamTLS: upTLS, MirrorCerts: cfg.MirrorCerts, MaxLeaves: cfg.MaxLeaves, Jar: cookies}, ctrl, flowCh)
 	if err != nil {
 		return err
 	}
@@ -37,9 +92,29 @@ func Run(cfg Config) error {
 		errCh <- px.Serve(ctx)
 	}()
 
+	scope := newScope(cfg)
+	crawl, err := newCrawl(cfg, scope)
+	if err != nil {
+		return err
+	}
+
+	rpOpts := repeater.Options{Timeout: 15 * time.Second, DialContext: res.DialContext, TLSConfig: upTLS.ConfigFor}
+	matrix, err := newAuthz(cfg, scope, rpOpts)
+	if err != nil {
+		return err
+	}
+	go matrix.Run(ctx)
+	macros, err := newMacros(cfg, rpOpts)
+	if err != nil {
+		return err
+	}
+	ctrl.SetSessionHandler(macros)
+
 	model := tui.New(tui.Config{
 		ListenAddr: cfg.ListenAddr,
 		FlowCh:     flowCh,
+		Throttle:   ctrl.ThrottleMode(),
+		Repeater:   rpOpts,
 		SetIntercept: func(on bool) {
 			ctrl.SetIntercept(on)
 		},
@@ -55,6 +130,21 @@ func Run(cfg Config) error {
 		RemoveBreakpoint: func(id int64) {
 			ctrl.RemoveBreakpoint(id)
 		},
+		ToggleFlowMock: func(f *proxy.Flow) (bool, error) {
+			return toggleFlowMock(ctrl, f)
+		},
+		CycleThrottle: func() string {
+			return ctrl.CycleThrottle()
+		},
+		FiltersFile: filtersFile(),
+		Scope:       scope,
+		Crawl:       crawl,
+		Sequence:    newSequence(cfg, rpOpts),
+		Authz:       matrix,
+		Macros:      macros,
+		MacrosFile:  macrosFile(cfg),
+		Jar:         cookies,
+		JarAttach:   cfg.CookieAttach,
 	})
 
 	p := tea.NewProgram(model, tea.WithAltScreen())
@@ -85,3 +175,11 @@ func Run(cfg Config) error {
 		return nil
 	}
 }
+
+func filtersFile() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "burpui", "filters.json")
+}
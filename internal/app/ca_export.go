@@ -18,10 +18,63 @@ func ExportCA(caDir string, outPath string) error {
 	return os.WriteFile(outPath, st.RootCertPEM(), 0o644)
 }
 
-func InstallCA(caDir string) (string, error) {
-	return ca.InstallRootCA(caDir, ca.ScopeCurrentUser)
+func installOptions(scope string, dryRun bool) (ca.InstallOptions, error) {
+	opts := ca.InstallOptions{DryRun: dryRun, Log: os.Stdout}
+	switch scope {
+	case "", "user":
+		opts.Scope = ca.ScopeCurrentUser
+	case "system":
+		opts.Scope = ca.ScopeSystem
+	default:
+		return opts, fmt.Errorf("escopo inválido: %q (user|system)", scope)
+	}
+	return opts, nil
+}
+
+func InstallCA(caDir string, scope string, dryRun bool) (string, error) {
+	opts, err := installOptions(scope, dryRun)
+	if err != nil {
+		return "", err
+	}
+	return ca.InstallRootCA(caDir, opts)
+}
+
+func UninstallCA(caDir string, scope string, dryRun bool) (string, error) {
+	opts, err := installOptions(scope, dryRun)
+	if err != nil {
+		return "", err
+	}
+	return ca.UninstallRootCA(caDir, opts)
 }
 
-func UninstallCA(caDir string) (string, error) {
-	return ca.UninstallRootCA(caDir, ca.ScopeCurrentUser)
+func ListLeaves(caDir string) ([]ca.LeafInfo, error) {
+	st, err := ca.Load(caDir)
+	if err != nil {
+		return nil, err
+	}
+	return st.ListLeaves()
+}
+
+func PurgeLeaves(caDir string, match string) (int, error) {
+	st, err := ca.Load(caDir)
+	if err != nil {
+		return 0, err
+	}
+	return st.PurgeLeaves(match)
+}
+
+func ImportCA(caDir, certFile, keyFile, chainFile, password string) (thumbprint string, backup string, err error) {
+	st, backup, err := ca.Import(caDir, ca.ImportOptions{CertFile: certFile, KeyFile: keyFile, ChainFile: chainFile, Password: password})
+	if err != nil {
+		return "", backup, err
+	}
+	return st.RootThumbprintSHA1(), backup, nil
+}
+
+func RegenerateCA(caDir string) (thumbprint string, backup string, err error) {
+	st, backup, err := ca.Regenerate(caDir)
+	if err != nil {
+		return "", backup, err
+	}
+	return st.RootThumbprintSHA1(), backup, nil
 }
@@ -0,0 +1,302 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"burpui/internal/authz"
+	"burpui/internal/crawler"
+	"burpui/internal/macro"
+	"burpui/internal/proxy"
+	"burpui/internal/repeater"
+	"burpui/internal/resolver"
+	"burpui/internal/sequencer"
+	"burpui/internal/sitemap"
+)
+
+func splitRule(flagName, s string) (string, string, error) {
+	match, target, ok := strings.Cut(s, "=")
+	match = strings.TrimSpace(match)
+	target = strings.TrimSpace(target)
+	if !ok || match == "" || target == "" {
+		return "", "", fmt.Errorf("--%s inválido (esperado glob=destino): %q", flagName, s)
+	}
+	return match, target, nil
+}
+
+func applyMapRules(ctrl *proxy.Controller, cfg Config) error {
+	for _, s := range cfg.MapLocal {
+		match, path, err := splitRule("map-local", s)
+		if err != nil {
+			return err
+		}
+		ctrl.AddMapLocal(match, path)
+	}
+	for _, s := range cfg.MapRemote {
+		match, to, err := splitRule("map-remote", s)
+		if err != nil {
+			return err
+		}
+		if _, err := ctrl.AddMapRemote(match, to, false); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type mockSpec struct {
+	Match    string            `json:"match"`
+	Method   string            `json:"method"`
+	Status   int               `json:"status"`
+	Headers  map[string]string `json:"headers"`
+	Body     string            `json:"body"`
+	BodyFile string            `json:"body_file"`
+	Template bool              `json:"template"`
+	Delay    string            `json:"delay"`
+}
+
+func loadMocks(ctrl *proxy.Controller, path string) error {
+	if strings.TrimSpace(path) == "" {
+		return nil
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	var specs []mockSpec
+	if err := json.Unmarshal(b, &specs); err != nil {
+		return fmt.Errorf("mocks %s: %w", path, err)
+	}
+	for i, sp := range specs {
+		r := proxy.MockRule{Enabled: true, Match: sp.Match, Method: sp.Method, Status: sp.Status, Body: sp.Body, Template: sp.Template}
+		if len(sp.Headers) > 0 {
+			r.Header = http.Header{}
+			for k, v := range sp.Headers {
+				r.Header.Set(k, v)
+			}
+		}
+		if sp.BodyFile != "" {
+			bodyPath := sp.BodyFile
+			if !filepath.IsAbs(bodyPath) {
+				bodyPath = filepath.Join(filepath.Dir(path), bodyPath)
+			}
+			body, err := os.ReadFile(bodyPath)
+			if err != nil {
+				return fmt.Errorf("mocks[%d]: %w", i, err)
+			}
+			r.Body = string(body)
+		}
+		if sp.Delay != "" {
+			d, err := time.ParseDuration(sp.Delay)
+			if err != nil {
+				return fmt.Errorf("mocks[%d]: delay: %w", i, err)
+			}
+			r.Delay = d
+		}
+		if _, err := ctrl.AddMock(r); err != nil {
+			return fmt.Errorf("mocks[%d]: %w", i, err)
+		}
+	}
+	return nil
+}
+
+func toggleFlowMock(ctrl *proxy.Controller, f *proxy.Flow) (bool, error) {
+	for _, r := range ctrl.ListMocks() {
+		if r.FromFlow == f.ID {
+			ctrl.RemoveMock(r.ID)
+			return false, nil
+		}
+	}
+	if f.Pending || (f.StatusCode == 0 && f.Error != "") {
+		return false, fmt.Errorf("fluxo sem resposta")
+	}
+	r, err := proxy.MockFromFlow(f)
+	if err != nil {
+		return false, err
+	}
+	_, err = ctrl.AddMock(r)
+	return err == nil, err
+}
+
+func applyThrottle(ctrl *proxy.Controller, cfg Config) error {
+	for _, s := range cfg.ThrottleProfiles {
+		tp, err := proxy.ParseThrottleProfile(s)
+		if err != nil {
+			return err
+		}
+		ctrl.SetThrottleProfile(tp)
+	}
+	for _, s := range cfg.ThrottleRules {
+		match, profile, err := splitRule("throttle-rule", s)
+		if err != nil {
+			return err
+		}
+		if _, err := ctrl.AddThrottleRule(match, profile); err != nil {
+			return err
+		}
+	}
+	return ctrl.SetThrottleMode(strings.TrimSpace(cfg.Throttle))
+}
+
+func applyPassthrough(ctrl *proxy.Controller, cfg Config) {
+	for _, s := range cfg.Passthrough {
+		for _, match := range strings.Split(s, ",") {
+			if match = strings.TrimSpace(match); match != "" {
+				ctrl.AddPassthrough(match)
+			}
+		}
+	}
+	ctrl.SetPassthroughAfter(cfg.PassthroughAfter)
+}
+
+func newScope(cfg Config) *sitemap.Scope {
+	scope := sitemap.NewScope()
+	for _, s := range cfg.Scope {
+		for _, prefix := range strings.Split(s, ",") {
+			prefix = strings.TrimSpace(prefix)
+			if strings.HasPrefix(prefix, "!") {
+				scope.Exclude(strings.TrimPrefix(prefix, "!"))
+			} else if prefix != "" {
+				scope.Include(prefix)
+			}
+		}
+	}
+	return scope
+}
+
+func newCrawl(cfg Config, scope *sitemap.Scope) (func(context.Context, []string, func(crawler.Stats)) (crawler.Stats, error), error) {
+	c, err := crawler.New(crawler.Options{
+		ProxyURL: proxyURL(cfg.ListenAddr),
+		MaxDepth: cfg.CrawlDepth,
+		Rate:     cfg.CrawlRate,
+		MaxPages: cfg.CrawlMax,
+		MaxBody:  int64(cfg.MaxBodyBytes),
+		Scope:    scope,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return c.Run, nil
+}
+
+func newSequence(cfg Config, opts repeater.Options) func(context.Context, string, sequencer.Extractor, func(sequencer.Stats)) ([]string, sequencer.Stats, error) {
+	o := sequencer.Options{Count: cfg.SeqCount, Rate: cfg.SeqRate, Repeater: opts}
+	return func(ctx context.Context, raw string, ex sequencer.Extractor, progress func(sequencer.Stats)) ([]string, sequencer.Stats, error) {
+		return sequencer.Collect(ctx, raw, ex, o, progress)
+	}
+}
+
+func newAuthz(cfg Config, scope *sitemap.Scope, opts repeater.Options) (*authz.Matrix, error) {
+	var sessions []authz.Session
+	for _, spec := range cfg.AuthzSessions {
+		s, err := authz.ParseSession(spec)
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, s)
+	}
+	return authz.New(authz.Options{Rate: cfg.AuthzRate, Scope: scope, Repeater: opts}, sessions...), nil
+}
+
+func newMacros(cfg Config, opts repeater.Options) (*macro.Engine, error) {
+	e := macro.New(macro.Options{Repeater: opts})
+	if path := strings.TrimSpace(cfg.MacrosFile); path != "" {
+		if err := e.Load(path); err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+	}
+	for _, spec := range cfg.SessionRules {
+		r, err := macro.ParseRule(spec)
+		if err != nil {
+			return nil, err
+		}
+		e.AddRule(r)
+	}
+	return e, nil
+}
+
+func macrosFile(cfg Config) string {
+	if path := strings.TrimSpace(cfg.MacrosFile); path != "" {
+		return path
+	}
+	return "macros.json"
+}
+
+func proxyURL(listen string) string {
+	host, port, err := net.SplitHostPort(listen)
+	if err != nil {
+		return "http://" + listen
+	}
+	if ip := net.ParseIP(host); host == "" || (ip != nil && ip.IsUnspecified()) {
+		host = "127.0.0.1"
+	}
+	return "http://" + net.JoinHostPort(host, port)
+}
+
+func newResolver(cfg Config) (*resolver.Resolver, error) {
+	hosts := map[string]string{}
+	for _, s := range cfg.HostMap {
+		host, ip, err := splitRule("host-map", s)
+		if err != nil {
+			return nil, err
+		}
+		hosts[host] = ip
+	}
+	return resolver.New(hosts, cfg.DNSServer)
+}
+
+type upstreamTLSSpec struct {
+	Match          string   `json:"match"`
+	Insecure       bool     `json:"insecure"`
+	CAFile         string   `json:"ca_file"`
+	ClientCert     string   `json:"client_cert"`
+	ClientKey      string   `json:"client_key"`
+	PKCS12         string   `json:"pkcs12"`
+	PKCS12Password string   `json:"pkcs12_password"`
+	MinVersion     string   `json:"min_version"`
+	MaxVersion     string   `json:"max_version"`
+	CipherSuites   []string `json:"cipher_suites"`
+}
+
+func loadUpstreamTLS(path string) (*proxy.UpstreamTLS, error) {
+	if strings.TrimSpace(path) == "" {
+		return proxy.NewUpstreamTLS(nil)
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var specs []upstreamTLSSpec
+	if err := json.Unmarshal(b, &specs); err != nil {
+		return nil, fmt.Errorf("upstream tls %s: %w", path, err)
+	}
+	rel := func(p string) string {
+		if p == "" || filepath.IsAbs(p) {
+			return p
+		}
+		return filepath.Join(filepath.Dir(path), p)
+	}
+	rules := make([]proxy.UpstreamTLSRule, 0, len(specs))
+	for _, sp := range specs {
+		rules = append(rules, proxy.UpstreamTLSRule{
+			Match:        sp.Match,
+			Insecure:     sp.Insecure,
+			CAFile:       rel(sp.CAFile),
+			ClientCert:   rel(sp.ClientCert),
+			ClientKey:    rel(sp.ClientKey),
+			PKCS12File:   rel(sp.PKCS12),
+			PKCS12Pass:   sp.PKCS12Password,
+			MinVersion:   sp.MinVersion,
+			MaxVersion:   sp.MaxVersion,
+			CipherSuites: sp.CipherSuites,
+		})
+	}
+	return proxy.NewUpstreamTLS(rules)
+}
@@ -0,0 +1,186 @@
+package authz
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"burpui/internal/httpraw"
+	"burpui/internal/proxy"
+	"burpui/internal/repeater"
+	"burpui/internal/sitemap"
+)
+
+const raw = "GET http://app.test/admin/users HTTP/1.1\r\nHost: app.test\r\nCookie: sid=owner\r\nAccept: */*\r\nX-Api-Key: k1\r\n\r\n"
+
+func TestParseSession(t *testing.T) {
+	s, err := ParseSession("user=Cookie: sid=u1|X-Api-Key: k2")
+	if err != nil || s.Name != "user" || s.Header.Get("Cookie") != "sid=u1" || s.Header.Get("X-Api-Key") != "k2" || !s.Enabled {
+		t.Fatalf("session = %+v %v", s, err)
+	}
+	for _, bad := range []string{"nome", "=Cookie: a", "x=", "x=bad header: v", "x=semdoispontos"} {
+		if _, err := ParseSession(bad); err == nil {
+			t.Fatalf("expected error for %q", bad)
+		}
+	}
+}
+
+func TestApplyRaw(t *testing.T) {
+	s, _ := ParseSession("user=Cookie: sid=u1")
+	out := ApplyRaw(raw, s, []string{"Authorization", "x-api-key"})
+	req, _, err := httpraw.ParseRequest(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if req.Header.Get("Cookie") != "sid=u1" || req.Header.Get("X-Api-Key") != "" || req.Header.Get("Accept") != "*/*" || len(req.Header.Values("Cookie")) != 1 {
+		t.Fatalf("headers = %v", req.Header)
+	}
+
+	anon := ApplyRaw("POST http://app.test/x HTTP/1.1\nHost: app.test\nCookie: a=1\n\nbody=1", Session{Name: UnauthName, Unauth: true}, []string{"Cookie"})
+	if strings.Contains(anon, "Cookie") || !strings.HasSuffix(anon, "\n\nbody=1") {
+		t.Fatalf("unauth raw = %q", anon)
+	}
+}
+
+func TestJudge(t *testing.T) {
+	cases := []struct {
+		orig, status int
+		sim          float64
+		loc          string
+		want         Verdict
+	}{
+		{200, 200, 0.99, "", Bypass},
+		{200, 200, 0.4, "", Unclear},
+		{200, 403, 0, "", Enforced},
+		{200, 302, 0, "/login?next=/admin", Enforced},
+		{200, 302, 0, "/home", Enforced},
+		{200, 404, 0.1, "", Enforced},
+		{404, 404, 1, "", Unclear},
+		{500, 200, 0.1, "", Enforced},
+	}
+	for _, c := range cases {
+		if got := Judge(c.orig, c.status, c.sim, c.loc, 0.9); got != c.want {
+			t.Fatalf("Judge(%d,%d,%.1f,%q) = %v, want %v", c.orig, c.status, c.sim, c.loc, got, c.want)
+		}
+	}
+}
+
+func TestMatrix(t *testing.T) {
+	admin := "<html>lista de usuários: alice, bob, carol</html>"
+	send := func(raw string, _ repeater.Options) (*repeater.Result, error) {
+		req, _, err := httpraw.ParseRequest(raw)
+		if err != nil {
+			return nil, err
+		}
+		switch req.Header.Get("Cookie") {
+		case "sid=user":
+			return &repeater.Result{StatusCode: 200, Header: http.Header{}, Body: []byte(admin)}, nil
+		case "sid=guest":
+			return &repeater.Result{StatusCode: 200, Header: http.Header{}, Body: []byte("<html>bem-vindo, guest</html>")}, nil
+		}
+		return &repeater.Result{StatusCode: 302, Header: http.Header{"Location": {"/login"}}}, nil
+	}
+	user, _ := ParseSession("user=Cookie: sid=user")
+	guest, _ := ParseSession("guest=Cookie: sid=guest")
+	scope := sitemap.NewScope()
+	scope.Include("app.test")
+	m := New(Options{Send: send, Scope: scope}, user, guest)
+
+	f := &proxy.Flow{ID: 7, Method: "GET", URL: "http://app.test/admin/users", StatusCode: 200, ResponseBody: []byte(admin)}
+	if !m.Submit(f, raw) {
+		t.Fatalf("submit rejected")
+	}
+	if m.Submit(f, raw) || m.Submit(&proxy.Flow{ID: 8, Method: "GET", URL: "http://app.test/admin/users", StatusCode: 200}, raw) {
+		t.Fatalf("duplicate accepted")
+	}
+	if m.Submit(&proxy.Flow{ID: 9, Method: "GET", URL: "http://other.test/", StatusCode: 200}, raw) ||
+		m.Submit(&proxy.Flow{ID: 10, Method: "GET", URL: "http://app.test/logo.png", StatusCode: 200}, raw) {
+		t.Fatalf("out of scope or static accepted")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go m.Run(ctx)
+
+	deadline := time.After(2 * time.Second)
+	for {
+		rows := m.Rows()
+		if len(rows) == 1 && rows[0].Cells[2].Verdict != Pending {
+			got := []Verdict{rows[0].Cells[0].Verdict, rows[0].Cells[1].Verdict, rows[0].Cells[2].Verdict}
+			if got[0] != Enforced || got[1] != Bypass || got[2] != Unclear || rows[0].Worst() != Bypass {
+				t.Fatalf("verdicts = %v (%+v)", got, rows[0].Cells)
+			}
+			break
+		}
+		select {
+		case <-m.Updates():
+		case <-deadline:
+			t.Fatalf("timeout waiting for replays: %+v", m.Rows())
+		}
+	}
+
+	m.SetEnabled(false)
+	if m.Submit(&proxy.Flow{ID: 11, Method: "GET", URL: "http://app.test/other", StatusCode: 200}, raw) {
+		t.Fatalf("disabled matrix accepted flow")
+	}
+	if err := m.AddSession(user); err == nil {
+		t.Fatalf("duplicate session accepted")
+	}
+	m.ToggleSession(UnauthName)
+	if !m.Replay(7) || len(m.Rows()[0].Cells) != 2 {
+		t.Fatalf("replay did not use enabled sessions only: %+v", m.Rows()[0].Cells)
+	}
+}
+
+func TestMatrix_Compressed(t *testing.T) {
+	page := "<html>lista de usuários: alice, bob, carol</html>"
+	var zipped bytes.Buffer
+	zw := gzip.NewWriter(&zipped)
+	zw.Write([]byte(page))
+	zw.Close()
+
+	sent := make(chan string, 4)
+	send := func(raw string, _ repeater.Options) (*repeater.Result, error) {
+		sent <- raw
+		return &repeater.Result{StatusCode: 200, Header: http.Header{"Content-Encoding": {"gzip"}}, Body: zipped.Bytes()}, nil
+	}
+	user, _ := ParseSession("user=Cookie: sid=user")
+	m := New(Options{Send: send}, user)
+	m.ToggleSession(UnauthName)
+
+	f := &proxy.Flow{ID: 1, Method: "GET", URL: "http://app.test/admin/users", StatusCode: 200,
+		ResponseHeader: http.Header{"Content-Encoding": {"gzip"}}, ResponseBody: zipped.Bytes()}
+	if !m.Submit(f, strings.Replace(raw, "Accept: */*\r\n", "Accept-Encoding: gzip, br\r\n", 1)) {
+		t.Fatalf("submit rejected")
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go m.Run(ctx)
+
+	select {
+	case r := <-sent:
+		if strings.Contains(r, "Accept-Encoding") {
+			t.Fatalf("Accept-Encoding kept: %q", r)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatalf("timeout waiting for replay")
+	}
+	deadline := time.After(2 * time.Second)
+	for {
+		if c := m.Rows()[0].Cells[0]; c.Verdict != Pending {
+			if c.Verdict != Bypass || c.Similarity < 0.99 {
+				t.Fatalf("cell = %+v", c)
+			}
+			return
+		}
+		select {
+		case <-m.Updates():
+		case <-deadline:
+			t.Fatalf("timeout: %+v", m.Rows())
+		}
+	}
+}
@@ -0,0 +1,334 @@
+package authz
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"burpui/internal/comparer"
+	"burpui/internal/crawler"
+	"burpui/internal/httpraw"
+	"burpui/internal/proxy"
+	"burpui/internal/repeater"
+	"burpui/internal/sitemap"
+)
+
+type Verdict int
+
+const (
+	Pending Verdict = iota
+	Enforced
+	Failed
+	Unclear
+	Bypass
+)
+
+func (v Verdict) String() string {
+	switch v {
+	case Enforced:
+		return "bloqueado"
+	case Unclear:
+		return "verificar"
+	case Bypass:
+		return "BYPASS?"
+	case Failed:
+		return "erro"
+	}
+	return "…"
+}
+
+type Cell struct {
+	Session    string
+	Status     int
+	Length     int
+	Similarity float64
+	Err        string
+	Verdict    Verdict
+}
+
+type Row struct {
+	FlowID int64
+	Method string
+	URL    string
+	Status int
+	Length int
+	Cells  []Cell
+	raw    string
+	body   []byte
+}
+
+func (r Row) Worst() Verdict {
+	w := Pending
+	for _, c := range r.Cells {
+		if c.Verdict > w {
+			w = c.Verdict
+		}
+	}
+	return w
+}
+
+type Options struct {
+	Rate      float64
+	Threshold float64
+	Scope     *sitemap.Scope
+	Repeater  repeater.Options
+	Send      func(raw string, opts repeater.Options) (*repeater.Result, error)
+}
+
+type job struct {
+	row  *Row
+	sess []Session
+}
+
+type Matrix struct {
+	opts Options
+
+	mu       sync.Mutex
+	sessions []Session
+	rows     []*Row
+	byID     map[int64]*Row
+	seen     map[string]bool
+	enabled  bool
+
+	queue   chan job
+	updates chan struct{}
+}
+
+func New(opts Options, sessions ...Session) *Matrix {
+	if opts.Threshold <= 0 {
+		opts.Threshold = 0.9
+	}
+	if opts.Send == nil {
+		opts.Send = repeater.Send
+	}
+	m := &Matrix{
+		opts:    opts,
+		byID:    map[int64]*Row{},
+		seen:    map[string]bool{},
+		enabled: len(sessions) > 0,
+		queue:   make(chan job, 1024),
+		updates: make(chan struct{}, 1),
+	}
+	m.sessions = append(m.sessions, Session{Name: UnauthName, Unauth: true, Enabled: true})
+	m.sessions = append(m.sessions, sessions...)
+	return m
+}
+
+func (m *Matrix) Sessions() []Session {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]Session(nil), m.sessions...)
+}
+
+func (m *Matrix) AddSession(s Session) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, x := range m.sessions {
+		if x.Name == s.Name {
+			return fmt.Errorf("authz: sessão %s já existe", s.Name)
+		}
+	}
+	m.sessions = append(m.sessions, s)
+	return nil
+}
+
+func (m *Matrix) ToggleSession(name string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i := range m.sessions {
+		if m.sessions[i].Name == name {
+			m.sessions[i].Enabled = !m.sessions[i].Enabled
+			return m.sessions[i].Enabled
+		}
+	}
+	return false
+}
+
+func (m *Matrix) SetEnabled(on bool) {
+	m.mu.Lock()
+	m.enabled = on
+	m.mu.Unlock()
+}
+
+func (m *Matrix) Enabled() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.enabled
+}
+
+func (m *Matrix) Updates() <-chan struct{} {
+	return m.updates
+}
+
+func (m *Matrix) Rows() []Row {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	out := make([]Row, 0, len(m.rows))
+	for _, r := range m.rows {
+		c := *r
+		c.Cells = append([]Cell(nil), r.Cells...)
+		out = append(out, c)
+	}
+	return out
+}
+
+func (m *Matrix) Submit(f *proxy.Flow, raw string) bool {
+	if f == nil || f.Pending || f.Tunnel || f.Error != "" || f.Method == http.MethodConnect || f.StatusCode == 0 {
+		return false
+	}
+	u, err := url.Parse(f.URL)
+	if err != nil || crawler.IsStatic(u) {
+		return false
+	}
+	if m.opts.Scope != nil && !m.opts.Scope.InScope(f.URL) {
+		return false
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if !m.enabled || m.byID[f.ID] != nil {
+		return false
+	}
+	key := f.Method + " " + f.URL + " " + string(f.RequestBody)
+	if m.seen[key] {
+		return false
+	}
+	m.seen[key] = true
+	r := &Row{FlowID: f.ID, Method: f.Method, URL: f.URL, Status: f.StatusCode, Length: len(f.ResponseBody), raw: raw, body: decoded(f.ResponseHeader, f.ResponseBody)}
+	return m.enqueue(r)
+}
+
+func (m *Matrix) Replay(id int64) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	r := m.byID[id]
+	if r == nil {
+		return false
+	}
+	return m.enqueue(r)
+}
+
+func (m *Matrix) enqueue(r *Row) bool {
+	var sess []Session
+	for _, s := range m.sessions {
+		if s.Enabled {
+			sess = append(sess, s)
+		}
+	}
+	if len(sess) == 0 {
+		return false
+	}
+	r.Cells = make([]Cell, len(sess))
+	for i, s := range sess {
+		r.Cells[i] = Cell{Session: s.Name}
+	}
+	select {
+	case m.queue <- job{row: r, sess: sess}:
+	default:
+		return false
+	}
+	if m.byID[r.FlowID] == nil {
+		m.byID[r.FlowID] = r
+		m.rows = append(m.rows, r)
+	}
+	m.notify()
+	return true
+}
+
+func (m *Matrix) notify() {
+	select {
+	case m.updates <- struct{}{}:
+	default:
+	}
+}
+
+func (m *Matrix) Run(ctx context.Context) {
+	var tick <-chan time.Time
+	if m.opts.Rate > 0 {
+		t := time.NewTicker(time.Duration(float64(time.Second) / m.opts.Rate))
+		defer t.Stop()
+		tick = t.C
+	}
+	for {
+		var j job
+		select {
+		case <-ctx.Done():
+			return
+		case j = <-m.queue:
+		}
+		for i, s := range j.sess {
+			if tick != nil {
+				select {
+				case <-ctx.Done():
+					return
+				case <-tick:
+				}
+			}
+			c := m.replay(j.row, s)
+			m.mu.Lock()
+			if i < len(j.row.Cells) && j.row.Cells[i].Session == s.Name {
+				j.row.Cells[i] = c
+			}
+			m.notify()
+			m.mu.Unlock()
+		}
+	}
+}
+
+func (m *Matrix) replay(r *Row, s Session) Cell {
+	c := Cell{Session: s.Name}
+	res, err := m.opts.Send(ApplyRaw(r.raw, s, append(m.credentialHeaders(), "Accept-Encoding")), m.opts.Repeater)
+	if err != nil {
+		c.Err = err.Error()
+		c.Verdict = Failed
+		return c
+	}
+	c.Status = res.StatusCode
+	c.Length = len(res.Body)
+	c.Similarity = comparer.Similarity(string(r.body), string(decoded(res.Header, res.Body)))
+	c.Verdict = Judge(r.Status, c.Status, c.Similarity, res.Header.Get("Location"), m.opts.Threshold)
+	return c
+}
+
+func decoded(h http.Header, body []byte) []byte {
+	if b, err := httpraw.DecodeBody(h, body); err == nil {
+		return b
+	}
+	return body
+}
+
+func (m *Matrix) credentialHeaders() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	keys := []string{"Cookie", "Authorization"}
+	for _, s := range m.sessions {
+		for k := range s.Header {
+			keys = append(keys, k)
+		}
+	}
+	return keys
+}
+
+func Judge(orig, status int, similarity float64, location string, threshold float64) Verdict {
+	loc := strings.ToLower(location)
+	switch {
+	case status == http.StatusUnauthorized || status == http.StatusForbidden:
+		return Enforced
+	case status >= 300 && status < 400 && (strings.Contains(loc, "login") || strings.Contains(loc, "signin") || strings.Contains(loc, "auth")):
+		return Enforced
+	case orig < 200 || orig >= 300:
+		if status == orig && similarity >= threshold {
+			return Unclear
+		}
+		return Enforced
+	case status >= 200 && status < 300 && similarity >= threshold:
+		return Bypass
+	case status >= 200 && status < 300:
+		return Unclear
+	}
+	return Enforced
+}
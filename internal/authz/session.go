@@ -0,0 +1,93 @@
+package authz
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+const UnauthName = "anônimo"
+
+type Session struct {
+	Name    string
+	Header  http.Header
+	Unauth  bool
+	Enabled bool
+}
+
+func ParseSession(spec string) (Session, error) {
+	name, rest, ok := strings.Cut(spec, "=")
+	name = strings.TrimSpace(name)
+	if !ok || name == "" {
+		return Session{}, fmt.Errorf("authz: use nome=Header: valor|Header: valor")
+	}
+	s := Session{Name: name, Header: http.Header{}, Enabled: true}
+	for _, part := range strings.Split(rest, "|") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		k, v, ok := strings.Cut(part, ":")
+		k = strings.TrimSpace(k)
+		if !ok || k == "" || strings.ContainsAny(k, " \t") {
+			return Session{}, fmt.Errorf("authz: header inválido em %s: %q", name, part)
+		}
+		s.Header.Add(k, strings.TrimSpace(v))
+	}
+	if len(s.Header) == 0 {
+		return Session{}, fmt.Errorf("authz: sessão %s sem headers", name)
+	}
+	return s, nil
+}
+
+func (s Session) String() string {
+	if s.Unauth {
+		return s.Name + " (sem credenciais)"
+	}
+	var parts []string
+	for k, vv := range s.Header {
+		for _, v := range vv {
+			parts = append(parts, k+": "+v)
+		}
+	}
+	return s.Name + "=" + strings.Join(parts, "|")
+}
+
+func ApplyRaw(raw string, s Session, strip []string) string {
+	sep := "\r\n"
+	head, body, ok := strings.Cut(raw, "\r\n\r\n")
+	if !ok {
+		sep = "\n"
+		head, body, ok = strings.Cut(raw, "\n\n")
+	}
+	lines := strings.Split(head, sep)
+
+	drop := map[string]bool{}
+	for _, k := range strip {
+		drop[http.CanonicalHeaderKey(k)] = true
+	}
+	for k := range s.Header {
+		drop[http.CanonicalHeaderKey(k)] = true
+	}
+
+	out := lines[:1]
+	for _, l := range lines[1:] {
+		k, _, _ := strings.Cut(l, ":")
+		if drop[http.CanonicalHeaderKey(strings.TrimSpace(k))] {
+			continue
+		}
+		out = append(out, l)
+	}
+	if !s.Unauth {
+		for k, vv := range s.Header {
+			for _, v := range vv {
+				out = append(out, k+": "+v)
+			}
+		}
+	}
+	res := strings.Join(out, sep) + sep + sep
+	if ok {
+		res += body
+	}
+	return res
+}
@@ -0,0 +1,374 @@
+package ca
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"software.sslmate.com/src/go-pkcs12"
+)
+
+type ImportOptions struct {
+	CertFile  string
+	KeyFile   string
+	ChainFile string
+	Password  string
+}
+
+var caFiles = []string{"ca.crt.pem", "ca.key.pem", "ca.chain.pem", "ca.root.pem"}
+
+func Import(dir string, opts ImportOptions) (*Store, string, error) {
+	if strings.TrimSpace(dir) == "" {
+		return nil, "", fmt.Errorf("ca dir vazio")
+	}
+	data, err := os.ReadFile(opts.CertFile)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var key crypto.Signer
+	certs, keys, err := decodeBundle(data, opts.Password)
+	if err != nil {
+		return nil, "", fmt.Errorf("%s: %w", opts.CertFile, err)
+	}
+	if len(certs) == 0 {
+		return nil, "", fmt.Errorf("%s: nenhum certificado", opts.CertFile)
+	}
+	if len(keys) > 0 {
+		key = keys[0]
+	}
+	if opts.KeyFile != "" {
+		keyData, err := os.ReadFile(opts.KeyFile)
+		if err != nil {
+			return nil, "", err
+		}
+		key, err = parsePrivateKey(keyData)
+		if err != nil {
+			return nil, "", fmt.Errorf("%s: %w", opts.KeyFile, err)
+		}
+	}
+	if key == nil {
+		return nil, "", fmt.Errorf("chave privada do CA não encontrada (use --ca-import-key)")
+	}
+
+	caCert, chain := certs[0], certs[1:]
+	if opts.ChainFile != "" {
+		chainData, err := os.ReadFile(opts.ChainFile)
+		if err != nil {
+			return nil, "", err
+		}
+		extra, _, err := decodeBundle(chainData, opts.Password)
+		if err != nil {
+			return nil, "", fmt.Errorf("%s: %w", opts.ChainFile, err)
+		}
+		chain = append(chain, extra...)
+	}
+	chain = orderChain(caCert, chain)
+	if err := validateCA(caCert, key, chain); err != nil {
+		return nil, "", err
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, "", err
+	}
+	staging, err := os.MkdirTemp(dir, ".import-")
+	if err != nil {
+		return nil, "", err
+	}
+	defer os.RemoveAll(staging)
+	if err := writeCA(staging, caCert, key, chain); err != nil {
+		return nil, "", err
+	}
+	backup, err := replaceCA(dir, staging)
+	if err != nil {
+		return nil, backup, err
+	}
+	if err := os.RemoveAll(filepath.Join(dir, "leaves")); err != nil {
+		return nil, backup, err
+	}
+	st, err := LoadOrCreate(dir)
+	return st, backup, err
+}
+
+func Regenerate(dir string) (*Store, string, error) {
+	if strings.TrimSpace(dir) == "" {
+		return nil, "", fmt.Errorf("ca dir vazio")
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, "", err
+	}
+	backup, err := backupCA(dir)
+	if err != nil {
+		return nil, "", undoBackup(dir, backup, err)
+	}
+	st, err := LoadOrCreate(dir)
+	if err != nil {
+		return nil, "", undoBackup(dir, backup, err)
+	}
+	if err := os.RemoveAll(filepath.Join(dir, "leaves")); err != nil {
+		return st, backup, err
+	}
+	return st, backup, nil
+}
+
+func replaceCA(dir, staging string) (string, error) {
+	backup, err := backupCA(dir)
+	if err == nil {
+		err = moveCA(staging, dir)
+	}
+	if err != nil {
+		return "", undoBackup(dir, backup, err)
+	}
+	return backup, nil
+}
+
+func moveCA(from, to string) error {
+	for _, name := range caFiles {
+		err := os.Rename(filepath.Join(from, name), filepath.Join(to, name))
+		optional := name == "ca.chain.pem" || name == "ca.root.pem"
+		if err != nil && !(optional && os.IsNotExist(err)) {
+			return err
+		}
+	}
+	return nil
+}
+
+func undoBackup(dir, backup string, cause error) error {
+	for _, name := range caFiles {
+		if err := os.Remove(filepath.Join(dir, name)); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("%w (CA antigo continua em %s: %v)", cause, backup, err)
+		}
+	}
+	if backup == "" {
+		return cause
+	}
+	for _, name := range caFiles {
+		if err := os.Rename(filepath.Join(backup, name), filepath.Join(dir, name)); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("%w (CA antigo continua em %s: %v)", cause, backup, err)
+		}
+	}
+	_ = os.Remove(backup)
+	return cause
+}
+
+func backupCA(dir string) (string, error) {
+	var present []string
+	for _, name := range caFiles {
+		if _, err := os.Stat(filepath.Join(dir, name)); err == nil {
+			present = append(present, name)
+		}
+	}
+	if len(present) == 0 {
+		return "", nil
+	}
+
+	backup := filepath.Join(dir, "backup-"+time.Now().Format("20060102-150405"))
+	for i := 2; ; i++ {
+		if _, err := os.Stat(backup); os.IsNotExist(err) {
+			break
+		}
+		backup = filepath.Join(dir, fmt.Sprintf("backup-%s-%d", time.Now().Format("20060102-150405"), i))
+	}
+	if err := os.MkdirAll(backup, 0o700); err != nil {
+		return "", err
+	}
+	for _, name := range present {
+		if err := os.Rename(filepath.Join(dir, name), filepath.Join(backup, name)); err != nil {
+			return backup, err
+		}
+	}
+	return backup, nil
+}
+
+func writeCA(dir string, caCert *x509.Certificate, caKey crypto.Signer, chain []*x509.Certificate) error {
+	keyDER, err := x509.MarshalPKCS8PrivateKey(caKey)
+	if err != nil {
+		return err
+	}
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: caCert.Raw})
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER})
+	if err := os.WriteFile(filepath.Join(dir, "ca.crt.pem"), certPEM, 0o644); err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(dir, "ca.key.pem"), keyPEM, 0o600); err != nil {
+		return err
+	}
+
+	chainPath := filepath.Join(dir, "ca.chain.pem")
+	rootPath := filepath.Join(dir, "ca.root.pem")
+	if len(chain) == 0 {
+		for _, p := range []string{chainPath, rootPath} {
+			if err := os.Remove(p); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+		}
+		return nil
+	}
+	var b bytes.Buffer
+	for _, c := range chain {
+		_ = pem.Encode(&b, &pem.Block{Type: "CERTIFICATE", Bytes: c.Raw})
+	}
+	if err := os.WriteFile(chainPath, b.Bytes(), 0o644); err != nil {
+		return err
+	}
+	rootPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: chain[len(chain)-1].Raw})
+	return os.WriteFile(rootPath, rootPEM, 0o644)
+}
+
+func loadChain(path string) ([]*x509.Certificate, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	certs, _, err := decodeBundle(data, "")
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return certs, nil
+}
+
+func validateCA(caCert *x509.Certificate, key crypto.Signer, chain []*x509.Certificate) error {
+	if !caCert.BasicConstraintsValid || !caCert.IsCA {
+		return fmt.Errorf("certificado não é um CA (basicConstraints CA:FALSE)")
+	}
+	if caCert.KeyUsage != 0 && caCert.KeyUsage&x509.KeyUsageCertSign == 0 {
+		return fmt.Errorf("certificado do CA não permite keyCertSign")
+	}
+	if time.Now().After(caCert.NotAfter) {
+		return fmt.Errorf("certificado do CA expirou em %s", caCert.NotAfter.Format("2006-01-02"))
+	}
+	pub, ok := key.Public().(interface{ Equal(crypto.PublicKey) bool })
+	if !ok || !pub.Equal(caCert.PublicKey) {
+		return fmt.Errorf("chave privada não corresponde ao certificado do CA")
+	}
+	prev := caCert
+	for i, c := range chain {
+		if err := prev.CheckSignatureFrom(c); err != nil {
+			return fmt.Errorf("cadeia[%d] %s não emitiu %s: %w", i, c.Subject.CommonName, prev.Subject.CommonName, err)
+		}
+		prev = c
+	}
+	return nil
+}
+
+func orderChain(caCert *x509.Certificate, certs []*x509.Certificate) []*x509.Certificate {
+	rest := append([]*x509.Certificate(nil), certs...)
+	var out []*x509.Certificate
+	prev := caCert
+	for len(rest) > 0 {
+		next := -1
+		for i, c := range rest {
+			if c.Equal(prev) {
+				rest = append(rest[:i], rest[i+1:]...)
+				next = -2
+				break
+			}
+			if prev.CheckSignatureFrom(c) == nil {
+				next = i
+				break
+			}
+		}
+		if next == -2 {
+			continue
+		}
+		if next < 0 {
+			return append(out, rest...)
+		}
+		prev = rest[next]
+		out = append(out, prev)
+		rest = append(rest[:next], rest[next+1:]...)
+	}
+	return out
+}
+
+func decodeBundle(data []byte, password string) ([]*x509.Certificate, []crypto.Signer, error) {
+	var certs []*x509.Certificate
+	var keys []crypto.Signer
+
+	rest := data
+	sawPEM := false
+	for {
+		var b *pem.Block
+		b, rest = pem.Decode(rest)
+		if b == nil {
+			break
+		}
+		sawPEM = true
+		switch {
+		case b.Type == "CERTIFICATE":
+			c, err := x509.ParseCertificate(b.Bytes)
+			if err != nil {
+				return nil, nil, err
+			}
+			certs = append(certs, c)
+		case strings.HasSuffix(b.Type, "PRIVATE KEY"):
+			k, err := parseKeyDER(b.Bytes)
+			if err != nil {
+				return nil, nil, err
+			}
+			keys = append(keys, k)
+		}
+	}
+	if sawPEM {
+		return certs, keys, nil
+	}
+
+	if parsed, err := x509.ParseCertificates(data); err == nil && len(parsed) > 0 {
+		return parsed, nil, nil
+	}
+
+	keyAny, leaf, chain, err := pkcs12.DecodeChain(data, password)
+	if err != nil {
+		return nil, nil, fmt.Errorf("formato não reconhecido (PEM, DER ou PKCS#12): %w", err)
+	}
+	certs = append([]*x509.Certificate{leaf}, chain...)
+	if k, ok := keyAny.(crypto.Signer); ok {
+		keys = append(keys, k)
+	}
+	return certs, keys, nil
+}
+
+func parsePrivateKey(data []byte) (crypto.Signer, error) {
+	if b, _ := pem.Decode(data); b != nil {
+		for rest := data; ; {
+			b, rest = pem.Decode(rest)
+			if b == nil {
+				return nil, fmt.Errorf("key pem inválido")
+			}
+			if strings.HasSuffix(b.Type, "PRIVATE KEY") {
+				return parseKeyDER(b.Bytes)
+			}
+		}
+	}
+	return parseKeyDER(data)
+}
+
+func parseKeyDER(der []byte) (crypto.Signer, error) {
+	if k, err := x509.ParsePKCS8PrivateKey(der); err == nil {
+		switch k := k.(type) {
+		case *rsa.PrivateKey:
+			return k, nil
+		case *ecdsa.PrivateKey:
+			return k, nil
+		}
+		return nil, fmt.Errorf("tipo de chave não suportado (use RSA ou ECDSA)")
+	}
+	if k, err := x509.ParsePKCS1PrivateKey(der); err == nil {
+		return k, nil
+	}
+	if k, err := x509.ParseECPrivateKey(der); err == nil {
+		return k, nil
+	}
+	return nil, fmt.Errorf("chave privada inválida (esperado PKCS#8, PKCS#1 ou SEC1)")
+}
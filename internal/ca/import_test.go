@@ -0,0 +1,180 @@
+package ca
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/hex"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"software.sslmate.com/src/go-pkcs12"
+)
+
+func testRSACA(t *testing.T, cn string, parent *x509.Certificate, parentKey *rsa.PrivateKey) (*x509.Certificate, *rsa.PrivateKey) {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("rsa: %v", err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: cn},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().AddDate(1, 0, 0),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	if parent == nil {
+		parent, parentKey = tmpl, key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, parent, &key.PublicKey, parentKey)
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	return cert, key
+}
+
+func TestImport_PKCS12WithIntermediate(t *testing.T) {
+	dir := t.TempDir()
+	old, err := LoadOrCreate(dir)
+	if err != nil {
+		t.Fatalf("LoadOrCreate: %v", err)
+	}
+	if _, _, err := old.LeafCert("old.example.com"); err != nil {
+		t.Fatalf("LeafCert: %v", err)
+	}
+
+	root, rootKey := testRSACA(t, "Team Root", nil, nil)
+	inter, interKey := testRSACA(t, "Team Interception", root, rootKey)
+	p12, err := pkcs12.Modern2023.Encode(interKey, inter, []*x509.Certificate{root}, "segredo")
+	if err != nil {
+		t.Fatalf("pkcs12: %v", err)
+	}
+	p12Path := filepath.Join(t.TempDir(), "team.p12")
+	if err := os.WriteFile(p12Path, p12, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	st, backup, err := Import(dir, ImportOptions{CertFile: p12Path, Password: "segredo"})
+	if err != nil {
+		t.Fatalf("Import: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(backup, "ca.crt.pem")); err != nil {
+		t.Fatalf("expected old CA in backup: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "leaves")); !os.IsNotExist(err) {
+		t.Fatalf("expected leaf cache purged, got %v", err)
+	}
+	if staged, _ := filepath.Glob(filepath.Join(dir, ".import-*")); len(staged) != 0 {
+		t.Fatalf("staging dir left behind: %v", staged)
+	}
+
+	sum := sha1.Sum(root.Raw)
+	if got, want := st.RootThumbprintSHA1(), strings.ToUpper(hex.EncodeToString(sum[:])); got != want {
+		t.Fatalf("thumbprint = %s, want root %s", got, want)
+	}
+
+	certPEM, keyPEM, err := st.LeafCert("api.example.com")
+	if err != nil {
+		t.Fatalf("LeafCert: %v", err)
+	}
+	pair, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		t.Fatalf("X509KeyPair: %v", err)
+	}
+	if len(pair.Certificate) != 2 {
+		t.Fatalf("expected leaf + intermediate, got %d certs", len(pair.Certificate))
+	}
+	leaf, _ := x509.ParseCertificate(pair.Certificate[0])
+	roots := x509.NewCertPool()
+	roots.AddCert(root)
+	inters := x509.NewCertPool()
+	inters.AddCert(inter)
+	if _, err := leaf.Verify(x509.VerifyOptions{DNSName: "api.example.com", Roots: roots, Intermediates: inters}); err != nil {
+		t.Fatalf("verify: %v", err)
+	}
+
+	reloaded, err := LoadOrCreate(dir)
+	if err != nil {
+		t.Fatalf("reload: %v", err)
+	}
+	if reloaded.RootThumbprintSHA1() != st.RootThumbprintSHA1() {
+		t.Fatalf("reloaded thumbprint differs")
+	}
+}
+
+func TestImport_PEMKeyMismatch(t *testing.T) {
+	ca1, _ := testRSACA(t, "A", nil, nil)
+	_, key2 := testRSACA(t, "B", nil, nil)
+
+	src := t.TempDir()
+	certPath := filepath.Join(src, "ca.der")
+	keyPath := filepath.Join(src, "ca.key")
+	_ = os.WriteFile(certPath, ca1.Raw, 0o644)
+	_ = os.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key2)}), 0o600)
+
+	dir := t.TempDir()
+	if _, _, err := Import(dir, ImportOptions{CertFile: certPath, KeyFile: keyPath}); err == nil || !strings.Contains(err.Error(), "não corresponde") {
+		t.Fatalf("expected key mismatch error, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "ca.crt.pem")); !os.IsNotExist(err) {
+		t.Fatalf("CA dir should be untouched, got %v", err)
+	}
+}
+
+func TestRegenerate_BacksUpOldRoot(t *testing.T) {
+	dir := t.TempDir()
+	old, err := LoadOrCreate(dir)
+	if err != nil {
+		t.Fatalf("LoadOrCreate: %v", err)
+	}
+	st, backup, err := Regenerate(dir)
+	if err != nil {
+		t.Fatalf("Regenerate: %v", err)
+	}
+	if st.RootThumbprintSHA1() == old.RootThumbprintSHA1() {
+		t.Fatalf("expected new root")
+	}
+	b, err := os.ReadFile(filepath.Join(backup, "ca.crt.pem"))
+	if err != nil {
+		t.Fatalf("backup: %v", err)
+	}
+	if string(b) != string(old.RootCertPEM()) {
+		t.Fatalf("backup does not hold the old root")
+	}
+}
+
+func TestImport_RestoresOnFailure(t *testing.T) {
+	dir := t.TempDir()
+	old, err := LoadOrCreate(dir)
+	if err != nil {
+		t.Fatalf("LoadOrCreate: %v", err)
+	}
+	if _, err := replaceCA(dir, t.TempDir()); err == nil {
+		t.Fatalf("expected error for empty staging dir")
+	}
+	st, err := LoadOrCreate(dir)
+	if err != nil {
+		t.Fatalf("reload: %v", err)
+	}
+	if st.RootThumbprintSHA1() != old.RootThumbprintSHA1() {
+		t.Fatalf("old CA not restored")
+	}
+	if backups, _ := filepath.Glob(filepath.Join(dir, "backup-*")); len(backups) != 0 {
+		t.Fatalf("leftover backups: %v", backups)
+	}
+}
@@ -1,36 +1,190 @@
 package ca
 
 import (
+	"crypto/x509"
+	"encoding/pem"
 	"fmt"
-	"runtime"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
 )
 
 type InstallScope int
 
 const (
 	ScopeCurrentUser InstallScope = iota
+	ScopeSystem
 )
 
-func InstallRootCA(dir string, scope InstallScope) (thumbprint string, err error) {
+const (
+	trustName     = "burpui Local CA"
+	installedFile = "ca.installed.pem"
+)
+
+type InstallOptions struct {
+	Scope  InstallScope
+	DryRun bool
+	Log    io.Writer
+
+	AnchorDir     string
+	UpdateCommand []string
+	NSSDBs        []string
+	SkipNSS       bool
+}
+
+type installStep struct {
+	desc     string
+	cmd      []string
+	fn       func() error
+	optional bool
+}
+
+type trustedCert struct {
+	path string
+	cert *x509.Certificate
+}
+
+func (t trustedCert) pem() []byte {
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: t.cert.Raw})
+}
+
+func (t trustedCert) thumbprint() string {
+	return thumbprintSHA1(t.cert.Raw)
+}
+
+func loadInstalled(dir string) (trustedCert, error) {
+	path := filepath.Join(dir, installedFile)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return trustedCert{}, err
+	}
+	cert, err := parseCertPEM(data)
+	if err != nil {
+		return trustedCert{}, fmt.Errorf("%s: %w", path, err)
+	}
+	return trustedCert{path: path, cert: cert}, nil
+}
+
+func InstallRootCA(dir string, opts InstallOptions) (thumbprint string, err error) {
 	st, err := LoadOrCreate(dir)
 	if err != nil {
 		return "", err
 	}
+	if !st.Generated() {
+		return "", fmt.Errorf("CA importado: o root da empresa não é instalado pelo burpui, distribua-o pelo processo da empresa")
+	}
+	target := trustedCert{path: filepath.Join(dir, installedFile), cert: st.CACert()}
+	thumb := target.thumbprint()
+
+	prev, err := loadInstalled(dir)
+	switch {
+	case err == nil && !prev.cert.Equal(target.cert):
+		return thumb, fmt.Errorf("outro CA do burpui (%s) continua instalado: rode --uninstall-ca antes", prev.thumbprint())
+	case err != nil && !os.IsNotExist(err):
+		return thumb, err
+	}
 
-	if runtime.GOOS != "windows" {
-		return "", fmt.Errorf("auto-instalação suportada só no Windows por enquanto")
+	steps, err := installSteps(target, opts)
+	if err != nil {
+		return thumb, err
+	}
+	if !opts.DryRun {
+		if err := os.WriteFile(target.path, target.pem(), 0o644); err != nil {
+			return thumb, err
+		}
 	}
-	return installRootCAWindows(st, scope)
+	return thumb, runSteps(steps, opts)
 }
 
-func UninstallRootCA(dir string, scope InstallScope) (thumbprint string, err error) {
-	st, err := LoadOrCreate(dir)
+func UninstallRootCA(dir string, opts InstallOptions) (thumbprint string, err error) {
+	target, err := loadInstalled(dir)
+	if os.IsNotExist(err) {
+		st, lerr := Load(dir)
+		if lerr != nil {
+			return "", lerr
+		}
+		if !st.Generated() {
+			return "", fmt.Errorf("CA importado e nenhum CA instalado pelo burpui registrado em %s: nada a remover", installedFile)
+		}
+		target, err = trustedCert{path: filepath.Join(dir, "ca.crt.pem"), cert: st.CACert()}, nil
+	}
 	if err != nil {
 		return "", err
 	}
+	thumb := target.thumbprint()
+
+	steps, err := uninstallSteps(target, opts)
+	if err != nil {
+		return thumb, err
+	}
+	if err := runSteps(steps, opts); err != nil {
+		return thumb, err
+	}
+	if !opts.DryRun {
+		if err := os.Remove(filepath.Join(dir, installedFile)); err != nil && !os.IsNotExist(err) {
+			return thumb, err
+		}
+	}
+	return thumb, nil
+}
+
+func runSteps(steps []installStep, opts InstallOptions) error {
+	log := opts.Log
+	if log == nil {
+		log = io.Discard
+	}
+	if opts.DryRun {
+		fmt.Fprintln(log, "dry-run: nada será alterado")
+	}
 
-	if runtime.GOOS != "windows" {
-		return "", fmt.Errorf("auto-desinstalação suportada só no Windows por enquanto")
+	for _, st := range steps {
+		fmt.Fprintf(log, "- %s\n", st.desc)
+		if len(st.cmd) > 0 {
+			fmt.Fprintf(log, "  $ %s\n", shellJoin(st.cmd))
+		}
+		if opts.DryRun {
+			continue
+		}
+
+		var err error
+		if st.fn != nil {
+			err = st.fn()
+		} else if len(st.cmd) > 0 {
+			out, cmdErr := exec.Command(st.cmd[0], st.cmd[1:]...).CombinedOutput()
+			if cmdErr != nil {
+				err = fmt.Errorf("%s falhou: %w\n%s", st.cmd[0], cmdErr, string(out))
+			}
+		}
+		if err != nil {
+			if st.optional {
+				fmt.Fprintf(log, "  aviso: %s\n", err.Error())
+				continue
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+func shellJoin(args []string) string {
+	out := make([]string, len(args))
+	for i, a := range args {
+		if a == "" || strings.ContainsAny(a, " \t'\"$\\*?,") {
+			a = "'" + strings.ReplaceAll(a, "'", `'\''`) + "'"
+		}
+		out[i] = a
+	}
+	return strings.Join(out, " ")
+}
+
+func privileged(cmd ...string) []string {
+	if os.Geteuid() == 0 {
+		return cmd
+	}
+	if _, err := exec.LookPath("sudo"); err != nil {
+		return cmd
 	}
-	return uninstallRootCAWindows(st, scope)
+	return append([]string{"sudo"}, cmd...)
 }
@@ -0,0 +1,46 @@
+package ca
+
+import (
+	"os"
+	"path/filepath"
+)
+
+func darwinKeychain(scope InstallScope) (string, error) {
+	if scope == ScopeSystem {
+		return "/Library/Keychains/System.keychain", nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, "Library", "Keychains", "login.keychain-db"), nil
+}
+
+func installSteps(root trustedCert, opts InstallOptions) ([]installStep, error) {
+	keychain, err := darwinKeychain(opts.Scope)
+	if err != nil {
+		return nil, err
+	}
+	args := []string{"security", "add-trusted-cert", "-r", "trustRoot", "-k", keychain, root.path}
+	if opts.Scope == ScopeSystem {
+		args = privileged("security", "add-trusted-cert", "-d", "-r", "trustRoot", "-k", keychain, root.path)
+	}
+	return []installStep{{desc: "confiar no CA no keychain " + keychain, cmd: args}}, nil
+}
+
+func uninstallSteps(root trustedCert, opts InstallOptions) ([]installStep, error) {
+	keychain, err := darwinKeychain(opts.Scope)
+	if err != nil {
+		return nil, err
+	}
+	trust := []string{"security", "remove-trusted-cert", root.path}
+	del := []string{"security", "delete-certificate", "-Z", root.thumbprint(), keychain}
+	if opts.Scope == ScopeSystem {
+		trust = privileged("security", "remove-trusted-cert", "-d", root.path)
+		del = privileged(del...)
+	}
+	return []installStep{
+		{desc: "remover configuração de confiança", cmd: trust, optional: true},
+		{desc: "remover CA do keychain " + keychain, cmd: del},
+	}, nil
+}
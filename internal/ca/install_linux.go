@@ -0,0 +1,144 @@
+package ca
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+)
+
+type linuxTrust struct {
+	name      string
+	anchorDir string
+	fileName  string
+	update    []string
+}
+
+var linuxTrusts = []linuxTrust{
+	{name: "debian", anchorDir: "/usr/local/share/ca-certificates", fileName: "burpui-ca.crt", update: []string{"update-ca-certificates"}},
+	{name: "redhat", anchorDir: "/etc/pki/ca-trust/source/anchors", fileName: "burpui-ca.pem", update: []string{"update-ca-trust", "extract"}},
+	{name: "arch", anchorDir: "/etc/ca-certificates/trust-source/anchors", fileName: "burpui-ca.crt", update: []string{"trust", "extract-compat"}},
+	{name: "suse", anchorDir: "/etc/pki/trust/anchors", fileName: "burpui-ca.pem", update: []string{"update-ca-certificates"}},
+}
+
+func detectLinuxTrust(opts InstallOptions) (linuxTrust, bool, error) {
+	if opts.AnchorDir != "" {
+		return linuxTrust{name: "custom", anchorDir: opts.AnchorDir, fileName: "burpui-ca.crt", update: opts.UpdateCommand}, true, nil
+	}
+	for _, t := range linuxTrusts {
+		if fi, err := os.Stat(t.anchorDir); err == nil && fi.IsDir() {
+			if _, err := exec.LookPath(t.update[0]); err == nil {
+				return t, false, nil
+			}
+		}
+	}
+	return linuxTrust{}, false, fmt.Errorf("nenhum trust store conhecido encontrado (debian/redhat/arch/suse)")
+}
+
+func linuxNSSDBs(opts InstallOptions) []string {
+	if opts.SkipNSS {
+		return nil
+	}
+	if opts.NSSDBs != nil {
+		return opts.NSSDBs
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil
+	}
+
+	var dbs []string
+	if fi, err := os.Stat(filepath.Join(home, ".pki", "nssdb")); err == nil && fi.IsDir() {
+		dbs = append(dbs, filepath.Join(home, ".pki", "nssdb"))
+	}
+	for _, base := range []string{
+		filepath.Join(home, ".mozilla", "firefox"),
+		filepath.Join(home, "snap", "firefox", "common", ".mozilla", "firefox"),
+		filepath.Join(home, ".var", "app", "org.mozilla.firefox", ".mozilla", "firefox"),
+	} {
+		matches, _ := filepath.Glob(filepath.Join(base, "*", "cert9.db"))
+		for _, m := range matches {
+			dbs = append(dbs, filepath.Dir(m))
+		}
+	}
+	return dbs
+}
+
+func nssSteps(dbs []string, args func(db string) []string, desc string) []installStep {
+	if len(dbs) == 0 {
+		return nil
+	}
+	if _, err := exec.LookPath("certutil"); err != nil {
+		return []installStep{{
+			desc:     desc + " (NSS/Firefox/Chromium)",
+			fn:       func() error { return fmt.Errorf("certutil não encontrado (instale libnss3-tools/nss-tools)") },
+			optional: true,
+		}}
+	}
+	steps := make([]installStep, 0, len(dbs))
+	for _, db := range dbs {
+		steps = append(steps, installStep{desc: desc + " " + db, cmd: args(db), optional: true})
+	}
+	return steps
+}
+
+func installSteps(root trustedCert, opts InstallOptions) ([]installStep, error) {
+	t, custom, err := detectLinuxTrust(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	src := root.path
+	dst := filepath.Join(t.anchorDir, t.fileName)
+	var steps []installStep
+	if custom || os.Geteuid() == 0 {
+		pemBytes := root.pem()
+		steps = append(steps, installStep{desc: "copiar CA para " + dst, fn: func() error { return os.WriteFile(dst, pemBytes, 0o644) }})
+	} else {
+		steps = append(steps, installStep{desc: "copiar CA para " + dst, cmd: privileged("install", "-m", "0644", src, dst)})
+	}
+	if len(t.update) > 0 {
+		update := t.update
+		if !custom {
+			update = privileged(update...)
+		}
+		steps = append(steps, installStep{desc: "atualizar trust store (" + t.name + ")", cmd: update})
+	}
+
+	steps = append(steps, nssSteps(linuxNSSDBs(opts), func(db string) []string {
+		return []string{"certutil", "-d", "sql:" + db, "-A", "-t", "C,,", "-n", trustName, "-i", src}
+	}, "importar no NSS")...)
+	return steps, nil
+}
+
+func uninstallSteps(root trustedCert, opts InstallOptions) ([]installStep, error) {
+	t, custom, err := detectLinuxTrust(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	dst := filepath.Join(t.anchorDir, t.fileName)
+	var steps []installStep
+	if custom || os.Geteuid() == 0 {
+		steps = append(steps, installStep{desc: "remover " + dst, fn: func() error {
+			if err := os.Remove(dst); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+			return nil
+		}})
+	} else {
+		steps = append(steps, installStep{desc: "remover " + dst, cmd: privileged("rm", "-f", dst)})
+	}
+	if len(t.update) > 0 {
+		update := t.update
+		if !custom {
+			update = privileged(update...)
+		}
+		steps = append(steps, installStep{desc: "atualizar trust store (" + t.name + ")", cmd: update})
+	}
+
+	steps = append(steps, nssSteps(linuxNSSDBs(opts), func(db string) []string {
+		return []string{"certutil", "-d", "sql:" + db, "-D", "-n", trustName}
+	}, "remover do NSS")...)
+	return steps, nil
+}
@@ -0,0 +1,111 @@
+package ca
+
+import (
+	"bytes"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestInstallRootCA_FakeAnchorDir(t *testing.T) {
+	caDir := t.TempDir()
+	anchors := t.TempDir()
+	opts := InstallOptions{AnchorDir: anchors, SkipNSS: true}
+	dst := filepath.Join(anchors, "burpui-ca.crt")
+
+	var log bytes.Buffer
+	dry := opts
+	dry.DryRun = true
+	dry.Log = &log
+	if _, err := InstallRootCA(caDir, dry); err != nil {
+		t.Fatalf("dry-run: %v", err)
+	}
+	if _, err := os.Stat(dst); !os.IsNotExist(err) {
+		t.Fatalf("dry-run should not write %s", dst)
+	}
+	if !strings.Contains(log.String(), dst) {
+		t.Fatalf("dry-run output should mention %s, got:\n%s", dst, log.String())
+	}
+
+	thumb, err := InstallRootCA(caDir, opts)
+	if err != nil {
+		t.Fatalf("InstallRootCA: %v", err)
+	}
+	if len(thumb) != 40 {
+		t.Fatalf("unexpected thumbprint %q", thumb)
+	}
+	b, err := os.ReadFile(dst)
+	if err != nil {
+		t.Fatalf("anchor not written: %v", err)
+	}
+	st, _ := LoadOrCreate(caDir)
+	if !bytes.Equal(b, st.RootCertPEM()) {
+		t.Fatalf("anchor content differs from root PEM")
+	}
+
+	if _, err := os.Stat(filepath.Join(caDir, installedFile)); err != nil {
+		t.Fatalf("installed root not recorded: %v", err)
+	}
+
+	if _, err := UninstallRootCA(caDir, opts); err != nil {
+		t.Fatalf("UninstallRootCA: %v", err)
+	}
+	if _, err := os.Stat(dst); !os.IsNotExist(err) {
+		t.Fatalf("expected %s removed", dst)
+	}
+	if _, err := os.Stat(filepath.Join(caDir, installedFile)); !os.IsNotExist(err) {
+		t.Fatalf("record should be removed after uninstall, got %v", err)
+	}
+}
+
+func TestInstallRootCA_ImportedCA(t *testing.T) {
+	caDir := t.TempDir()
+	anchors := t.TempDir()
+	opts := InstallOptions{AnchorDir: anchors, SkipNSS: true}
+	dst := filepath.Join(anchors, "burpui-ca.crt")
+
+	ours, err := InstallRootCA(caDir, opts)
+	if err != nil {
+		t.Fatalf("InstallRootCA: %v", err)
+	}
+
+	root, rootKey := testRSACA(t, "Team Root", nil, nil)
+	inter, interKey := testRSACA(t, "Team Interception", root, rootKey)
+	src := t.TempDir()
+	certPath := filepath.Join(src, "team.pem")
+	keyPath := filepath.Join(src, "team.key")
+	bundle := append(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: inter.Raw}), pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: root.Raw})...)
+	_ = os.WriteFile(certPath, bundle, 0o644)
+	_ = os.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(interKey)}), 0o600)
+	if _, _, err := Import(caDir, ImportOptions{CertFile: certPath, KeyFile: keyPath}); err != nil {
+		t.Fatalf("Import: %v", err)
+	}
+
+	if _, err := InstallRootCA(caDir, opts); err == nil || !strings.Contains(err.Error(), "importado") {
+		t.Fatalf("expected imported CA install to be refused, got %v", err)
+	}
+	thumb, err := UninstallRootCA(caDir, opts)
+	if err != nil || thumb != ours {
+		t.Fatalf("uninstall removed %s (%v), want burpui root %s", thumb, err, ours)
+	}
+	if _, err := os.Stat(dst); !os.IsNotExist(err) {
+		t.Fatalf("expected %s removed", dst)
+	}
+	if _, err := UninstallRootCA(caDir, opts); err == nil || !strings.Contains(err.Error(), "nada a remover") {
+		t.Fatalf("expected refusal for imported CA without record, got %v", err)
+	}
+}
+
+func TestUninstallRootCA_NoCA(t *testing.T) {
+	caDir := t.TempDir()
+	if _, err := UninstallRootCA(caDir, InstallOptions{AnchorDir: t.TempDir(), SkipNSS: true}); !errors.Is(err, ErrNoCA) {
+		t.Fatalf("expected ErrNoCA, got %v", err)
+	}
+	if entries, _ := os.ReadDir(caDir); len(entries) != 0 {
+		t.Fatalf("uninstall created files: %v", entries)
+	}
+}
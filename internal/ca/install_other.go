@@ -0,0 +1,16 @@
+//go:build !windows && !linux && !darwin
+
+package ca
+
+import (
+	"fmt"
+	"runtime"
+)
+
+func installSteps(root trustedCert, opts InstallOptions) ([]installStep, error) {
+	return nil, fmt.Errorf("auto-instalação não suportada em %s", runtime.GOOS)
+}
+
+func uninstallSteps(root trustedCert, opts InstallOptions) ([]installStep, error) {
+	return nil, fmt.Errorf("auto-desinstalação não suportada em %s", runtime.GOOS)
+}
@@ -3,54 +3,32 @@ package ca
 import (
 	"fmt"
 	"os"
-	"os/exec"
 	"path/filepath"
 )
 
-func installRootCAWindows(st *Store, scope InstallScope) (string, error) {
-	thumb := st.RootThumbprintSHA1()
-	if thumb == "" {
-		return "", fmt.Errorf("thumbprint vazio")
-	}
-
-	der := st.RootCertDER()
+func installSteps(root trustedCert, opts InstallOptions) ([]installStep, error) {
+	der := root.cert.Raw
 	if len(der) == 0 {
-		return "", fmt.Errorf("cert der vazio")
+		return nil, fmt.Errorf("cert der vazio")
 	}
 
-	path := filepath.Join(st.Dir, "ca.cer")
-	if err := os.WriteFile(path, der, 0o644); err != nil {
-		return "", err
+	path := filepath.Join(filepath.Dir(root.path), "ca.cer")
+	args := []string{"certutil", "-user", "-addstore", "Root", path}
+	if opts.Scope != ScopeCurrentUser {
+		args = []string{"certutil", "-addstore", "Root", path}
 	}
 
-	args := []string{"-user", "-addstore", "Root", path}
-	if scope != ScopeCurrentUser {
-		args = []string{"-addstore", "Root", path}
-	}
-
-	cmd := exec.Command("certutil", args...)
-	out, err := cmd.CombinedOutput()
-	if err != nil {
-		return thumb, fmt.Errorf("certutil falhou: %w\n%s", err, string(out))
-	}
-	return thumb, nil
+	return []installStep{
+		{desc: "gravar " + path, fn: func() error { return os.WriteFile(path, der, 0o644) }},
+		{desc: "importar no Trusted Root", cmd: args},
+	}, nil
 }
 
-func uninstallRootCAWindows(st *Store, scope InstallScope) (string, error) {
-	thumb := st.RootThumbprintSHA1()
-	if thumb == "" {
-		return "", fmt.Errorf("thumbprint vazio")
-	}
-
-	args := []string{"-user", "-delstore", "Root", thumb}
-	if scope != ScopeCurrentUser {
-		args = []string{"-delstore", "Root", thumb}
-	}
-
-	cmd := exec.Command("certutil", args...)
-	out, err := cmd.CombinedOutput()
-	if err != nil {
-		return thumb, fmt.Errorf("certutil falhou: %w\n%s", err, string(out))
+func uninstallSteps(root trustedCert, opts InstallOptions) ([]installStep, error) {
+	thumb := root.thumbprint()
+	args := []string{"certutil", "-user", "-delstore", "Root", thumb}
+	if opts.Scope != ScopeCurrentUser {
+		args = []string{"certutil", "-delstore", "Root", thumb}
 	}
-	return thumb, nil
+	return []installStep{{desc: "remover do Trusted Root", cmd: args}}, nil
 }
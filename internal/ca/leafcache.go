@@ -0,0 +1,272 @@
+package ca
+
+import (
+	"container/list"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultMaxLeaves = 500
+	leafRenewBefore  = 24 * time.Hour
+	mirrorMaxAge     = 7 * 24 * time.Hour
+	mirrorPrefix     = "mirror:"
+	issuedHeader     = "Issued"
+)
+
+type LeafInfo struct {
+	Key       string
+	DNSNames  []string
+	NotBefore time.Time
+	NotAfter  time.Time
+	Path      string
+	LastUsed  time.Time
+}
+
+func (s *Store) leavesDir() string {
+	return filepath.Join(s.Dir, "leaves")
+}
+
+func leafFileName(key string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(key) {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '.', r == '-':
+			b.WriteRune(r)
+		default:
+			b.WriteRune('_')
+		}
+	}
+	return b.String() + ".pem"
+}
+
+func leafUsable(c *tlsCert, caCert *x509.Certificate, now time.Time) bool {
+	if c == nil || c.cert == nil || caCert == nil {
+		return false
+	}
+	if strings.HasPrefix(c.key, mirrorPrefix) {
+		if c.issued.IsZero() || now.Before(c.issued) || now.Sub(c.issued) >= mirrorMaxAge {
+			return false
+		}
+	} else if now.Before(c.cert.NotBefore) || now.Add(leafRenewBefore).After(c.cert.NotAfter) {
+		return false
+	}
+	return c.cert.CheckSignatureFrom(caCert) == nil
+}
+
+func (s *Store) cachedLeaf(key string) ([]byte, []byte, bool) {
+	s.mu.Lock()
+	caCert := s.caCert
+	s.mu.Unlock()
+
+	s.leafMu.Lock()
+	defer s.leafMu.Unlock()
+	now := time.Now()
+
+	if cached, ok := s.leafCert[key]; ok {
+		if leafUsable(cached, caCert, now) {
+			s.leafLRU.MoveToFront(cached.elem)
+			return append([]byte(nil), cached.certPEM...), append([]byte(nil), cached.keyPEM...), true
+		}
+		s.evictLocked(cached)
+		return nil, nil, false
+	}
+
+	path := filepath.Join(s.leavesDir(), leafFileName(key))
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, false
+	}
+	c, err := splitLeafPEM(data)
+	if err == nil {
+		c.key = key
+	}
+	if err != nil || !leafUsable(c, caCert, now) {
+		_ = os.Remove(path)
+		return nil, nil, false
+	}
+	_ = os.Chtimes(path, now, now)
+	s.addLocked(c)
+	return append([]byte(nil), c.certPEM...), append([]byte(nil), c.keyPEM...), true
+}
+
+func (s *Store) putLeaf(key string, certPEM, keyPEM []byte) {
+	cert, err := parseCertPEM(certPEM)
+	if err != nil {
+		return
+	}
+
+	s.leafMu.Lock()
+	defer s.leafMu.Unlock()
+	if old, ok := s.leafCert[key]; ok {
+		s.leafLRU.Remove(old.elem)
+		delete(s.leafCert, key)
+	}
+	issued := time.Now()
+	s.addLocked(&tlsCert{key: key, certPEM: certPEM, keyPEM: keyPEM, cert: cert, issued: issued})
+
+	if err := os.MkdirAll(s.leavesDir(), 0o700); err == nil {
+		block := &pem.Block{Type: "CERTIFICATE", Headers: map[string]string{issuedHeader: issued.UTC().Format(time.RFC3339)}, Bytes: cert.Raw}
+		data := append(pem.EncodeToMemory(block), keyPEM...)
+		_ = os.WriteFile(filepath.Join(s.leavesDir(), leafFileName(key)), data, 0o600)
+	}
+	s.trimDiskLocked()
+}
+
+func (s *Store) addLocked(c *tlsCert) {
+	if s.leafCert == nil {
+		s.leafCert = map[string]*tlsCert{}
+	}
+	if s.leafLRU == nil {
+		s.leafLRU = list.New()
+	}
+	c.elem = s.leafLRU.PushFront(c)
+	s.leafCert[c.key] = c
+
+	for s.MaxLeaves > 0 && s.leafLRU.Len() > s.MaxLeaves {
+		oldest := s.leafLRU.Back().Value.(*tlsCert)
+		s.leafLRU.Remove(oldest.elem)
+		delete(s.leafCert, oldest.key)
+	}
+}
+
+func (s *Store) evictLocked(c *tlsCert) {
+	s.leafLRU.Remove(c.elem)
+	delete(s.leafCert, c.key)
+	_ = os.Remove(filepath.Join(s.leavesDir(), leafFileName(c.key)))
+}
+
+func (s *Store) trimDiskLocked() {
+	if s.MaxLeaves <= 0 {
+		return
+	}
+	entries, err := os.ReadDir(s.leavesDir())
+	if err != nil || len(entries) <= s.MaxLeaves {
+		return
+	}
+
+	type fileAge struct {
+		name string
+		mod  time.Time
+	}
+	files := make([]fileAge, 0, len(entries))
+	for _, e := range entries {
+		info, err := e.Info()
+		if err != nil || e.IsDir() {
+			continue
+		}
+		files = append(files, fileAge{name: e.Name(), mod: info.ModTime()})
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].mod.Before(files[j].mod) })
+	for i := 0; i < len(files)-s.MaxLeaves; i++ {
+		_ = os.Remove(filepath.Join(s.leavesDir(), files[i].name))
+	}
+}
+
+func splitLeafPEM(data []byte) (*tlsCert, error) {
+	c := &tlsCert{}
+	for {
+		var b *pem.Block
+		b, data = pem.Decode(data)
+		if b == nil {
+			break
+		}
+		switch b.Type {
+		case "CERTIFICATE":
+			if c.certPEM == nil {
+				if t, err := time.Parse(time.RFC3339, b.Headers[issuedHeader]); err == nil {
+					c.issued = t
+				}
+				c.certPEM = pem.EncodeToMemory(&pem.Block{Type: b.Type, Bytes: b.Bytes})
+			}
+		case "PRIVATE KEY":
+			c.keyPEM = pem.EncodeToMemory(b)
+		}
+	}
+	if c.certPEM == nil || c.keyPEM == nil {
+		return nil, fmt.Errorf("leaf pem incompleto")
+	}
+	cert, err := parseCertPEM(c.certPEM)
+	if err != nil {
+		return nil, err
+	}
+	c.cert = cert
+	return c, nil
+}
+
+func (s *Store) ListLeaves() ([]LeafInfo, error) {
+	entries, err := os.ReadDir(s.leavesDir())
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var out []LeafInfo
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".pem") {
+			continue
+		}
+		path := filepath.Join(s.leavesDir(), e.Name())
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		c, err := splitLeafPEM(data)
+		if err != nil {
+			continue
+		}
+		cert := c.cert
+		info := LeafInfo{
+			Key:       strings.TrimSuffix(e.Name(), ".pem"),
+			DNSNames:  cert.DNSNames,
+			NotBefore: cert.NotBefore,
+			NotAfter:  cert.NotAfter,
+			Path:      path,
+		}
+		for _, ip := range cert.IPAddresses {
+			info.DNSNames = append(info.DNSNames, ip.String())
+		}
+		if fi, err := e.Info(); err == nil {
+			info.LastUsed = fi.ModTime()
+		}
+		out = append(out, info)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].LastUsed.After(out[j].LastUsed) })
+	return out, nil
+}
+
+func (s *Store) PurgeLeaves(match string) (int, error) {
+	match = strings.ToLower(strings.TrimSpace(match))
+	leaves, err := s.ListLeaves()
+	if err != nil {
+		return 0, err
+	}
+
+	s.leafMu.Lock()
+	defer s.leafMu.Unlock()
+	n := 0
+	for _, l := range leaves {
+		if match != "" && !strings.Contains(l.Key, match) {
+			continue
+		}
+		if err := os.Remove(l.Path); err != nil {
+			return n, err
+		}
+		n++
+	}
+	for key, c := range s.leafCert {
+		if match == "" || strings.Contains(strings.TrimSuffix(leafFileName(key), ".pem"), match) {
+			s.leafLRU.Remove(c.elem)
+			delete(s.leafCert, key)
+		}
+	}
+	return n, nil
+}
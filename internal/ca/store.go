@@ -1,14 +1,19 @@
 package ca
 
 import (
+	"bytes"
+	"container/list"
+	"crypto"
 	"crypto/ecdsa"
 	"crypto/elliptic"
 	"crypto/rand"
 	"crypto/sha1"
+	"crypto/sha256"
 	"crypto/x509"
 	"crypto/x509/pkix"
 	"encoding/hex"
 	"encoding/pem"
+	"errors"
 	"fmt"
 	"math/big"
 	"net"
@@ -24,59 +29,93 @@ type Store struct {
 
 	mu        sync.Mutex
 	caCert    *x509.Certificate
-	caKey     *ecdsa.PrivateKey
+	caKey     crypto.Signer
 	caCertPEM []byte
+	chain     []*x509.Certificate
+
+	MaxLeaves int
 
 	leafMu   sync.Mutex
 	leafCert map[string]*tlsCert
+	leafLRU  *list.List
 }
 
 type tlsCert struct {
+	key     string
 	certPEM []byte
 	keyPEM  []byte
 	cert    *x509.Certificate
+	issued  time.Time
+	elem    *list.Element
 }
 
-func LoadOrCreate(dir string) (*Store, error) {
+var ErrNoCA = errors.New("nenhum CA encontrado")
+
+func Load(dir string) (*Store, error) {
 	if strings.TrimSpace(dir) == "" {
 		return nil, fmt.Errorf("ca dir vazio")
 	}
-	if err := os.MkdirAll(dir, 0o755); err != nil {
+	certPEM, err := os.ReadFile(filepath.Join(dir, "ca.crt.pem"))
+	if err == nil {
+		var keyPEM []byte
+		if keyPEM, err = os.ReadFile(filepath.Join(dir, "ca.key.pem")); err == nil {
+			return loadStore(dir, certPEM, keyPEM)
+		}
+	}
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("%w em %s", ErrNoCA, dir)
+	}
+	return nil, err
+}
+
+func loadStore(dir string, certPEM, keyPEM []byte) (*Store, error) {
+	caCert, err := parseCertPEM(certPEM)
+	if err != nil {
+		return nil, err
+	}
+	caKey, err := parsePrivateKey(keyPEM)
+	if err != nil {
+		return nil, err
+	}
+	chain, err := loadChain(filepath.Join(dir, "ca.chain.pem"))
+	if err != nil {
 		return nil, err
 	}
+	return &Store{Dir: dir, caCert: caCert, caKey: caKey, caCertPEM: certPEM, chain: chain, MaxLeaves: DefaultMaxLeaves}, nil
+}
 
-	certPath := filepath.Join(dir, "ca.crt.pem")
-	keyPath := filepath.Join(dir, "ca.key.pem")
+func LoadOrCreate(dir string) (*Store, error) {
+	st, err := Load(dir)
+	if !errors.Is(err, ErrNoCA) {
+		return st, err
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
 
-	certPEM, certErr := os.ReadFile(certPath)
-	keyPEM, keyErr := os.ReadFile(keyPath)
-	if certErr == nil && keyErr == nil {
-		caCert, err := parseCertPEM(certPEM)
-		if err != nil {
-			return nil, err
-		}
-		caKey, err := parseECDSAKeyPEM(keyPEM)
-		if err != nil {
-			return nil, err
-		}
-		return &Store{Dir: dir, caCert: caCert, caKey: caKey, caCertPEM: certPEM, leafCert: map[string]*tlsCert{}}, nil
+	caCert, caKey, certPEM, err := createRoot(dir)
+	if err != nil {
+		return nil, err
 	}
+	return &Store{Dir: dir, caCert: caCert, caKey: caKey, caCertPEM: certPEM, MaxLeaves: DefaultMaxLeaves}, nil
+}
 
+func createRoot(dir string) (*x509.Certificate, crypto.Signer, []byte, error) {
 	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
 	if err != nil {
-		return nil, err
+		return nil, nil, nil, err
 	}
 
 	serial, err := randSerial()
 	if err != nil {
-		return nil, err
+		return nil, nil, nil, err
 	}
 
 	now := time.Now()
 	tmpl := &x509.Certificate{
 		SerialNumber: serial,
 		Subject: pkix.Name{
-			CommonName:   "burpui Local CA",
+			CommonName:   trustName,
 			Organization: []string{"burpui"},
 		},
 		NotBefore:             now.Add(-1 * time.Hour),
@@ -89,44 +128,70 @@ func LoadOrCreate(dir string) (*Store, error) {
 
 	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &caKey.PublicKey, caKey)
 	if err != nil {
-		return nil, err
+		return nil, nil, nil, err
 	}
 
 	caCert, err := x509.ParseCertificate(der)
 	if err != nil {
-		return nil, err
+		return nil, nil, nil, err
 	}
 
-	certPEM = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
-	keyDER, err := x509.MarshalPKCS8PrivateKey(caKey)
-	if err != nil {
-		return nil, err
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	if err := writeCA(dir, caCert, caKey, nil); err != nil {
+		return nil, nil, nil, err
 	}
-	keyPEM = pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER})
+	return caCert, caKey, certPEM, nil
+}
 
-	if err := os.WriteFile(certPath, certPEM, 0o644); err != nil {
-		return nil, err
-	}
-	if err := os.WriteFile(keyPath, keyPEM, 0o600); err != nil {
-		return nil, err
+func (s *Store) rootCert() *x509.Certificate {
+	if len(s.chain) > 0 {
+		return s.chain[len(s.chain)-1]
 	}
+	return s.caCert
+}
 
-	return &Store{Dir: dir, caCert: caCert, caKey: caKey, caCertPEM: certPEM, leafCert: map[string]*tlsCert{}}, nil
+func (s *Store) CACert() *x509.Certificate {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.caCert
+}
+
+func (s *Store) Generated() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c := s.caCert
+	return len(s.chain) == 0 && c != nil && c.Subject.CommonName == trustName && bytes.Equal(c.RawSubject, c.RawIssuer)
 }
 
 func (s *Store) RootCertPEM() []byte {
 	s.mu.Lock()
 	defer s.mu.Unlock()
+	if len(s.chain) > 0 {
+		return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: s.rootCert().Raw})
+	}
 	return append([]byte(nil), s.caCertPEM...)
 }
 
 func (s *Store) RootCertDER() []byte {
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	if s.caCert == nil {
+	root := s.rootCert()
+	if root == nil {
 		return nil
 	}
-	return append([]byte(nil), s.caCert.Raw...)
+	return append([]byte(nil), root.Raw...)
+}
+
+func (s *Store) withChain(certPEM []byte) []byte {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, c := range append([]*x509.Certificate{s.caCert}, s.chain...) {
+		if c == nil || bytes.Equal(c.RawSubject, c.RawIssuer) && c.CheckSignatureFrom(c) == nil {
+			continue
+		}
+		certPEM = append(certPEM, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: c.Raw})...)
+	}
+	return certPEM
 }
 
 func (s *Store) RootThumbprintSHA1() string {
@@ -134,10 +199,23 @@ func (s *Store) RootThumbprintSHA1() string {
 	if len(der) == 0 {
 		return ""
 	}
+	return thumbprintSHA1(der)
+}
+
+func thumbprintSHA1(der []byte) string {
 	sum := sha1.Sum(der)
 	return strings.ToUpper(hex.EncodeToString(sum[:]))
 }
 
+func (s *Store) RootFingerprintSHA256() string {
+	der := s.RootCertDER()
+	if len(der) == 0 {
+		return ""
+	}
+	sum := sha256.Sum256(der)
+	return strings.ToUpper(hex.EncodeToString(sum[:]))
+}
+
 func (s *Store) LeafCert(host string) (certPEM []byte, keyPEM []byte, err error) {
 	name := strings.TrimSpace(host)
 	name = strings.TrimSuffix(name, ".")
@@ -145,15 +223,74 @@ func (s *Store) LeafCert(host string) (certPEM []byte, keyPEM []byte, err error)
 		return nil, nil, fmt.Errorf("host vazio")
 	}
 
-	s.leafMu.Lock()
-	if cached, ok := s.leafCert[name]; ok {
-		certPEM = append([]byte(nil), cached.certPEM...)
-		keyPEM = append([]byte(nil), cached.keyPEM...)
-		s.leafMu.Unlock()
-		return certPEM, keyPEM, nil
+	if certPEM, keyPEM, ok := s.cachedLeaf(name); ok {
+		return s.withChain(certPEM), keyPEM, nil
+	}
+
+	now := time.Now()
+	leaf := &x509.Certificate{
+		Subject: pkix.Name{
+			CommonName: name,
+		},
+		NotBefore: now.Add(-1 * time.Hour),
+		NotAfter:  now.AddDate(0, 0, 7),
+	}
+
+	if ip := net.ParseIP(name); ip != nil {
+		leaf.IPAddresses = []net.IP{ip}
+	} else {
+		leaf.DNSNames = []string{name}
+	}
+
+	certPEM, keyPEM, err = s.issueLeaf(name, leaf)
+	if err != nil {
+		return nil, nil, err
+	}
+	return s.withChain(certPEM), keyPEM, nil
+}
+
+func (s *Store) MirrorLeafCert(host string, fetch func() (*x509.Certificate, error)) (certPEM []byte, keyPEM []byte, err error) {
+	name := strings.TrimSuffix(strings.TrimSpace(host), ".")
+	if name == "" {
+		return nil, nil, fmt.Errorf("host vazio")
+	}
+
+	cacheKey := mirrorPrefix + name
+	if certPEM, keyPEM, ok := s.cachedLeaf(cacheKey); ok {
+		return s.withChain(certPEM), keyPEM, nil
 	}
-	s.leafMu.Unlock()
 
+	upstream, err := fetch()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	leaf := &x509.Certificate{
+		Subject:        upstream.Subject,
+		DNSNames:       append([]string(nil), upstream.DNSNames...),
+		IPAddresses:    append([]net.IP(nil), upstream.IPAddresses...),
+		EmailAddresses: append([]string(nil), upstream.EmailAddresses...),
+		URIs:           upstream.URIs,
+		NotBefore:      upstream.NotBefore,
+		NotAfter:       upstream.NotAfter,
+	}
+	leaf.Subject.ExtraNames = nil
+	if len(leaf.DNSNames) == 0 && len(leaf.IPAddresses) == 0 {
+		if ip := net.ParseIP(name); ip != nil {
+			leaf.IPAddresses = []net.IP{ip}
+		} else {
+			leaf.DNSNames = []string{name}
+		}
+	}
+
+	certPEM, keyPEM, err = s.issueLeaf(cacheKey, leaf)
+	if err != nil {
+		return nil, nil, err
+	}
+	return s.withChain(certPEM), keyPEM, nil
+}
+
+func (s *Store) issueLeaf(key string, leaf *x509.Certificate) ([]byte, []byte, error) {
 	s.mu.Lock()
 	caCert := s.caCert
 	caKey := s.caKey
@@ -169,43 +306,24 @@ func (s *Store) LeafCert(host string) (certPEM []byte, keyPEM []byte, err error)
 		return nil, nil, err
 	}
 
-	now := time.Now()
-	leaf := &x509.Certificate{
-		SerialNumber: serial,
-		Subject: pkix.Name{
-			CommonName: name,
-		},
-		NotBefore:             now.Add(-1 * time.Hour),
-		NotAfter:              now.AddDate(0, 0, 7),
-		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
-		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
-		BasicConstraintsValid: true,
-	}
-
-	if ip := net.ParseIP(name); ip != nil {
-		leaf.IPAddresses = []net.IP{ip}
-	} else {
-		leaf.DNSNames = []string{name}
-	}
+	leaf.SerialNumber = serial
+	leaf.KeyUsage = x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment
+	leaf.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth}
+	leaf.BasicConstraintsValid = true
 
 	der, err := x509.CreateCertificate(rand.Reader, leaf, caCert, &leafKey.PublicKey, caKey)
 	if err != nil {
 		return nil, nil, err
 	}
 
-	certPEM = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
 	keyDER, err := x509.MarshalPKCS8PrivateKey(leafKey)
 	if err != nil {
 		return nil, nil, err
 	}
-	keyPEM = pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER})
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER})
 
-	s.leafMu.Lock()
-	if s.leafCert == nil {
-		s.leafCert = map[string]*tlsCert{}
-	}
-	s.leafCert[name] = &tlsCert{certPEM: certPEM, keyPEM: keyPEM}
-	s.leafMu.Unlock()
+	s.putLeaf(key, certPEM, keyPEM)
 
 	return append([]byte(nil), certPEM...), append([]byte(nil), keyPEM...), nil
 }
@@ -229,19 +347,3 @@ func parseCertPEM(pemBytes []byte) (*x509.Certificate, error) {
 	}
 	return x509.ParseCertificate(b.Bytes)
 }
-
-func parseECDSAKeyPEM(pemBytes []byte) (*ecdsa.PrivateKey, error) {
-	b, _ := pem.Decode(pemBytes)
-	if b == nil {
-		return nil, fmt.Errorf("key pem inválido")
-	}
-	keyAny, err := x509.ParsePKCS8PrivateKey(b.Bytes)
-	if err != nil {
-		return nil, err
-	}
-	key, ok := keyAny.(*ecdsa.PrivateKey)
-	if !ok {
-		return nil, fmt.Errorf("key não é ECDSA")
-	}
-	return key, nil
-}
@@ -1,6 +1,31 @@
 package ca
 
-import "testing"
+import (
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestLoad_DoesNotCreate(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := Load(dir); !errors.Is(err, ErrNoCA) {
+		t.Fatalf("expected ErrNoCA, got %v", err)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+		t.Fatalf("Load created files: %v", entries)
+	}
+	st, err := LoadOrCreate(dir)
+	if err != nil {
+		t.Fatalf("LoadOrCreate: %v", err)
+	}
+	loaded, err := Load(dir)
+	if err != nil || loaded.RootThumbprintSHA1() != st.RootThumbprintSHA1() {
+		t.Fatalf("Load after create: %v", err)
+	}
+}
 
 func TestRootThumbprintSHA1_Format(t *testing.T) {
 	st, err := LoadOrCreate(t.TempDir())
@@ -18,3 +43,107 @@ func TestRootThumbprintSHA1_Format(t *testing.T) {
 		t.Fatalf("invalid char %q in %q", r, thumb)
 	}
 }
+
+func TestMirrorLeafCert_ClonesUpstream(t *testing.T) {
+	dir := t.TempDir()
+	st, err := LoadOrCreate(dir)
+	if err != nil {
+		t.Fatalf("LoadOrCreate: %v", err)
+	}
+
+	upstream := &x509.Certificate{
+		Subject:   pkix.Name{CommonName: "*.example.com", Organization: []string{"Example Inc"}},
+		DNSNames:  []string{"*.example.com", "example.com"},
+		NotBefore: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+		NotAfter:  time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+	calls := 0
+	fetch := func() (*x509.Certificate, error) {
+		calls++
+		return upstream, nil
+	}
+
+	certPEM, _, err := st.MirrorLeafCert("api.example.com", fetch)
+	if err != nil {
+		t.Fatalf("MirrorLeafCert: %v", err)
+	}
+	leaf, err := parseCertPEM(certPEM)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if leaf.Subject.CommonName != "*.example.com" || len(leaf.Subject.Organization) != 1 {
+		t.Fatalf("unexpected subject %v", leaf.Subject)
+	}
+	if len(leaf.DNSNames) != 2 || leaf.DNSNames[0] != "*.example.com" {
+		t.Fatalf("unexpected SANs %v", leaf.DNSNames)
+	}
+	if !leaf.NotBefore.Equal(upstream.NotBefore) || !leaf.NotAfter.Equal(upstream.NotAfter) {
+		t.Fatalf("validity not mirrored: %s - %s", leaf.NotBefore, leaf.NotAfter)
+	}
+
+	if _, _, err := st.MirrorLeafCert("api.example.com", fetch); err != nil {
+		t.Fatalf("MirrorLeafCert (cached): %v", err)
+	}
+	if calls != 1 {
+		t.Fatalf("expected upstream fetched once, got %d", calls)
+	}
+
+	reloaded, err := LoadOrCreate(dir)
+	if err != nil {
+		t.Fatalf("LoadOrCreate (reload): %v", err)
+	}
+	if _, _, err := reloaded.MirrorLeafCert("api.example.com", fetch); err != nil {
+		t.Fatalf("MirrorLeafCert (disk): %v", err)
+	}
+	if calls != 1 {
+		t.Fatalf("expected mirrored leaf reused from disk, got %d fetches", calls)
+	}
+}
+
+func TestLeafCache_PersistsAndEvicts(t *testing.T) {
+	dir := t.TempDir()
+	st, err := LoadOrCreate(dir)
+	if err != nil {
+		t.Fatalf("LoadOrCreate: %v", err)
+	}
+	st.MaxLeaves = 2
+
+	first, _, err := st.LeafCert("a.example.com")
+	if err != nil {
+		t.Fatalf("LeafCert: %v", err)
+	}
+
+	st2, err := LoadOrCreate(dir)
+	if err != nil {
+		t.Fatalf("LoadOrCreate: %v", err)
+	}
+	st2.MaxLeaves = 2
+	again, _, err := st2.LeafCert("a.example.com")
+	if err != nil {
+		t.Fatalf("LeafCert: %v", err)
+	}
+	if string(first) != string(again) {
+		t.Fatalf("expected leaf loaded from disk after restart")
+	}
+
+	for _, h := range []string{"b.example.com", "c.example.com"} {
+		if _, _, err := st2.LeafCert(h); err != nil {
+			t.Fatalf("LeafCert(%s): %v", h, err)
+		}
+	}
+	leaves, err := st2.ListLeaves()
+	if err != nil {
+		t.Fatalf("ListLeaves: %v", err)
+	}
+	if len(leaves) != 2 {
+		t.Fatalf("expected cache capped at 2 leaves, got %d", len(leaves))
+	}
+
+	n, err := st2.PurgeLeaves("")
+	if err != nil || n != 2 {
+		t.Fatalf("PurgeLeaves: n=%d err=%v", n, err)
+	}
+	if leaves, _ := st2.ListLeaves(); len(leaves) != 0 {
+		t.Fatalf("expected empty cache after purge, got %d", len(leaves))
+	}
+}
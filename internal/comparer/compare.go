@@ -0,0 +1,86 @@
+package comparer
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+
+	"burpui/internal/httpraw"
+)
+
+type Side struct {
+	Label  string
+	Start  string
+	Status int
+	Header http.Header
+	Body   []byte
+}
+
+func (s Side) Decoded() Side {
+	if b, err := httpraw.DecodeBody(s.Header, s.Body); err == nil {
+		s.Body = b
+	}
+	return s
+}
+
+type HeaderState int
+
+const (
+	HeaderSame HeaderState = iota
+	HeaderChanged
+	HeaderOnlyA
+	HeaderOnlyB
+)
+
+type HeaderRow struct {
+	Name  string
+	A     []string
+	B     []string
+	State HeaderState
+}
+
+func Headers(a, b http.Header) []HeaderRow {
+	names := map[string]bool{}
+	for k := range a {
+		names[http.CanonicalHeaderKey(k)] = true
+	}
+	for k := range b {
+		names[http.CanonicalHeaderKey(k)] = true
+	}
+	out := make([]HeaderRow, 0, len(names))
+	for name := range names {
+		row := HeaderRow{Name: name, A: a.Values(name), B: b.Values(name)}
+		switch {
+		case len(row.B) == 0:
+			row.State = HeaderOnlyA
+		case len(row.A) == 0:
+			row.State = HeaderOnlyB
+		case strings.Join(row.A, "\n") != strings.Join(row.B, "\n"):
+			row.State = HeaderChanged
+		}
+		out = append(out, row)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+type Summary struct {
+	StatusA, StatusB int
+	LenA, LenB       int
+	HeadersChanged   int
+	BodyChanges      int
+}
+
+func (s Summary) Identical() bool {
+	return s.StatusA == s.StatusB && s.LenA == s.LenB && s.HeadersChanged == 0 && s.BodyChanges == 0
+}
+
+func Summarize(a, b Side, headers []HeaderRow, body []Op) Summary {
+	s := Summary{StatusA: a.Status, StatusB: b.Status, LenA: len(a.Body), LenB: len(b.Body), BodyChanges: Changes(body)}
+	for _, h := range headers {
+		if h.State != HeaderSame {
+			s.HeadersChanged++
+		}
+	}
+	return s
+}
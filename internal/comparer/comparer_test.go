@@ -0,0 +1,144 @@
+package comparer
+
+import (
+	"bytes"
+	"compress/gzip"
+	"math/rand"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func apply(ops []Op) (string, string) {
+	var a, b strings.Builder
+	for _, op := range ops {
+		if op.Kind != Insert {
+			a.WriteString(op.Text)
+		}
+		if op.Kind != Delete {
+			b.WriteString(op.Text)
+		}
+	}
+	return a.String(), b.String()
+}
+
+func TestWords(t *testing.T) {
+	a := `{"user":"alice","role":"user","id":10}`
+	b := `{"user":"alice","role":"admin","id":10}`
+	ops := Words(a, b)
+	if ga, gb := apply(ops); ga != a || gb != b {
+		t.Fatalf("ops do not reproduce inputs: %q %q", ga, gb)
+	}
+	var del, ins []string
+	for _, op := range ops {
+		switch op.Kind {
+		case Delete:
+			del = append(del, op.Text)
+		case Insert:
+			ins = append(ins, op.Text)
+		}
+	}
+	if strings.Join(del, "|") != "user" || strings.Join(ins, "|") != "admin" {
+		t.Fatalf("del=%q ins=%q", del, ins)
+	}
+	if Changes(Words("same text", "same text")) != 0 {
+		t.Fatalf("identical inputs reported changes")
+	}
+}
+
+func TestBytes_RandomRoundTrip(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 200; i++ {
+		a := make([]byte, r.Intn(40))
+		r.Read(a)
+		for j := range a {
+			a[j] = 'a' + a[j]%4
+		}
+		b := append([]byte(nil), a...)
+		for k := r.Intn(6); k > 0; k-- {
+			p := r.Intn(len(b) + 1)
+			switch r.Intn(3) {
+			case 0:
+				b = append(b[:p], append([]byte{'x'}, b[p:]...)...)
+			case 1:
+				if p < len(b) {
+					b = append(b[:p], b[p+1:]...)
+				}
+			default:
+				if p < len(b) {
+					b[p] = 'z'
+				}
+			}
+		}
+		ga, gb := apply(Bytes(a, b))
+		if !bytes.Equal([]byte(ga), a) || !bytes.Equal([]byte(gb), b) {
+			t.Fatalf("round trip failed for %q -> %q", a, b)
+		}
+	}
+}
+
+func TestBytes_FallbackOnLargeDifference(t *testing.T) {
+	a := bytes.Repeat([]byte("a"), 1500)
+	b := bytes.Repeat([]byte("b"), 1500)
+	ops := Bytes(append([]byte("head"), a...), append([]byte("head"), b...))
+	if len(ops) != 3 || ops[0].Text != "head" || ops[1].Kind != Delete || ops[2].Kind != Insert {
+		t.Fatalf("unexpected fallback ops (%d)", len(ops))
+	}
+}
+
+func TestSimilarity(t *testing.T) {
+	if Similarity("", "") != 1 || Similarity("abc", "abc") != 1 {
+		t.Fatalf("identical inputs should be 1")
+	}
+	if s := Similarity("hello world", "hello there"); s < 0.5 || s > 0.7 {
+		t.Fatalf("partial similarity = %.2f", s)
+	}
+	if s := Similarity("foo", "bar"); s != 0 {
+		t.Fatalf("disjoint similarity = %.2f", s)
+	}
+}
+
+func TestHeadersAndSummary(t *testing.T) {
+	a := Side{Status: 200, Header: http.Header{"Content-Type": {"text/html"}, "X-A": {"1"}, "Set-Cookie": {"s=1"}}, Body: []byte("hello world")}
+	b := Side{Status: 403, Header: http.Header{"Content-Type": {"text/html"}, "X-B": {"2"}, "Set-Cookie": {"s=2"}}, Body: []byte("hello there")}
+	rows := Headers(a.Header, b.Header)
+	want := map[string]HeaderState{"Content-Type": HeaderSame, "Set-Cookie": HeaderChanged, "X-A": HeaderOnlyA, "X-B": HeaderOnlyB}
+	if len(rows) != len(want) {
+		t.Fatalf("rows = %+v", rows)
+	}
+	for _, r := range rows {
+		if want[r.Name] != r.State {
+			t.Fatalf("%s: state %d, want %d", r.Name, r.State, want[r.Name])
+		}
+	}
+	s := Summarize(a, b, rows, Words(string(a.Body), string(b.Body)))
+	if s.StatusA != 200 || s.StatusB != 403 || s.LenA != 11 || s.LenB != 11 || s.HeadersChanged != 3 || s.BodyChanges != 2 || s.Identical() {
+		t.Fatalf("summary = %+v", s)
+	}
+}
+
+func TestDecodedCompressedSides(t *testing.T) {
+	zip := func(s string) []byte {
+		var b bytes.Buffer
+		zw := gzip.NewWriter(&b)
+		zw.Write([]byte(s))
+		zw.Close()
+		return b.Bytes()
+	}
+	h := http.Header{"Content-Encoding": {"gzip"}}
+	a := Side{Status: 200, Header: h, Body: zip("<p>usuário alice, saldo 100</p>")}.Decoded()
+	b := Side{Status: 200, Header: h, Body: zip("<p>usuário alice, saldo 250</p>")}.Decoded()
+	ops := Words(string(a.Body), string(b.Body))
+	if x, y := apply(ops); x != "<p>usuário alice, saldo 100</p>" || y != "<p>usuário alice, saldo 250</p>" {
+		t.Fatalf("decoded bodies = %q %q", x, y)
+	}
+	s := Summarize(a, b, Headers(a.Header, b.Header), ops)
+	if s.LenA != len("<p>usuário alice, saldo 100</p>") || s.BodyChanges != 2 || s.HeadersChanged != 0 {
+		t.Fatalf("summary = %+v", s)
+	}
+
+	raw := Side{Header: http.Header{"Content-Encoding": {"br"}}, Body: []byte{0x1b, 0x02}}.Decoded()
+	if !bytes.Equal(raw.Body, []byte{0x1b, 0x02}) {
+		t.Fatalf("undecodable body changed: %v", raw.Body)
+	}
+}
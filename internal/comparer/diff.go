@@ -0,0 +1,225 @@
+package comparer
+
+import "strings"
+
+type OpKind int
+
+const (
+	Equal OpKind = iota
+	Delete
+	Insert
+)
+
+type Op struct {
+	Kind OpKind
+	Text string
+}
+
+const maxEdits = 1000
+
+func Words(a, b string) []Op {
+	ta, tb := tokenize(a), tokenize(b)
+	return group(diff(len(ta), len(tb), func(i, j int) bool { return ta[i] == tb[j] }),
+		func(i int) string { return ta[i] }, func(j int) string { return tb[j] })
+}
+
+func Bytes(a, b []byte) []Op {
+	return group(diff(len(a), len(b), func(i, j int) bool { return a[i] == b[j] }),
+		func(i int) string { return string(a[i : i+1]) }, func(j int) string { return string(b[j : j+1]) })
+}
+
+func Changes(ops []Op) int {
+	n := 0
+	for _, op := range ops {
+		if op.Kind != Equal {
+			n++
+		}
+	}
+	return n
+}
+
+func Similarity(a, b string) float64 {
+	if len(a)+len(b) == 0 {
+		return 1
+	}
+	if a == b {
+		return 1
+	}
+	same := 0
+	for _, op := range Words(a, b) {
+		if op.Kind == Equal {
+			same += len(op.Text)
+		}
+	}
+	return 2 * float64(same) / float64(len(a)+len(b))
+}
+
+func tokenize(s string) []string {
+	var out []string
+	start := 0
+	class := -1
+	for i, r := range s {
+		c := runeClass(r)
+		if i > start && (c != class || c == 2) {
+			out = append(out, s[start:i])
+			start = i
+		}
+		class = c
+	}
+	if start < len(s) {
+		out = append(out, s[start:])
+	}
+	return out
+}
+
+func runeClass(r rune) int {
+	switch {
+	case r == ' ' || r == '\t' || r == '\r' || r == '\n':
+		return 0
+	case r == '_' || r == '-' || r >= '0' && r <= '9' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r > 127:
+		return 1
+	}
+	return 2
+}
+
+func diff(n, m int, eq func(i, j int) bool) []OpKind {
+	pre := 0
+	for pre < n && pre < m && eq(pre, pre) {
+		pre++
+	}
+	suf := 0
+	for suf < n-pre && suf < m-pre && eq(n-1-suf, m-1-suf) {
+		suf++
+	}
+
+	ops := make([]OpKind, 0, n+m)
+	for i := 0; i < pre; i++ {
+		ops = append(ops, Equal)
+	}
+	mid := myers(n-pre-suf, m-pre-suf, func(i, j int) bool { return eq(pre+i, pre+j) })
+	if mid == nil {
+		for i := 0; i < n-pre-suf; i++ {
+			ops = append(ops, Delete)
+		}
+		for j := 0; j < m-pre-suf; j++ {
+			ops = append(ops, Insert)
+		}
+	} else {
+		ops = append(ops, mid...)
+	}
+	for i := 0; i < suf; i++ {
+		ops = append(ops, Equal)
+	}
+	return ops
+}
+
+func myers(n, m int, eq func(i, j int) bool) []OpKind {
+	if n == 0 && m == 0 {
+		return []OpKind{}
+	}
+	limit := n + m
+	if limit > maxEdits {
+		limit = maxEdits
+	}
+	off := limit + 1
+	v := make([]int, 2*off+1)
+	var trace [][]int
+	for d := 0; d <= limit; d++ {
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[off+k-1] < v[off+k+1]) {
+				x = v[off+k+1]
+			} else {
+				x = v[off+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && eq(x, y) {
+				x++
+				y++
+			}
+			v[off+k] = x
+			if x >= n && y >= m {
+				trace = append(trace, append([]int(nil), v[off-d:off+d+1]...))
+				return backtrack(trace, n, m)
+			}
+		}
+		trace = append(trace, append([]int(nil), v[off-d:off+d+1]...))
+	}
+	return nil
+}
+
+func backtrack(trace [][]int, n, m int) []OpKind {
+	x, y := n, m
+	var rev []OpKind
+	for d := len(trace) - 1; d > 0; d-- {
+		prev := trace[d-1]
+		get := func(k int) int { return prev[k+d-1] }
+		k := x - y
+		pk := k - 1
+		if k == -d || (k != d && get(k-1) < get(k+1)) {
+			pk = k + 1
+		}
+		px := get(pk)
+		py := px - pk
+		sx, sy := px, py
+		if pk == k+1 {
+			sy++
+		} else {
+			sx++
+		}
+		for x > sx && y > sy {
+			rev = append(rev, Equal)
+			x--
+			y--
+		}
+		if pk == k+1 {
+			rev = append(rev, Insert)
+		} else {
+			rev = append(rev, Delete)
+		}
+		x, y = px, py
+	}
+	for x > 0 && y > 0 {
+		rev = append(rev, Equal)
+		x--
+		y--
+	}
+	out := make([]OpKind, len(rev))
+	for i, op := range rev {
+		out[len(rev)-1-i] = op
+	}
+	return out
+}
+
+func group(ops []OpKind, a, b func(int) string) []Op {
+	var out []Op
+	var cur strings.Builder
+	kind := OpKind(-1)
+	flush := func() {
+		if cur.Len() > 0 {
+			out = append(out, Op{Kind: kind, Text: cur.String()})
+			cur.Reset()
+		}
+	}
+	i, j := 0, 0
+	for _, op := range ops {
+		if op != kind {
+			flush()
+			kind = op
+		}
+		switch op {
+		case Equal:
+			cur.WriteString(a(i))
+			i++
+			j++
+		case Delete:
+			cur.WriteString(a(i))
+			i++
+		case Insert:
+			cur.WriteString(b(j))
+			j++
+		}
+	}
+	flush()
+	return out
+}
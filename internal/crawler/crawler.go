@@ -0,0 +1,197 @@
+package crawler
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"burpui/internal/httpraw"
+	"burpui/internal/proxy"
+	"burpui/internal/sitemap"
+)
+
+type Options struct {
+	ProxyURL string
+	MaxDepth int
+	Rate     float64
+	MaxPages int
+	MaxBody  int64
+	Timeout  time.Duration
+	Scope    *sitemap.Scope
+}
+
+type Stats struct {
+	Fetched int
+	Errors  int
+	Found   int
+	Queued  int
+}
+
+func (s Stats) String() string {
+	return fmt.Sprintf("%d páginas, %d erros, %d links, %d na fila", s.Fetched, s.Errors, s.Found, s.Queued)
+}
+
+type Crawler struct {
+	opts   Options
+	client *http.Client
+}
+
+func New(opts Options) (*Crawler, error) {
+	if opts.MaxDepth < 0 {
+		opts.MaxDepth = 0
+	}
+	if opts.MaxPages <= 0 {
+		opts.MaxPages = 200
+	}
+	if opts.MaxBody <= 0 {
+		opts.MaxBody = 2 << 20
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = 15 * time.Second
+	}
+	tr := http.DefaultTransport.(*http.Transport).Clone()
+	tr.Proxy = nil
+	if opts.ProxyURL != "" {
+		pu, err := url.Parse(opts.ProxyURL)
+		if err != nil {
+			return nil, fmt.Errorf("crawler: proxy inválido: %w", err)
+		}
+		tr.Proxy = http.ProxyURL(pu)
+		tr.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+	}
+	client := &http.Client{
+		Timeout:   opts.Timeout,
+		Transport: tr,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	return &Crawler{opts: opts, client: client}, nil
+}
+
+func Feed(t *sitemap.Tree, f *proxy.Flow) bool {
+	if !t.AddFlow(f) {
+		return false
+	}
+	if f.RespTruncated {
+		return true
+	}
+	body, err := httpraw.DecodeBody(f.ResponseHeader, f.ResponseBody)
+	if err != nil {
+		return true
+	}
+	for _, l := range Extract(f.URL, f.ResponseHeader, body) {
+		t.AddURL(l.URL, l.Params...)
+	}
+	return true
+}
+
+type target struct {
+	url   string
+	depth int
+}
+
+func (c *Crawler) Run(ctx context.Context, seeds []string, progress func(Stats)) (Stats, error) {
+	var st Stats
+	hosts := map[string]bool{}
+	seen := map[string]bool{}
+	var queue []target
+	for _, s := range seeds {
+		u, err := url.Parse(s)
+		if err != nil || u.Host == "" {
+			continue
+		}
+		u.Fragment = ""
+		hosts[u.Host] = true
+		if !seen[u.String()] {
+			seen[u.String()] = true
+			queue = append(queue, target{url: u.String()})
+		}
+	}
+
+	var tick <-chan time.Time
+	if c.opts.Rate > 0 {
+		t := time.NewTicker(time.Duration(float64(time.Second) / c.opts.Rate))
+		defer t.Stop()
+		tick = t.C
+	}
+
+	for len(queue) > 0 && st.Fetched+st.Errors < c.opts.MaxPages {
+		if tick != nil && st.Fetched+st.Errors > 0 {
+			select {
+			case <-ctx.Done():
+				return st, ctx.Err()
+			case <-tick:
+			}
+		}
+		if err := ctx.Err(); err != nil {
+			return st, err
+		}
+		next := queue[0]
+		queue = queue[1:]
+
+		links, err := c.fetch(ctx, next.url)
+		if err != nil {
+			st.Errors++
+		} else {
+			st.Fetched++
+		}
+		st.Found += len(links)
+		if next.depth < c.opts.MaxDepth {
+			for _, l := range links {
+				if l.Method != http.MethodGet {
+					continue
+				}
+				u, err := url.Parse(l.URL)
+				if err != nil || IsStatic(u) || !c.allowed(u, hosts) {
+					continue
+				}
+				if seen[u.String()] {
+					continue
+				}
+				seen[u.String()] = true
+				queue = append(queue, target{url: u.String(), depth: next.depth + 1})
+			}
+		}
+		st.Queued = len(queue)
+		if progress != nil {
+			progress(st)
+		}
+	}
+	return st, nil
+}
+
+func (c *Crawler) allowed(u *url.URL, hosts map[string]bool) bool {
+	if c.opts.Scope.Empty() {
+		return hosts[u.Host]
+	}
+	return c.opts.Scope.InScope(u.String())
+}
+
+func (c *Crawler) fetch(ctx context.Context, rawURL string) ([]Link, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", "burpui-crawler")
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, c.opts.MaxBody))
+	if err != nil {
+		return nil, err
+	}
+	links := Extract(rawURL, resp.Header, body)
+	if loc := resp.Header.Get("Location"); loc != "" {
+		if u, err := req.URL.Parse(loc); err == nil {
+			links = append(links, Link{URL: u.String(), Kind: KindLink, Method: http.MethodGet})
+		}
+	}
+	return links, nil
+}
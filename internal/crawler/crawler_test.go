@@ -0,0 +1,199 @@
+package crawler
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"burpui/internal/proxy"
+	"burpui/internal/sitemap"
+)
+
+func TestExtract_HTML(t *testing.T) {
+	body := `<html><head><link rel="stylesheet" href="/css/app.css"><script src="/js/app.js?v=1"></script></head>
+<body><a href="about.html#team">Sobre</a> <a href="mailto:x@example.com">m</a> <a href='/search?q=a&amp;page=2'>s</a>
+<form action="/login" method="post"><input name="user"><input type="password" name="pass"><button name="go">ok</button></form>
+<form><input name="q"></form>
+<script>fetch("/api/v1/me").then(r => r.json()); var x = "/internal/reports/export"; var y = "/"; var re = "/g";</script>
+<p>https://cdn.example.com/lib.js</p></body></html>`
+	links := Extract("https://app.example.com/home/index", http.Header{"Content-Type": {"text/html"}}, []byte(body))
+
+	got := map[string]Link{}
+	for _, l := range links {
+		got[string(l.Kind)+" "+l.URL] = l
+	}
+	for _, want := range []string{
+		"link https://app.example.com/css/app.css",
+		"script https://app.example.com/js/app.js?v=1",
+		"link https://app.example.com/home/about.html",
+		"link https://app.example.com/search?q=a&page=2",
+		"api https://app.example.com/api/v1/me",
+		"api https://app.example.com/internal/reports/export",
+		"link https://cdn.example.com/lib.js",
+	} {
+		if _, ok := got[want]; !ok {
+			t.Fatalf("missing %q in %v", want, keys(got))
+		}
+	}
+	login := got["form https://app.example.com/login"]
+	if login.Method != "POST" || strings.Join(login.Params, ",") != "go,pass,user" {
+		t.Fatalf("login form = %+v", login)
+	}
+	self := got["form https://app.example.com/home/index"]
+	if self.Method != "GET" || strings.Join(self.Params, ",") != "q" {
+		t.Fatalf("self form = %+v", self)
+	}
+	for k := range got {
+		if strings.Contains(k, "mailto") || strings.HasSuffix(k, "example.com/") || strings.HasSuffix(k, "/g") {
+			t.Fatalf("unexpected link %q", k)
+		}
+	}
+}
+
+func TestExtract_JS(t *testing.T) {
+	js := `const base="/api/v2";axios.post('/graphql',{});xhr.open("GET", "/ajax/list.php");const p = ` + "`/users/{id}/orders`" + `;`
+	links := Extract("https://app.example.com/static/app.js", http.Header{"Content-Type": {"application/javascript"}}, []byte(js))
+	var urls []string
+	for _, l := range links {
+		urls = append(urls, l.URL)
+	}
+	sort.Strings(urls)
+	want := "https://app.example.com/ajax/list.php https://app.example.com/api/v2 https://app.example.com/graphql"
+	if got := strings.Join(urls, " "); got != want {
+		t.Fatalf("js links = %s", got)
+	}
+	if Extract("https://x.test/a.png", http.Header{"Content-Type": {"image/png"}}, []byte("/api/v1")) != nil {
+		t.Fatalf("binary bodies should not be parsed")
+	}
+}
+
+func TestFeed(t *testing.T) {
+	tr := sitemap.New()
+	f := &proxy.Flow{ID: 1, Method: "GET", URL: "https://app.example.com/", StatusCode: 200,
+		ResponseHeader: http.Header{"Content-Type": {"text/html"}},
+		ResponseBody:   []byte(`<a href="/admin">a</a><form action="/login" method="post"><input name="user"></form>`)}
+	if !Feed(tr, f) || Feed(tr, f) {
+		t.Fatalf("Feed should add the flow exactly once")
+	}
+	admin := tr.Find("https://app.example.com/admin")
+	if admin == nil || !admin.Discovered() {
+		t.Fatalf("admin node = %+v", admin)
+	}
+	if login := tr.Find("https://app.example.com/login"); login == nil || strings.Join(login.ParamList(), ",") != "user" {
+		t.Fatalf("login node = %+v", login)
+	}
+}
+
+func TestFeed_CompressedAndTruncated(t *testing.T) {
+	var gz bytes.Buffer
+	w := gzip.NewWriter(&gz)
+	w.Write([]byte(`<a href="/a">1</a><a href="/b">2</a><a href="/c">3</a><a href="/d">4</a>`))
+	w.Close()
+
+	tr := sitemap.New()
+	f := &proxy.Flow{ID: 1, Method: "GET", URL: "https://app.example.com/", StatusCode: 200,
+		ResponseHeader: http.Header{"Content-Type": {"text/html"}, "Content-Encoding": {"gzip"}},
+		ResponseBody:   gz.Bytes()}
+	if !Feed(tr, f) {
+		t.Fatalf("Feed should add the flow")
+	}
+	for _, p := range []string{"/a", "/b", "/c", "/d"} {
+		if n := tr.Find("https://app.example.com" + p); n == nil || !n.Discovered() {
+			t.Fatalf("%s not discovered from gzipped page", p)
+		}
+	}
+
+	f = &proxy.Flow{ID: 2, Method: "GET", URL: "https://other.example.com/", StatusCode: 200,
+		ResponseHeader: http.Header{"Content-Type": {"text/html"}},
+		ResponseBody:   []byte(`<a href="/cut">`), RespTruncated: true}
+	if !Feed(tr, f) {
+		t.Fatalf("Feed should add the truncated flow")
+	}
+	if tr.Find("https://other.example.com/cut") != nil {
+		t.Fatalf("links extracted from a truncated body")
+	}
+}
+
+func TestCrawler_DepthScopeAndLimits(t *testing.T) {
+	var mu sync.Mutex
+	hits := map[string]int{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		hits[r.URL.Path]++
+		mu.Unlock()
+		w.Header().Set("Content-Type", "text/html")
+		switch r.URL.Path {
+		case "/":
+			fmt.Fprintf(w, `<a href="/a">a</a><a href="/b">b</a><a href="/logout">x</a><a href="/logo.png">i</a><a href="https://other.test/">o</a><form action="/post" method="post"></form>`)
+		case "/a":
+			fmt.Fprint(w, `<a href="/a/deep">d</a><a href="/">home</a>`)
+		case "/a/deep":
+			fmt.Fprint(w, `<a href="/a/deeper">d</a>`)
+		case "/b":
+			http.Redirect(w, r, "/c", http.StatusFound)
+		default:
+			fmt.Fprint(w, "ok")
+		}
+	}))
+	defer srv.Close()
+
+	scope := sitemap.NewScope()
+	scope.Include(srv.URL)
+	scope.Exclude(srv.URL + "/logout")
+	c, err := New(Options{MaxDepth: 2, Scope: scope})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var last Stats
+	st, err := c.Run(context.Background(), []string{srv.URL + "/"}, func(s Stats) { last = s })
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if last != st {
+		t.Fatalf("progress %+v != final %+v", last, st)
+	}
+
+	mu.Lock()
+	for _, p := range []string{"/", "/a", "/b", "/a/deep", "/c"} {
+		if hits[p] != 1 {
+			t.Fatalf("%s fetched %d times (hits %v)", p, hits[p], hits)
+		}
+	}
+	for _, p := range []string{"/logout", "/logo.png", "/a/deeper", "/post"} {
+		if hits[p] != 0 {
+			t.Fatalf("%s should not be fetched (hits %v)", p, hits)
+		}
+	}
+	mu.Unlock()
+	if st.Fetched != 5 {
+		t.Fatalf("stats = %+v", st)
+	}
+
+	c, _ = New(Options{MaxDepth: 5, MaxPages: 2})
+	st, _ = c.Run(context.Background(), []string{srv.URL + "/"}, nil)
+	if st.Fetched != 2 {
+		t.Fatalf("MaxPages not honoured: %+v", st)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := c.Run(ctx, []string{srv.URL + "/"}, nil); err == nil {
+		t.Fatalf("expected cancellation error")
+	}
+}
+
+func keys(m map[string]Link) []string {
+	var out []string
+	for k := range m {
+		out = append(out, k)
+	}
+	sort.Strings(out)
+	return out
+}
@@ -0,0 +1,189 @@
+package crawler
+
+import (
+	"html"
+	"mime"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+type Kind string
+
+const (
+	KindLink   Kind = "link"
+	KindForm   Kind = "form"
+	KindScript Kind = "script"
+	KindAPI    Kind = "api"
+)
+
+type Link struct {
+	URL    string
+	Kind   Kind
+	Method string
+	Params []string
+}
+
+var (
+	tagRe      = regexp.MustCompile(`(?is)<(a|link|area|img|iframe|frame|script|source|embed)\b[^>]*>`)
+	formRe     = regexp.MustCompile(`(?is)<form\b([^>]*)>(.*?)</form>`)
+	scriptRe   = regexp.MustCompile(`(?is)<script\b[^>]*>(.*?)</script>`)
+	inputRe    = regexp.MustCompile(`(?is)<(?:input|select|textarea|button)\b[^>]*>`)
+	attrRe     = regexp.MustCompile(`(?is)\b([a-z-]+)\s*=\s*(?:"([^"]*)"|'([^']*)'|([^\s>]+))`)
+	absRe      = regexp.MustCompile(`https?://[a-zA-Z0-9.\-]+(?::\d+)?(?:/[^\s"'<>\\)\x60]*)?`)
+	jsPathRe   = regexp.MustCompile("[\"'\x60](/[a-zA-Z0-9_\\-./{}:@%~+]*)[\"'\x60]")
+	jsCallRe   = regexp.MustCompile("(?i)(?:fetch|axios(?:\\.(?:get|post|put|patch|delete))?|\\.open\\s*\\(\\s*[\"'][a-z]+[\"']\\s*,)\\s*\\(?\\s*[\"'\x60]([^\"'\x60\\s]+)[\"'\x60]")
+	apiHintRe  = regexp.MustCompile(`(?i)/(?:api|v\d+|graphql|rest|rpc|ajax|ws|auth|oauth|admin)(?:/|$)`)
+	staticExts = []string{".png", ".jpg", ".jpeg", ".gif", ".svg", ".ico", ".webp", ".bmp", ".css", ".woff", ".woff2", ".ttf", ".eot", ".otf", ".mp4", ".mp3", ".webm", ".pdf", ".zip"}
+)
+
+func Extract(rawURL string, header http.Header, body []byte) []Link {
+	base, err := url.Parse(rawURL)
+	if err != nil || len(body) == 0 {
+		return nil
+	}
+	mt, _, _ := mime.ParseMediaType(header.Get("Content-Type"))
+	if mt == "" {
+		mt = http.DetectContentType(body)
+		mt, _, _ = mime.ParseMediaType(mt)
+	}
+
+	c := collector{base: base, seen: map[string]int{}}
+	s := string(body)
+	switch {
+	case strings.Contains(mt, "html"):
+		c.html(s)
+		for _, m := range scriptRe.FindAllStringSubmatch(s, -1) {
+			c.js(m[1])
+		}
+	case strings.Contains(mt, "javascript") || strings.Contains(mt, "ecmascript"):
+		c.js(s)
+	case strings.Contains(mt, "json") || strings.Contains(mt, "xml") || strings.HasPrefix(mt, "text/"):
+	default:
+		return nil
+	}
+	for _, u := range absRe.FindAllString(s, -1) {
+		c.add(u, KindLink, "", nil)
+	}
+	return c.out
+}
+
+type collector struct {
+	base *url.URL
+	seen map[string]int
+	out  []Link
+}
+
+func (c *collector) add(ref string, kind Kind, method string, params []string) {
+	ref = strings.TrimSpace(ref)
+	if ref == "" || strings.HasPrefix(ref, "#") {
+		return
+	}
+	low := strings.ToLower(ref)
+	for _, p := range []string{"javascript:", "mailto:", "data:", "tel:", "about:", "blob:"} {
+		if strings.HasPrefix(low, p) {
+			return
+		}
+	}
+	u, err := c.base.Parse(ref)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return
+	}
+	u.Fragment = ""
+	key := string(kind) + " " + u.String()
+	if i, ok := c.seen[key]; ok {
+		c.out[i].Params = mergeParams(c.out[i].Params, params)
+		return
+	}
+	if method == "" {
+		method = http.MethodGet
+	}
+	c.seen[key] = len(c.out)
+	c.out = append(c.out, Link{URL: u.String(), Kind: kind, Method: method, Params: mergeParams(nil, params)})
+}
+
+func (c *collector) html(s string) {
+	for _, m := range tagRe.FindAllStringSubmatch(s, -1) {
+		tag := strings.ToLower(m[1])
+		attrs := parseAttrs(m[0])
+		kind := KindLink
+		if tag == "script" {
+			kind = KindScript
+		}
+		for _, a := range []string{"href", "src"} {
+			if v, ok := attrs[a]; ok {
+				c.add(v, kind, "", nil)
+			}
+		}
+	}
+	for _, m := range formRe.FindAllStringSubmatch(s, -1) {
+		attrs := parseAttrs("<form " + m[1] + ">")
+		action, ok := attrs["action"]
+		if !ok || strings.TrimSpace(action) == "" {
+			action = c.base.String()
+		}
+		var params []string
+		for _, in := range inputRe.FindAllString(m[2], -1) {
+			if name := parseAttrs(in)["name"]; name != "" {
+				params = append(params, name)
+			}
+		}
+		c.add(action, KindForm, strings.ToUpper(attrs["method"]), params)
+	}
+}
+
+func (c *collector) js(s string) {
+	for _, m := range jsCallRe.FindAllStringSubmatch(s, -1) {
+		c.add(m[1], KindAPI, "", nil)
+	}
+	for _, m := range jsPathRe.FindAllStringSubmatch(s, -1) {
+		p := m[1]
+		if len(p) < 2 || strings.HasPrefix(p, "//") || strings.ContainsAny(p, "{}") && !apiHintRe.MatchString(p) {
+			continue
+		}
+		if !apiHintRe.MatchString(p) && !strings.Contains(strings.TrimPrefix(p, "/"), "/") && len(p) < 4 {
+			continue
+		}
+		c.add(p, KindAPI, "", nil)
+	}
+}
+
+func parseAttrs(tag string) map[string]string {
+	out := map[string]string{}
+	for _, m := range attrRe.FindAllStringSubmatch(tag, -1) {
+		name := strings.ToLower(m[1])
+		if _, ok := out[name]; ok {
+			continue
+		}
+		out[name] = html.UnescapeString(m[2] + m[3] + m[4])
+	}
+	return out
+}
+
+func mergeParams(a, b []string) []string {
+	if len(b) == 0 {
+		return a
+	}
+	set := map[string]bool{}
+	for _, p := range append(append([]string{}, a...), b...) {
+		set[p] = true
+	}
+	out := make([]string, 0, len(set))
+	for p := range set {
+		out = append(out, p)
+	}
+	sort.Strings(out)
+	return out
+}
+
+func IsStatic(u *url.URL) bool {
+	p := strings.ToLower(u.Path)
+	for _, ext := range staticExts {
+		if strings.HasSuffix(p, ext) {
+			return true
+		}
+	}
+	return false
+}
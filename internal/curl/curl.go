@@ -0,0 +1,421 @@
+package curl
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"burpui/internal/httpraw"
+)
+
+type Request struct {
+	Raw        string
+	Insecure   bool
+	Compressed bool
+}
+
+func Format(r Request) (string, error) {
+	req, body, err := httpraw.ParseRequest(r.Raw)
+	if err != nil {
+		return "", err
+	}
+	args := []string{"curl " + Quote(req.URL.String())}
+	switch {
+	case req.Method == http.MethodHead && len(body) == 0:
+		args = append(args, "-I")
+	case req.Method == http.MethodGet && len(body) == 0:
+	case req.Method == http.MethodPost && len(body) > 0:
+	default:
+		args = append(args, "-X "+Quote(req.Method))
+	}
+	compressed := r.Compressed
+	for _, h := range rawHeaders(r.Raw) {
+		k, v, _ := strings.Cut(h, ":")
+		k, v = strings.TrimSpace(k), strings.TrimSpace(v)
+		switch {
+		case strings.EqualFold(k, "Content-Length"):
+			continue
+		case strings.EqualFold(k, "Host") && v == req.URL.Host:
+			continue
+		case strings.EqualFold(k, "Accept-Encoding"):
+			compressed = true
+		}
+		if v == "" {
+			args = append(args, "-H "+Quote(k+";"))
+			continue
+		}
+		args = append(args, "-H "+Quote(k+": "+v))
+	}
+	if len(body) > 0 {
+		if printable(string(body)) {
+			args = append(args, "--data-raw "+Quote(string(body)))
+		} else {
+			args = append(args, "--data-binary "+quoteANSI(body))
+		}
+	}
+	if compressed {
+		args = append(args, "--compressed")
+	}
+	if r.Insecure {
+		args = append(args, "-k")
+	}
+	return strings.Join(args, " \\\n  "), nil
+}
+
+func rawHeaders(raw string) []string {
+	raw = strings.ReplaceAll(raw, "\r\n", "\n")
+	head, _, _ := strings.Cut(strings.TrimLeft(raw, "\n"), "\n\n")
+	lines := strings.Split(head, "\n")
+	var out []string
+	for _, l := range lines[1:] {
+		if strings.Contains(l, ":") {
+			out = append(out, l)
+		}
+	}
+	return out
+}
+
+func Quote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+func printable(s string) bool {
+	if !utf8.ValidString(s) {
+		return false
+	}
+	for _, r := range s {
+		if r < 0x20 && r != '\n' && r != '\r' && r != '\t' || r == 0x7f {
+			return false
+		}
+	}
+	return true
+}
+
+func quoteANSI(b []byte) string {
+	var sb strings.Builder
+	sb.WriteString("$'")
+	for _, c := range b {
+		switch {
+		case c == '\'' || c == '\\':
+			sb.WriteByte('\\')
+			sb.WriteByte(c)
+		case c == '\n':
+			sb.WriteString(`\n`)
+		case c == '\r':
+			sb.WriteString(`\r`)
+		case c == '\t':
+			sb.WriteString(`\t`)
+		case c < 0x20 || c >= 0x7f:
+			fmt.Fprintf(&sb, `\x%02x`, c)
+		default:
+			sb.WriteByte(c)
+		}
+	}
+	sb.WriteString("'")
+	return sb.String()
+}
+
+var valueOpts = map[string]string{
+	"-X": "request", "--request": "request",
+	"-H": "header", "--header": "header",
+	"-d": "data", "--data": "data", "--data-ascii": "data",
+	"--data-binary": "data-binary", "--data-raw": "data-raw", "--data-urlencode": "data-urlencode",
+	"-u": "user", "--user": "user",
+	"-b": "cookie", "--cookie": "cookie",
+	"-A": "user-agent", "--user-agent": "user-agent",
+	"-e": "referer", "--referer": "referer",
+	"--url": "url",
+	"-o":    "ignore", "--output": "ignore",
+	"-m": "ignore", "--max-time": "ignore", "--connect-timeout": "ignore",
+	"-x": "ignore", "--proxy": "ignore",
+	"-w": "ignore", "--write-out": "ignore",
+	"--retry": "ignore",
+}
+
+var flagOpts = map[string]string{
+	"-k": "insecure", "--insecure": "insecure",
+	"--compressed": "compressed",
+	"-G":           "get", "--get": "get",
+	"-I": "head", "--head": "head",
+	"-s": "ignore", "--silent": "ignore",
+	"-S": "ignore", "--show-error": "ignore",
+	"-L": "ignore", "--location": "ignore",
+	"-v": "ignore", "--verbose": "ignore",
+	"-i": "ignore", "--include": "ignore",
+	"-g": "ignore", "--globoff": "ignore",
+	"-f": "ignore", "--fail": "ignore",
+	"-N": "ignore", "--no-buffer": "ignore",
+	"--http1.1": "ignore", "--http2": "ignore",
+}
+
+type parsed struct {
+	method   string
+	url      string
+	headers  [][2]string
+	data     []string
+	get      bool
+	head     bool
+	user     string
+	cookies  []string
+	insecure bool
+	compress bool
+}
+
+func Parse(cmd string) (Request, error) {
+	args, err := Split(cmd)
+	if err != nil {
+		return Request{}, err
+	}
+	if len(args) > 0 && (args[0] == "curl" || strings.HasSuffix(args[0], "/curl") || strings.EqualFold(args[0], "curl.exe")) {
+		args = args[1:]
+	}
+	if len(args) == 0 {
+		return Request{}, fmt.Errorf("curl: comando vazio")
+	}
+
+	var p parsed
+	for i := 0; i < len(args); i++ {
+		a := args[i]
+		if a == "" || a[0] != '-' || a == "-" {
+			if err := p.setURL(a); err != nil {
+				return Request{}, err
+			}
+			continue
+		}
+		if strings.HasPrefix(a, "--") {
+			if name, ok := flagOpts[a]; ok {
+				p.flag(name)
+				continue
+			}
+			name, ok := valueOpts[a]
+			if !ok {
+				return Request{}, fmt.Errorf("curl: opção não suportada: %s", a)
+			}
+			if i+1 >= len(args) {
+				return Request{}, fmt.Errorf("curl: %s sem valor", a)
+			}
+			i++
+			if err := p.value(name, args[i]); err != nil {
+				return Request{}, err
+			}
+			continue
+		}
+		for j := 1; j < len(a); j++ {
+			opt := "-" + a[j:j+1]
+			if name, ok := flagOpts[opt]; ok {
+				p.flag(name)
+				continue
+			}
+			name, ok := valueOpts[opt]
+			if !ok {
+				return Request{}, fmt.Errorf("curl: opção não suportada: %s", opt)
+			}
+			v := a[j+1:]
+			if v == "" {
+				if i+1 >= len(args) {
+					return Request{}, fmt.Errorf("curl: %s sem valor", opt)
+				}
+				i++
+				v = args[i]
+			}
+			if err := p.value(name, v); err != nil {
+				return Request{}, err
+			}
+			break
+		}
+	}
+	return p.build()
+}
+
+func (p *parsed) setURL(s string) error {
+	if p.url != "" {
+		return fmt.Errorf("curl: mais de uma URL (%s e %s)", p.url, s)
+	}
+	p.url = s
+	return nil
+}
+
+func (p *parsed) flag(name string) {
+	switch name {
+	case "insecure":
+		p.insecure = true
+	case "compressed":
+		p.compress = true
+	case "get":
+		p.get = true
+	case "head":
+		p.head = true
+	}
+}
+
+func (p *parsed) value(name, v string) error {
+	switch name {
+	case "request":
+		p.method = strings.ToUpper(v)
+	case "header":
+		k, val, ok := strings.Cut(v, ":")
+		if !ok {
+			if strings.HasSuffix(v, ";") {
+				p.headers = append(p.headers, [2]string{strings.TrimSuffix(v, ";"), ""})
+				return nil
+			}
+			return fmt.Errorf("curl: header inválido: %q", v)
+		}
+		k, val = strings.TrimSpace(k), strings.TrimSpace(val)
+		if val == "" {
+			p.removeHeader(k)
+			return nil
+		}
+		p.headers = append(p.headers, [2]string{k, val})
+	case "data", "data-binary":
+		if strings.HasPrefix(v, "@") {
+			b, err := os.ReadFile(v[1:])
+			if err != nil {
+				return fmt.Errorf("curl: %w", err)
+			}
+			v = string(b)
+			if name == "data" {
+				v = strings.NewReplacer("\r", "", "\n", "").Replace(v)
+			}
+		}
+		p.data = append(p.data, v)
+	case "data-raw":
+		p.data = append(p.data, v)
+	case "data-urlencode":
+		d, err := urlencodeData(v)
+		if err != nil {
+			return err
+		}
+		p.data = append(p.data, d)
+	case "user":
+		p.user = v
+	case "cookie":
+		if !strings.Contains(v, "=") {
+			return fmt.Errorf("curl: -b com arquivo de cookies não é suportado: %s", v)
+		}
+		p.cookies = append(p.cookies, v)
+	case "user-agent":
+		p.headers = append(p.headers, [2]string{"User-Agent", v})
+	case "referer":
+		p.headers = append(p.headers, [2]string{"Referer", v})
+	case "url":
+		return p.setURL(v)
+	}
+	return nil
+}
+
+func (p *parsed) removeHeader(name string) {
+	out := p.headers[:0]
+	for _, h := range p.headers {
+		if !strings.EqualFold(h[0], name) {
+			out = append(out, h)
+		}
+	}
+	p.headers = out
+}
+
+func (p *parsed) hasHeader(name string) bool {
+	for _, h := range p.headers {
+		if strings.EqualFold(h[0], name) {
+			return true
+		}
+	}
+	return false
+}
+
+func urlencodeData(v string) (string, error) {
+	name, content := "", v
+	if i := strings.IndexAny(v, "=@"); i >= 0 {
+		name, content = v[:i], v[i+1:]
+		if v[i] == '@' {
+			b, err := os.ReadFile(content)
+			if err != nil {
+				return "", fmt.Errorf("curl: %w", err)
+			}
+			content = string(b)
+		}
+	}
+	if name == "" {
+		return url.QueryEscape(content), nil
+	}
+	return name + "=" + url.QueryEscape(content), nil
+}
+
+func (p *parsed) build() (Request, error) {
+	if p.url == "" {
+		return Request{}, fmt.Errorf("curl: URL ausente")
+	}
+	target := p.url
+	if !strings.Contains(target, "://") {
+		target = "http://" + target
+	}
+	u, err := url.Parse(target)
+	if err != nil {
+		return Request{}, fmt.Errorf("curl: URL inválida: %w", err)
+	}
+	if u.Host == "" {
+		return Request{}, fmt.Errorf("curl: URL sem host: %s", p.url)
+	}
+	if u.Path == "" {
+		u.Path = "/"
+	}
+	u.Fragment = ""
+
+	body := strings.Join(p.data, "&")
+	method := http.MethodGet
+	switch {
+	case p.head:
+		method = http.MethodHead
+	case len(p.data) > 0 && !p.get:
+		method = http.MethodPost
+	}
+	if p.get && body != "" {
+		if u.RawQuery != "" {
+			u.RawQuery += "&"
+		}
+		u.RawQuery += body
+		body = ""
+	}
+	if p.method != "" {
+		method = p.method
+	}
+
+	if p.compress {
+		p.removeHeader("Accept-Encoding")
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s %s HTTP/1.1\r\n", method, u.String())
+	if !p.hasHeader("Host") {
+		fmt.Fprintf(&b, "Host: %s\r\n", u.Host)
+	}
+	if p.user != "" && !p.hasHeader("Authorization") {
+		user := p.user
+		if !strings.Contains(user, ":") {
+			user += ":"
+		}
+		fmt.Fprintf(&b, "Authorization: Basic %s\r\n", base64.StdEncoding.EncodeToString([]byte(user)))
+	}
+	if len(p.cookies) > 0 && !p.hasHeader("Cookie") {
+		fmt.Fprintf(&b, "Cookie: %s\r\n", strings.Join(p.cookies, "; "))
+	}
+	for _, h := range p.headers {
+		if strings.EqualFold(h[0], "Content-Length") {
+			continue
+		}
+		fmt.Fprintf(&b, "%s: %s\r\n", h[0], h[1])
+	}
+	if body != "" {
+		if !p.hasHeader("Content-Type") {
+			b.WriteString("Content-Type: application/x-www-form-urlencoded\r\n")
+		}
+		b.WriteString("Content-Length: " + strconv.Itoa(len(body)) + "\r\n")
+	}
+	b.WriteString("\r\n")
+	b.WriteString(body)
+	return Request{Raw: b.String(), Insecure: p.insecure, Compressed: p.compress}, nil
+}
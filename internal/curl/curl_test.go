@@ -0,0 +1,148 @@
+package curl
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"burpui/internal/httpraw"
+)
+
+func TestSplit(t *testing.T) {
+	cmd := "curl 'https://a.test/x?q=1' \\\n  -H \"X-Msg: say \\\"hi\\\" \\$HOME\" \\\r\n  --data-raw $'a\\nb\\x41\\'' -d it\\'s"
+	got, err := Split(cmd)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"curl", "https://a.test/x?q=1", "-H", `X-Msg: say "hi" $HOME`, "--data-raw", "a\nbA'", "-d", "it's"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("Split = %q", got)
+	}
+	for _, bad := range []string{"curl 'x", `curl "x`, "curl $'x"} {
+		if _, err := Split(bad); err == nil {
+			t.Fatalf("expected error for %q", bad)
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	r, err := Parse(`curl -sSL -XPUT 'https://api.test/v1/items?id=1' -H 'Content-Type: application/json' -H 'Accept-Encoding: gzip, br' -u alice:s3cret -b 'sid=1; csrf=2' -k --compressed --data-binary '{"a":"b'\''c"}'`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !r.Insecure || !r.Compressed {
+		t.Fatalf("flags = %+v", r)
+	}
+	req, body, err := httpraw.ParseRequest(r.Raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if req.Method != "PUT" || req.URL.String() != "https://api.test/v1/items?id=1" || string(body) != `{"a":"b'c"}` {
+		t.Fatalf("req = %s %s %q", req.Method, req.URL, body)
+	}
+	if req.Header.Get("Authorization") != "Basic YWxpY2U6czNjcmV0" || req.Header.Get("Cookie") != "sid=1; csrf=2" || req.Header.Get("Accept-Encoding") != "" {
+		t.Fatalf("headers = %v", req.Header)
+	}
+}
+
+func TestParseData(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "body.txt")
+	if err := os.WriteFile(file, []byte("x=1\ny=2\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	r, err := Parse("curl a.test/login -d user=bob --data-urlencode 'msg=olá mundo' --data-urlencode =raw&v -d @" + file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req, body, err := httpraw.ParseRequest(r.Raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if req.Method != "POST" || req.URL.String() != "http://a.test/login" || req.Header.Get("Content-Type") != "application/x-www-form-urlencoded" {
+		t.Fatalf("req = %s %s %v", req.Method, req.URL, req.Header)
+	}
+	if string(body) != "user=bob&msg=ol%C3%A1+mundo&raw%26v&x=1y=2" {
+		t.Fatalf("body = %q", body)
+	}
+
+	r, err = Parse("curl -G https://a.test/s?x=1 -d q=go -H 'User-Agent:' -A bot")
+	if err != nil {
+		t.Fatal(err)
+	}
+	req, body, _ = httpraw.ParseRequest(r.Raw)
+	if req.Method != "GET" || req.URL.RawQuery != "x=1&q=go" || len(body) != 0 || req.Header.Get("User-Agent") != "bot" {
+		t.Fatalf("req = %s %s %q %v", req.Method, req.URL, body, req.Header)
+	}
+
+	for _, bad := range []string{"curl", "curl -H", "curl --foo a.test", "curl a.test b.test", "curl -b cookies.txt a.test"} {
+		if _, err := Parse(bad); err == nil {
+			t.Fatalf("expected error for %q", bad)
+		}
+	}
+}
+
+func TestFormatRoundTrip(t *testing.T) {
+	raw := "POST https://a.test/api?x=1 HTTP/1.1\r\nHost: a.test\r\nX-Quote: it's\r\nAccept-Encoding: gzip\r\nContent-Type: application/json\r\nContent-Length: 13\r\n\r\n{\"k\":\"it's\"}\n"
+	cmd, err := Format(Request{Raw: raw, Insecure: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(cmd, "Host:") || strings.Contains(cmd, "Content-Length") || strings.Contains(cmd, "-X") {
+		t.Fatalf("unexpected command:\n%s", cmd)
+	}
+	if !strings.Contains(cmd, `-H 'X-Quote: it'\''s'`) || !strings.HasSuffix(cmd, "--compressed \\\n  -k") {
+		t.Fatalf("unexpected command:\n%s", cmd)
+	}
+	r, err := Parse(cmd)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req, body, err := httpraw.ParseRequest(r.Raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if req.Method != "POST" || req.Header.Get("X-Quote") != "it's" || string(body) != "{\"k\":\"it's\"}\n" || !r.Insecure {
+		t.Fatalf("round trip: %s %v %q", req.Method, req.Header, body)
+	}
+
+	bin, err := Format(Request{Raw: "PUT /b HTTP/1.1\nHost: a.test:8080\nContent-Length: 3\n\n\x00\xff'"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(bin, `--data-binary $'\x00\xff\''`) || !strings.Contains(bin, "-X 'PUT'") {
+		t.Fatalf("binary command:\n%s", bin)
+	}
+	r, err = Parse(bin)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, body, _ = httpraw.ParseRequest(r.Raw)
+	if string(body) != "\x00\xff'" {
+		t.Fatalf("binary body = %q", body)
+	}
+}
+
+func TestFormatShell(t *testing.T) {
+	sh, err := exec.LookPath("bash")
+	if err != nil {
+		t.Skip("bash ausente")
+	}
+	cmd, err := Format(Request{Raw: "POST http://a.test/ HTTP/1.1\r\nHost: a.test\r\nX-A: $HOME `id` \"q\" 'x'\r\nContent-Length: 9\r\n\r\nl1\nl2 'z'"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	script := "printf '%s\\0' " + strings.TrimPrefix(cmd, "curl ")
+	out, err := exec.Command(sh, "-c", script).Output()
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := strings.Split(strings.TrimSuffix(string(out), "\x00"), "\x00")
+	want, _ := Split(cmd)
+	if !reflect.DeepEqual(got, want[1:]) {
+		t.Fatalf("shell args %q, Split %q", got, want[1:])
+	}
+}
@@ -0,0 +1,142 @@
+package curl
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+func Split(cmd string) ([]string, error) {
+	var args []string
+	var cur strings.Builder
+	inArg := false
+	rs := []rune(cmd)
+	for i := 0; i < len(rs); i++ {
+		c := rs[i]
+		switch {
+		case c == '\\':
+			if i+1 < len(rs) && rs[i+1] == '\r' && i+2 < len(rs) && rs[i+2] == '\n' {
+				i += 2
+				continue
+			}
+			if i+1 < len(rs) && rs[i+1] == '\n' {
+				i++
+				continue
+			}
+			if i+1 < len(rs) {
+				i++
+				cur.WriteRune(rs[i])
+				inArg = true
+			}
+		case c == '\'':
+			end := indexRune(rs, i+1, '\'')
+			if end < 0 {
+				return nil, fmt.Errorf("curl: aspas simples sem fechar")
+			}
+			cur.WriteString(string(rs[i+1 : end]))
+			i = end
+			inArg = true
+		case c == '$' && i+1 < len(rs) && rs[i+1] == '\'':
+			s, end, err := ansiC(rs, i+2)
+			if err != nil {
+				return nil, err
+			}
+			cur.WriteString(s)
+			i = end
+			inArg = true
+		case c == '"':
+			j := i + 1
+			for ; j < len(rs) && rs[j] != '"'; j++ {
+				if rs[j] == '\\' && j+1 < len(rs) {
+					switch rs[j+1] {
+					case '"', '\\', '$', '`':
+						j++
+					case '\n':
+						j++
+						continue
+					}
+				}
+				cur.WriteRune(rs[j])
+			}
+			if j >= len(rs) {
+				return nil, fmt.Errorf("curl: aspas duplas sem fechar")
+			}
+			i = j
+			inArg = true
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			if inArg {
+				args = append(args, cur.String())
+				cur.Reset()
+				inArg = false
+			}
+		default:
+			cur.WriteRune(c)
+			inArg = true
+		}
+	}
+	if inArg {
+		args = append(args, cur.String())
+	}
+	return args, nil
+}
+
+func indexRune(rs []rune, from int, r rune) int {
+	for i := from; i < len(rs); i++ {
+		if rs[i] == r {
+			return i
+		}
+	}
+	return -1
+}
+
+func ansiC(rs []rune, from int) (string, int, error) {
+	var b []byte
+	for i := from; i < len(rs); i++ {
+		c := rs[i]
+		if c == '\'' {
+			return string(b), i, nil
+		}
+		if c != '\\' || i+1 >= len(rs) {
+			b = append(b, string(c)...)
+			continue
+		}
+		i++
+		switch rs[i] {
+		case 'n':
+			b = append(b, '\n')
+		case 'r':
+			b = append(b, '\r')
+		case 't':
+			b = append(b, '\t')
+		case '0':
+			b = append(b, 0)
+		case 'x':
+			j := i + 1
+			for j < len(rs) && j < i+3 && strings.ContainsRune("0123456789abcdefABCDEF", rs[j]) {
+				j++
+			}
+			if j == i+1 {
+				b = append(b, '\\', 'x')
+				continue
+			}
+			n, _ := strconv.ParseUint(string(rs[i+1:j]), 16, 8)
+			b = append(b, byte(n))
+			i = j - 1
+		case 'u':
+			j := i + 1
+			for j < len(rs) && j < i+5 && strings.ContainsRune("0123456789abcdefABCDEF", rs[j]) {
+				j++
+			}
+			if j == i+1 {
+				b = append(b, '\\', 'u')
+				continue
+			}
+			n, _ := strconv.ParseUint(string(rs[i+1:j]), 16, 32)
+			b = append(b, string(rune(n))...)
+			i = j - 1
+		default:
+			b = append(b, string(rs[i])...)
+		}
+	}
+	return "", 0, fmt.Errorf("curl: $'...' sem fechar")
+}
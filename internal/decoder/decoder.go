@@ -0,0 +1,243 @@
+package decoder
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"html"
+	"io"
+	"net/url"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"burpui/internal/jwt"
+)
+
+type Transform struct {
+	Name  string
+	Desc  string
+	Apply func([]byte) ([]byte, error)
+}
+
+type Step struct {
+	Name   string
+	Output []byte
+}
+
+var transforms = []Transform{
+	{"url-decode", "URL decode (%XX e +)", urlDecode},
+	{"url-encode", "URL encode", func(b []byte) ([]byte, error) { return []byte(url.QueryEscape(string(b))), nil }},
+	{"base64-decode", "Base64 decode", b64Decode(base64.StdEncoding, base64.RawStdEncoding)},
+	{"base64-encode", "Base64 encode", func(b []byte) ([]byte, error) { return []byte(base64.StdEncoding.EncodeToString(b)), nil }},
+	{"base64url-decode", "Base64URL decode", b64Decode(base64.URLEncoding, base64.RawURLEncoding)},
+	{"base64url-encode", "Base64URL encode (sem padding)", func(b []byte) ([]byte, error) { return []byte(base64.RawURLEncoding.EncodeToString(b)), nil }},
+	{"hex-decode", "hex decode", hexDecode},
+	{"hex-encode", "hex encode", func(b []byte) ([]byte, error) { return []byte(hex.EncodeToString(b)), nil }},
+	{"html-decode", "HTML entities decode", func(b []byte) ([]byte, error) { return []byte(html.UnescapeString(string(b))), nil }},
+	{"html-encode", "HTML entities encode", func(b []byte) ([]byte, error) { return []byte(html.EscapeString(string(b))), nil }},
+	{"unicode-decode", `escapes \uXXXX / \xXX decode`, unicodeDecode},
+	{"unicode-encode", `não-ASCII para \uXXXX`, unicodeEncode},
+	{"gunzip", "gzip decompress", gunzip},
+	{"gzip", "gzip compress", gzipCompress},
+	{"inflate", "deflate/zlib decompress", inflate},
+	{"deflate", "zlib compress", deflateCompress},
+	{"jwt", "JWT decode (header, payload, assinatura)", jwtDecode},
+	{"md5", "MD5 (hex)", hashHex(func(b []byte) []byte { s := md5.Sum(b); return s[:] })},
+	{"sha1", "SHA-1 (hex)", hashHex(func(b []byte) []byte { s := sha1.Sum(b); return s[:] })},
+	{"sha256", "SHA-256 (hex)", hashHex(func(b []byte) []byte { s := sha256.Sum256(b); return s[:] })},
+	{"sha512", "SHA-512 (hex)", hashHex(func(b []byte) []byte { s := sha512.Sum512(b); return s[:] })},
+}
+
+func Transforms() []Transform {
+	return append([]Transform(nil), transforms...)
+}
+
+func Lookup(name string) (Transform, bool) {
+	for _, t := range transforms {
+		if t.Name == name {
+			return t, true
+		}
+	}
+	return Transform{}, false
+}
+
+func Run(input []byte, chain []string) ([]Step, error) {
+	steps := make([]Step, 0, len(chain))
+	cur := input
+	for _, name := range chain {
+		t, ok := Lookup(name)
+		if !ok {
+			return steps, fmt.Errorf("decoder: transformação desconhecida: %s", name)
+		}
+		out, err := t.Apply(cur)
+		if err != nil {
+			return steps, fmt.Errorf("decoder: %s: %w", name, err)
+		}
+		steps = append(steps, Step{Name: name, Output: out})
+		cur = out
+	}
+	return steps, nil
+}
+
+func urlDecode(b []byte) ([]byte, error) {
+	s, err := url.QueryUnescape(string(b))
+	if err != nil {
+		s, err = url.PathUnescape(string(b))
+	}
+	return []byte(s), err
+}
+
+func b64Decode(padded, raw *base64.Encoding) func([]byte) ([]byte, error) {
+	return func(b []byte) ([]byte, error) {
+		s := strings.Join(strings.Fields(string(b)), "")
+		if out, err := padded.DecodeString(s); err == nil {
+			return out, nil
+		}
+		return raw.DecodeString(strings.TrimRight(s, "="))
+	}
+}
+
+func hexDecode(b []byte) ([]byte, error) {
+	s := strings.NewReplacer(" ", "", "\n", "", "\r", "", "\t", "", ":", "", "0x", "", `\x`, "").Replace(string(b))
+	return hex.DecodeString(s)
+}
+
+func unicodeDecode(b []byte) ([]byte, error) {
+	s := string(b)
+	var out strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i+1 >= len(s) {
+			out.WriteByte(s[i])
+			continue
+		}
+		n := 0
+		switch s[i+1] {
+		case 'u':
+			n = 4
+		case 'U':
+			n = 8
+		case 'x':
+			n = 2
+		}
+		if n == 0 || i+2+n > len(s) {
+			out.WriteByte(s[i])
+			continue
+		}
+		v, err := strconv.ParseUint(s[i+2:i+2+n], 16, 32)
+		if err != nil {
+			out.WriteByte(s[i])
+			continue
+		}
+		r := rune(v)
+		if n == 4 && r >= 0xd800 && r < 0xdc00 && i+12 <= len(s) && s[i+6] == '\\' && s[i+7] == 'u' {
+			if lo, err := strconv.ParseUint(s[i+8:i+12], 16, 32); err == nil && lo >= 0xdc00 && lo < 0xe000 {
+				r = (r-0xd800)<<10 + (rune(lo) - 0xdc00) + 0x10000
+				i += 6
+			}
+		}
+		if n == 2 {
+			out.WriteByte(byte(v))
+		} else {
+			out.WriteRune(r)
+		}
+		i += 1 + n
+	}
+	return []byte(out.String()), nil
+}
+
+func unicodeEncode(b []byte) ([]byte, error) {
+	var out strings.Builder
+	for _, r := range string(b) {
+		switch {
+		case r < 0x80:
+			out.WriteRune(r)
+		case r > 0xffff:
+			r -= 0x10000
+			fmt.Fprintf(&out, `\u%04x\u%04x`, 0xd800+(r>>10), 0xdc00+(r&0x3ff))
+		default:
+			fmt.Fprintf(&out, `\u%04x`, r)
+		}
+	}
+	return []byte(out.String()), nil
+}
+
+func gunzip(b []byte) ([]byte, error) {
+	r, err := gzip.NewReader(bytes.NewReader(b))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return io.ReadAll(r)
+}
+
+func gzipCompress(b []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	if _, err := w.Write(b); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func inflate(b []byte) ([]byte, error) {
+	if r, err := zlib.NewReader(bytes.NewReader(b)); err == nil {
+		defer r.Close()
+		if out, err := io.ReadAll(r); err == nil {
+			return out, nil
+		}
+	}
+	r := flate.NewReader(bytes.NewReader(b))
+	defer r.Close()
+	return io.ReadAll(r)
+}
+
+func deflateCompress(b []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	w := zlib.NewWriter(&buf)
+	if _, err := w.Write(b); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func jwtDecode(b []byte) ([]byte, error) {
+	t, err := jwt.Parse(string(b))
+	if err != nil {
+		return nil, err
+	}
+	return []byte(t.Pretty()), nil
+}
+
+func hashHex(sum func([]byte) []byte) func([]byte) ([]byte, error) {
+	return func(b []byte) ([]byte, error) {
+		return []byte(hex.EncodeToString(sum(b))), nil
+	}
+}
+
+func Printable(b []byte) bool {
+	if !utf8.Valid(b) {
+		return false
+	}
+	bad := 0
+	for _, r := range string(b) {
+		if r < 0x20 && r != '\n' && r != '\r' && r != '\t' {
+			bad++
+		}
+	}
+	return bad*20 <= len(b)
+}
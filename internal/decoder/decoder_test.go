@@ -0,0 +1,83 @@
+package decoder
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+)
+
+func TestRun_RoundTrips(t *testing.T) {
+	input := []byte("olá <b>mundo</b> & \"aspas\" 😀 ?a=1&b=2")
+	pairs := [][2]string{
+		{"url-encode", "url-decode"},
+		{"base64-encode", "base64-decode"},
+		{"base64url-encode", "base64url-decode"},
+		{"hex-encode", "hex-decode"},
+		{"html-encode", "html-decode"},
+		{"unicode-encode", "unicode-decode"},
+		{"gzip", "gunzip"},
+		{"deflate", "inflate"},
+	}
+	for _, p := range pairs {
+		steps, err := Run(input, []string{p[0], p[1]})
+		if err != nil {
+			t.Fatalf("%v: %v", p, err)
+		}
+		if got := string(steps[1].Output); got != string(input) {
+			t.Fatalf("%v: got %q", p, got)
+		}
+	}
+}
+
+func TestRun_HashesAndErrors(t *testing.T) {
+	steps, err := Run([]byte("abc"), []string{"md5", "sha1", "sha256"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(steps[0].Output) != "900150983cd24fb0d6963f7d28e17f72" {
+		t.Fatalf("md5 = %s", steps[0].Output)
+	}
+	if len(steps[2].Output) != 64 {
+		t.Fatalf("sha256 length %d", len(steps[2].Output))
+	}
+	if _, err := Run([]byte("zz"), []string{"hex-decode"}); err == nil {
+		t.Fatalf("expected hex error")
+	}
+	if steps, err := Run([]byte("x"), []string{"base64-encode", "nope"}); err == nil || len(steps) != 1 {
+		t.Fatalf("expected unknown transform error after one step, got %d %v", len(steps), err)
+	}
+}
+
+func TestUnicodeDecode(t *testing.T) {
+	out, _ := unicodeDecode([]byte(`a\x62 😀 \n \uZZZZ`))
+	if string(out) != `ab 😀 \n \uZZZZ` {
+		t.Fatalf("got %q", out)
+	}
+}
+
+func TestDetect(t *testing.T) {
+	gz, _ := gzipCompress([]byte(`{"session":"abc"}`))
+	cases := []struct {
+		in   string
+		want string
+	}{
+		{base64.StdEncoding.EncodeToString([]byte("user:secret-password")), "base64-decode"},
+		{"%7B%22a%22%3A1%7D", "url-decode"},
+		{base64.StdEncoding.EncodeToString(gz), "base64-decode,gunzip"},
+		{"%3D" + "&lt;script&gt;", "url-decode,html-decode"},
+		{"68656c6c6f20776f726c64", "hex-decode"},
+		{"5d41402abc4b2a76b9719d911017c592", ""},
+		{"plain text here", ""},
+		{"eyJhbGciOiJIUzI1NiJ9.eyJzdWIiOiIxIn0.c2ln", "jwt"},
+		{base64.RawURLEncoding.EncodeToString([]byte("a?b>c~~~~~")), "base64url-decode"},
+	}
+	for _, c := range cases {
+		if got := strings.Join(Detect([]byte(c.in)), ","); got != c.want {
+			t.Fatalf("Detect(%q) = %q, want %q", c.in, got, c.want)
+		}
+	}
+	chain, steps, err := Auto([]byte(base64.StdEncoding.EncodeToString(gz)))
+	if err != nil || len(chain) != 2 || string(steps[1].Output) != `{"session":"abc"}` {
+		t.Fatalf("Auto = %v %v", chain, err)
+	}
+}
@@ -0,0 +1,99 @@
+package decoder
+
+import (
+	"bytes"
+	"encoding/base64"
+	"regexp"
+	"strings"
+
+	"burpui/internal/jwt"
+)
+
+const maxAutoSteps = 8
+
+var (
+	pctRe       = regexp.MustCompile(`%[0-9a-fA-F]{2}`)
+	escRe       = regexp.MustCompile(`\\(?:u[0-9a-fA-F]{4}|x[0-9a-fA-F]{2})`)
+	entityRe    = regexp.MustCompile(`&(?:#[0-9]+|#[xX][0-9a-fA-F]+|[a-zA-Z]+);`)
+	hexRe       = regexp.MustCompile(`^(?:[0-9a-fA-F]{2})+$`)
+	b64Re       = regexp.MustCompile(`^[A-Za-z0-9+/]+={0,2}$`)
+	b64URLRe    = regexp.MustCompile(`^[A-Za-z0-9_-]+={0,2}$`)
+	hashLengths = map[int]bool{32: true, 40: true, 64: true, 128: true}
+)
+
+func Detect(input []byte) []string {
+	var chain []string
+	cur := input
+	for len(chain) < maxAutoSteps {
+		name := detectOne(cur)
+		if name == "" {
+			break
+		}
+		t, _ := Lookup(name)
+		out, err := t.Apply(cur)
+		if err != nil || bytes.Equal(out, cur) {
+			break
+		}
+		chain = append(chain, name)
+		if name == "jwt" {
+			break
+		}
+		cur = out
+	}
+	return chain
+}
+
+func Auto(input []byte) ([]string, []Step, error) {
+	chain := Detect(input)
+	steps, err := Run(input, chain)
+	return chain, steps, err
+}
+
+func detectOne(b []byte) string {
+	if len(b) >= 2 && b[0] == 0x1f && b[1] == 0x8b {
+		return "gunzip"
+	}
+	if len(b) >= 2 && b[0] == 0x78 && (b[1] == 0x01 || b[1] == 0x5e || b[1] == 0x9c || b[1] == 0xda) {
+		return "inflate"
+	}
+	if !Printable(b) {
+		return ""
+	}
+	s := strings.TrimSpace(string(b))
+	switch {
+	case jwt.Looks(s):
+		return "jwt"
+	case pctRe.MatchString(s):
+		return "url-decode"
+	case escRe.MatchString(s):
+		return "unicode-decode"
+	case entityRe.MatchString(s):
+		return "html-decode"
+	}
+	if len(s) < 8 {
+		return ""
+	}
+	if hexRe.MatchString(s) && !hashLengths[len(s)] {
+		if out, err := hexDecode([]byte(s)); err == nil && plausible(out) {
+			return "hex-decode"
+		}
+	}
+	if b64Re.MatchString(s) {
+		if out, err := b64Decode(base64.StdEncoding, base64.RawStdEncoding)([]byte(s)); err == nil && plausible(out) {
+			return "base64-decode"
+		}
+	}
+	if b64URLRe.MatchString(s) {
+		if out, err := b64Decode(base64.URLEncoding, base64.RawURLEncoding)([]byte(s)); err == nil && plausible(out) {
+			return "base64url-decode"
+		}
+	}
+	return ""
+}
+
+func plausible(b []byte) bool {
+	if len(b) >= 2 && (b[0] == 0x1f && b[1] == 0x8b || b[0] == 0x78 && (b[1] == 0x01 || b[1] == 0x5e || b[1] == 0x9c || b[1] == 0xda)) {
+		return true
+	}
+	return len(b) > 0 && Printable(b)
+}
@@ -0,0 +1,395 @@
+package filter
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"burpui/internal/jwt"
+	"burpui/internal/proxy"
+)
+
+type Filter struct {
+	Expr  string
+	terms []term
+}
+
+type term struct {
+	neg   bool
+	match func(*proxy.Flow) bool
+}
+
+func Parse(expr string) (*Filter, error) {
+	tokens, err := tokenize(expr)
+	if err != nil {
+		return nil, err
+	}
+	f := &Filter{Expr: strings.TrimSpace(expr)}
+	for _, tok := range tokens {
+		t, err := parseTerm(tok)
+		if err != nil {
+			return nil, err
+		}
+		f.terms = append(f.terms, t)
+	}
+	return f, nil
+}
+
+func (f *Filter) Empty() bool {
+	return f == nil || len(f.terms) == 0
+}
+
+func (f *Filter) Match(fl *proxy.Flow) bool {
+	if f == nil || fl == nil {
+		return true
+	}
+	for _, t := range f.terms {
+		if t.match(fl) == t.neg {
+			return false
+		}
+	}
+	return true
+}
+
+func tokenize(expr string) ([]string, error) {
+	var out []string
+	var cur strings.Builder
+	inQuote, has := false, false
+	rs := []rune(expr)
+	for i := 0; i < len(rs); i++ {
+		r := rs[i]
+		switch {
+		case inQuote && r == '\\' && i+1 < len(rs):
+			i++
+			cur.WriteRune(rs[i])
+		case r == '"':
+			inQuote = !inQuote
+			has = true
+		case !inQuote && (r == ' ' || r == '\t' || r == '\n'):
+			if has {
+				out = append(out, cur.String())
+				cur.Reset()
+				has = false
+			}
+		default:
+			cur.WriteRune(r)
+			has = true
+		}
+	}
+	if inQuote {
+		return nil, fmt.Errorf("filtro: aspas sem fechar")
+	}
+	if has {
+		out = append(out, cur.String())
+	}
+	return out, nil
+}
+
+func parseTerm(tok string) (term, error) {
+	t := term{}
+	if strings.HasPrefix(tok, "-") && len(tok) > 1 {
+		t.neg = true
+		tok = tok[1:]
+	}
+	k, v, ok := strings.Cut(tok, ":")
+	if !ok || !knownKey(k) {
+		needle := strings.ToLower(tok)
+		t.match = func(f *proxy.Flow) bool {
+			return strings.Contains(strings.ToLower(f.URL), needle) || strings.Contains(strings.ToLower(f.Host), needle)
+		}
+		return t, nil
+	}
+	k = strings.ToLower(k)
+	if v == "" {
+		return t, fmt.Errorf("filtro: %s: valor vazio", k)
+	}
+
+	var err error
+	switch k {
+	case "host":
+		t.match = anyOf(v, func(alt string) func(*proxy.Flow) bool {
+			alt = strings.ToLower(alt)
+			return func(f *proxy.Flow) bool { return matchText(alt, strings.ToLower(flowHost(f))) }
+		})
+	case "method":
+		t.match = anyOf(v, func(alt string) func(*proxy.Flow) bool {
+			return func(f *proxy.Flow) bool { return strings.EqualFold(f.Method, alt) }
+		})
+	case "url":
+		t.match = textField(v, func(f *proxy.Flow) string { return f.URL })
+	case "path":
+		t.match = textField(v, func(f *proxy.Flow) string { return flowPath(f) })
+	case "ext":
+		t.match = anyOf(v, func(alt string) func(*proxy.Flow) bool {
+			alt = strings.TrimPrefix(strings.ToLower(alt), ".")
+			return func(f *proxy.Flow) bool {
+				return strings.TrimPrefix(strings.ToLower(path.Ext(flowPath(f))), ".") == alt
+			}
+		})
+	case "body":
+		t.match = textField(v, func(f *proxy.Flow) string { return string(f.RequestBody) + "\n" + string(f.ResponseBody) })
+	case "req":
+		t.match = textField(v, func(f *proxy.Flow) string { return string(f.RequestBody) })
+	case "resp":
+		t.match = textField(v, func(f *proxy.Flow) string { return string(f.ResponseBody) })
+	case "header":
+		t.match = textField(v, func(f *proxy.Flow) string { return headerText(f.RequestHeader) + headerText(f.ResponseHeader) })
+	case "type":
+		t.match = textField(v, func(f *proxy.Flow) string { return f.ResponseHeader.Get("Content-Type") })
+	case "error", "err":
+		t.match = textField(v, func(f *proxy.Flow) string { return f.Error })
+	case "status":
+		t.match, err = numField(v, parseStatus, func(f *proxy.Flow) float64 { return float64(f.StatusCode) })
+	case "dur":
+		t.match, err = numField(v, parseDur, func(f *proxy.Flow) float64 { return float64(f.Duration) })
+	case "size":
+		t.match, err = numField(v, parseSize, func(f *proxy.Flow) float64 { return float64(len(f.ResponseBody)) })
+	case "id":
+		t.match, err = numField(v, parseInt, func(f *proxy.Flow) float64 { return float64(f.ID) })
+	case "is":
+		t.match, err = isFlag(v)
+	}
+	if err != nil {
+		return t, fmt.Errorf("filtro: %s: %w", k, err)
+	}
+	return t, nil
+}
+
+var keys = []string{"host", "method", "url", "path", "ext", "body", "req", "resp", "header", "type", "error", "err", "status", "dur", "size", "id", "is"}
+
+func knownKey(k string) bool {
+	k = strings.ToLower(k)
+	for _, known := range keys {
+		if k == known {
+			return true
+		}
+	}
+	return false
+}
+
+func anyOf(v string, build func(alt string) func(*proxy.Flow) bool) func(*proxy.Flow) bool {
+	var fns []func(*proxy.Flow) bool
+	for _, alt := range strings.Split(v, "|") {
+		if alt = strings.TrimSpace(alt); alt != "" {
+			fns = append(fns, build(alt))
+		}
+	}
+	return func(f *proxy.Flow) bool {
+		for _, fn := range fns {
+			if fn(f) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+func textField(v string, get func(*proxy.Flow) string) func(*proxy.Flow) bool {
+	return anyOf(v, func(alt string) func(*proxy.Flow) bool {
+		alt = strings.ToLower(alt)
+		return func(f *proxy.Flow) bool { return matchText(alt, strings.ToLower(get(f))) }
+	})
+}
+
+func matchText(pattern, s string) bool {
+	if !strings.Contains(pattern, "*") {
+		return strings.Contains(s, pattern)
+	}
+	return proxy.GlobMatch(pattern, s)
+}
+
+func numField(v string, parse func(string) (float64, float64, error), get func(*proxy.Flow) float64) (func(*proxy.Flow) bool, error) {
+	var fns []func(float64) bool
+	for _, alt := range strings.Split(v, "|") {
+		fn, err := numCond(strings.TrimSpace(alt), parse)
+		if err != nil {
+			return nil, err
+		}
+		fns = append(fns, fn)
+	}
+	return func(f *proxy.Flow) bool {
+		x := get(f)
+		for _, fn := range fns {
+			if fn(x) {
+				return true
+			}
+		}
+		return false
+	}, nil
+}
+
+func numCond(v string, parse func(string) (float64, float64, error)) (func(float64) bool, error) {
+	for _, op := range []string{">=", "<=", "!=", ">", "<", "="} {
+		if !strings.HasPrefix(v, op) {
+			continue
+		}
+		n, _, err := parse(strings.TrimPrefix(v, op))
+		if err != nil {
+			return nil, err
+		}
+		switch op {
+		case ">=":
+			return func(x float64) bool { return x >= n }, nil
+		case "<=":
+			return func(x float64) bool { return x <= n }, nil
+		case "!=":
+			return func(x float64) bool { return x != n }, nil
+		case ">":
+			return func(x float64) bool { return x > n }, nil
+		case "<":
+			return func(x float64) bool { return x < n }, nil
+		}
+		return func(x float64) bool { return x == n }, nil
+	}
+	if lo, hi, ok := strings.Cut(v, ".."); ok {
+		a, _, err := parse(lo)
+		if err != nil {
+			return nil, err
+		}
+		_, b, err := parse(hi)
+		if err != nil {
+			return nil, err
+		}
+		return func(x float64) bool { return x >= a && x <= b }, nil
+	}
+	lo, hi, err := parse(v)
+	if err != nil {
+		return nil, err
+	}
+	return func(x float64) bool { return x >= lo && x <= hi }, nil
+}
+
+func parseStatus(s string) (float64, float64, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if len(s) == 3 && strings.HasSuffix(s, "xx") && s[0] >= '1' && s[0] <= '5' {
+		n := float64(s[0]-'0') * 100
+		return n, n + 99, nil
+	}
+	return parseInt(s)
+}
+
+func parseInt(s string) (float64, float64, error) {
+	n, err := strconv.ParseInt(strings.TrimSpace(s), 10, 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("número inválido: %q", s)
+	}
+	return float64(n), float64(n), nil
+}
+
+func parseDur(s string) (float64, float64, error) {
+	s = strings.TrimSpace(s)
+	if n, err := strconv.ParseFloat(s, 64); err == nil {
+		d := float64(time.Duration(n * float64(time.Millisecond)))
+		return d, d, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, 0, fmt.Errorf("duração inválida: %q", s)
+	}
+	return float64(d), float64(d), nil
+}
+
+func parseSize(s string) (float64, float64, error) {
+	s = strings.ToLower(strings.TrimSuffix(strings.ToLower(strings.TrimSpace(s)), "b"))
+	mult := 1.0
+	switch {
+	case strings.HasSuffix(s, "k"):
+		mult, s = 1<<10, strings.TrimSuffix(s, "k")
+	case strings.HasSuffix(s, "m"):
+		mult, s = 1<<20, strings.TrimSuffix(s, "m")
+	}
+	n, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("tamanho inválido: %q", s)
+	}
+	return n * mult, n * mult, nil
+}
+
+func isFlag(v string) (func(*proxy.Flow) bool, error) {
+	var fns []func(*proxy.Flow) bool
+	for _, alt := range strings.Split(strings.ToLower(v), "|") {
+		var fn func(*proxy.Flow) bool
+		switch alt {
+		case "pending":
+			fn = func(f *proxy.Flow) bool { return f.Pending }
+		case "error":
+			fn = func(f *proxy.Flow) bool { return f.Error != "" }
+		case "intercepted":
+			fn = func(f *proxy.Flow) bool { return f.Intercepted }
+		case "mock":
+			fn = func(f *proxy.Flow) bool { return f.MockID != 0 }
+		case "mapped":
+			fn = func(f *proxy.Flow) bool { return f.MappedTo != "" }
+		case "throttled":
+			fn = func(f *proxy.Flow) bool { return f.Throttle != "" }
+		case "tunnel":
+			fn = func(f *proxy.Flow) bool { return f.Tunnel }
+		case "connect":
+			fn = func(f *proxy.Flow) bool { return f.Method == http.MethodConnect }
+		case "truncated":
+			fn = func(f *proxy.Flow) bool { return f.ReqTruncated || f.RespTruncated }
+		case "jwt":
+			fn = hasJWT
+		default:
+			return nil, fmt.Errorf("flag desconhecida: %q", alt)
+		}
+		fns = append(fns, fn)
+	}
+	return func(f *proxy.Flow) bool {
+		for _, fn := range fns {
+			if fn(f) {
+				return true
+			}
+		}
+		return false
+	}, nil
+}
+
+func hasJWT(f *proxy.Flow) bool {
+	if len(jwt.Find(f.URL)) > 0 || len(jwt.Find(string(f.RequestBody))) > 0 || len(jwt.Find(string(f.ResponseBody))) > 0 {
+		return true
+	}
+	for _, h := range []http.Header{f.RequestHeader, f.ResponseHeader} {
+		for _, vv := range h {
+			for _, v := range vv {
+				if len(jwt.Find(v)) > 0 {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+func flowHost(f *proxy.Flow) string {
+	h := f.Host
+	if h == "" {
+		if u, err := url.Parse(f.URL); err == nil {
+			h = u.Host
+		}
+	}
+	if host, _, err := net.SplitHostPort(h); err == nil {
+		return host
+	}
+	return h
+}
+
+func flowPath(f *proxy.Flow) string {
+	u, err := url.Parse(f.URL)
+	if err != nil {
+		return f.URL
+	}
+	return u.Path
+}
+
+func headerText(h http.Header) string {
+	var b bytes.Buffer
+	_ = h.Write(&b)
+	return b.String()
+}
@@ -0,0 +1,113 @@
+package filter
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+type Saved struct {
+	Name string `json:"name"`
+	Expr string `json:"expr"`
+}
+
+func LoadSaved(path string) ([]Saved, error) {
+	if path == "" {
+		return nil, nil
+	}
+	b, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var out []Saved
+	if err := json.Unmarshal(b, &out); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return out, nil
+}
+
+func StoreSaved(path string, saved []Saved) error {
+	if path == "" {
+		return fmt.Errorf("filtros: arquivo não configurado")
+	}
+	sort.Slice(saved, func(i, j int) bool { return saved[i].Name < saved[j].Name })
+	b, err := json.MarshalIndent(saved, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(b, '\n'), 0o644)
+}
+
+func Put(saved []Saved, name, expr string) ([]Saved, error) {
+	name = strings.TrimPrefix(strings.TrimSpace(name), "@")
+	if name == "" || strings.ContainsAny(name, " \t:") {
+		return saved, fmt.Errorf("filtros: nome inválido: %q", name)
+	}
+	if _, err := Parse(expr); err != nil {
+		return saved, err
+	}
+	for i := range saved {
+		if saved[i].Name == name {
+			saved[i].Expr = expr
+			return saved, nil
+		}
+	}
+	return append(saved, Saved{Name: name, Expr: expr}), nil
+}
+
+func Expand(expr string, saved []Saved) (string, error) {
+	var b strings.Builder
+	inQuote := false
+	for i := 0; i < len(expr); {
+		c := expr[i]
+		if inQuote && c == '\\' && i+1 < len(expr) {
+			b.WriteString(expr[i : i+2])
+			i += 2
+			continue
+		}
+		if c == '"' {
+			inQuote = !inQuote
+		}
+		ref := c == '@' || c == '-' && i+1 < len(expr) && expr[i+1] == '@'
+		if inQuote || !ref || i > 0 && !isSpace(expr[i-1]) {
+			b.WriteByte(c)
+			i++
+			continue
+		}
+		end := i
+		for end < len(expr) && !isSpace(expr[end]) {
+			end++
+		}
+		tok := expr[i:end]
+		name := strings.TrimPrefix(strings.TrimPrefix(tok, "-"), "@")
+		found := false
+		for _, s := range saved {
+			if s.Name == name {
+				if c == '-' {
+					return "", fmt.Errorf("filtros: @%s não pode ser negado", name)
+				}
+				b.WriteString(s.Expr)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return "", fmt.Errorf("filtros: filtro salvo desconhecido: @%s", name)
+		}
+		i = end
+	}
+	return b.String(), nil
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+}
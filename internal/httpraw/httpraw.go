@@ -3,6 +3,10 @@ package httpraw
 import (
 	"bufio"
 	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -10,6 +14,8 @@ import (
 	"strings"
 )
 
+var ErrUnsupportedEncoding = errors.New("Content-Encoding não suportado")
+
 func ParseRequest(raw string) (*http.Request, []byte, error) {
 	raw = strings.ReplaceAll(raw, "\r\n", "\n")
 	raw = strings.TrimSpace(raw) + "\n\n"
@@ -43,3 +49,104 @@ func ParseRequest(raw string) (*http.Request, []byte, error) {
 
 	return req, bodyBytes, nil
 }
+
+func splitHead(raw string) (head, body, sep string, hasBody bool) {
+	if h, b, ok := strings.Cut(raw, "\r\n\r\n"); ok {
+		return h, b, "\r\n", true
+	}
+	if h, b, ok := strings.Cut(raw, "\n\n"); ok {
+		return h, b, "\n", true
+	}
+	sep = "\n"
+	if strings.Contains(raw, "\r\n") {
+		sep = "\r\n"
+	}
+	return strings.TrimRight(raw, "\r\n"), "", sep, false
+}
+
+func HeaderValue(raw, name string) (string, bool) {
+	head, _, sep, _ := splitHead(raw)
+	lines := strings.Split(head, sep)
+	for _, l := range lines[1:] {
+		k, v, ok := strings.Cut(l, ":")
+		if ok && strings.EqualFold(strings.TrimSpace(k), name) {
+			return strings.TrimSpace(v), true
+		}
+	}
+	return "", false
+}
+
+func SetHeader(raw, name, value string) string {
+	head, body, sep, _ := splitHead(raw)
+	lines := strings.Split(head, sep)
+	out := []string{lines[0]}
+	set := false
+	for _, l := range lines[1:] {
+		k, _, ok := strings.Cut(l, ":")
+		if ok && strings.EqualFold(strings.TrimSpace(k), name) {
+			if !set {
+				out = append(out, name+": "+value)
+				set = true
+			}
+			continue
+		}
+		out = append(out, l)
+	}
+	if !set {
+		out = append(out, name+": "+value)
+	}
+	return strings.Join(out, sep) + sep + sep + body
+}
+
+func DelHeader(raw, name string) string {
+	if _, ok := HeaderValue(raw, name); !ok {
+		return raw
+	}
+	head, body, sep, _ := splitHead(raw)
+	lines := strings.Split(head, sep)
+	out := []string{lines[0]}
+	for _, l := range lines[1:] {
+		k, _, ok := strings.Cut(l, ":")
+		if ok && strings.EqualFold(strings.TrimSpace(k), name) {
+			continue
+		}
+		out = append(out, l)
+	}
+	return strings.Join(out, sep) + sep + sep + body
+}
+
+func DecodeBody(h http.Header, body []byte) ([]byte, error) {
+	var encs []string
+	for _, v := range h.Values("Content-Encoding") {
+		for _, e := range strings.Split(v, ",") {
+			if e = strings.ToLower(strings.TrimSpace(e)); e != "" && e != "identity" {
+				encs = append(encs, e)
+			}
+		}
+	}
+	for i := len(encs) - 1; i >= 0; i-- {
+		var r io.Reader
+		switch encs[i] {
+		case "gzip", "x-gzip":
+			zr, err := gzip.NewReader(bytes.NewReader(body))
+			if err != nil {
+				return nil, err
+			}
+			r = zr
+		case "deflate":
+			if zr, err := zlib.NewReader(bytes.NewReader(body)); err == nil {
+				r = zr
+			} else {
+				r = flate.NewReader(bytes.NewReader(body))
+			}
+		default:
+			return nil, fmt.Errorf("%w: %s", ErrUnsupportedEncoding, encs[i])
+		}
+		out, err := io.ReadAll(r)
+		if err != nil {
+			return nil, err
+		}
+		body = out
+	}
+	return body, nil
+}
@@ -1,6 +1,13 @@
 package httpraw
 
-import "testing"
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"errors"
+	"net/http"
+	"testing"
+)
 
 func TestParseRequest_PathOnly(t *testing.T) {
 	raw := "GET /foo HTTP/1.1\r\nHost: example.com\r\nUser-Agent: x\r\n\r\n"
@@ -38,3 +45,56 @@ func TestParseRequest_AbsoluteURL(t *testing.T) {
 		t.Fatalf("expected path /foo, got %q", req.URL.Path)
 	}
 }
+
+func TestSetHeader(t *testing.T) {
+	raw := "POST /x HTTP/1.1\r\nHost: a.test\r\ncookie: a=1\r\nCookie: b=2\r\n\r\nbody"
+	out := SetHeader(raw, "Cookie", "c=3")
+	if out != "POST /x HTTP/1.1\r\nHost: a.test\r\nCookie: c=3\r\n\r\nbody" {
+		t.Fatalf("unexpected raw %q", out)
+	}
+	if v, ok := HeaderValue(out, "cookie"); !ok || v != "c=3" {
+		t.Fatalf("HeaderValue = %q %v", v, ok)
+	}
+	out = SetHeader("GET / HTTP/1.1\nHost: a.test\n", "X-Test", "1")
+	if out != "GET / HTTP/1.1\nHost: a.test\nX-Test: 1\n\n" {
+		t.Fatalf("unexpected raw %q", out)
+	}
+	if _, ok := HeaderValue(out, "Cookie"); ok {
+		t.Fatalf("missing header reported as present")
+	}
+}
+
+func TestDelHeader(t *testing.T) {
+	raw := "GET /x HTTP/1.1\r\nHost: a.test\r\naccept-encoding: gzip, br\r\nAccept: */*\r\n\r\n"
+	if out := DelHeader(raw, "Accept-Encoding"); out != "GET /x HTTP/1.1\r\nHost: a.test\r\nAccept: */*\r\n\r\n" {
+		t.Fatalf("unexpected raw %q", out)
+	}
+	if out := DelHeader("GET / HTTP/1.1\nHost: a.test", "Cookie"); out != "GET / HTTP/1.1\nHost: a.test" {
+		t.Fatalf("raw without header changed: %q", out)
+	}
+}
+
+func TestDecodeBody(t *testing.T) {
+	var gz bytes.Buffer
+	w := gzip.NewWriter(&gz)
+	w.Write([]byte("<a href=/x>"))
+	w.Close()
+	out, err := DecodeBody(http.Header{"Content-Encoding": {"gzip"}}, gz.Bytes())
+	if err != nil || string(out) != "<a href=/x>" {
+		t.Fatalf("gzip = %q %v", out, err)
+	}
+
+	var zl bytes.Buffer
+	zw := zlib.NewWriter(&zl)
+	zw.Write([]byte("plain"))
+	zw.Close()
+	if out, err := DecodeBody(http.Header{"Content-Encoding": {"deflate"}}, zl.Bytes()); err != nil || string(out) != "plain" {
+		t.Fatalf("deflate = %q %v", out, err)
+	}
+	if out, err := DecodeBody(http.Header{}, []byte("raw")); err != nil || string(out) != "raw" {
+		t.Fatalf("identity = %q %v", out, err)
+	}
+	if _, err := DecodeBody(http.Header{"Content-Encoding": {"br"}}, []byte("x")); !errors.Is(err, ErrUnsupportedEncoding) {
+		t.Fatalf("br err = %v", err)
+	}
+}
@@ -0,0 +1,286 @@
+package jar
+
+import (
+	"net"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"burpui/internal/httpraw"
+)
+
+type Cookie struct {
+	Domain   string
+	Path     string
+	Name     string
+	Value    string
+	HostOnly bool
+	Expires  time.Time
+	Secure   bool
+	HttpOnly bool
+	SameSite string
+	Updated  time.Time
+}
+
+func (c Cookie) Key() string {
+	return c.Domain + ";" + c.Path + ";" + c.Name
+}
+
+func (c Cookie) Expired(now time.Time) bool {
+	return !c.Expires.IsZero() && !c.Expires.After(now)
+}
+
+func (c Cookie) Session() bool {
+	return c.Expires.IsZero()
+}
+
+type Jar struct {
+	mu      sync.Mutex
+	cookies map[string]Cookie
+	now     func() time.Time
+}
+
+func New() *Jar {
+	return &Jar{cookies: map[string]Cookie{}, now: time.Now}
+}
+
+func (j *Jar) Store(u *url.URL, h http.Header) int {
+	if j == nil || u == nil {
+		return 0
+	}
+	host := canonicalHost(u.Host)
+	if host == "" {
+		return 0
+	}
+	now := j.now()
+	n := 0
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	for _, hc := range (&http.Response{Header: h}).Cookies() {
+		c := Cookie{
+			Domain:   host,
+			Path:     hc.Path,
+			Name:     hc.Name,
+			Value:    hc.Value,
+			HostOnly: true,
+			Secure:   hc.Secure,
+			HttpOnly: hc.HttpOnly,
+			SameSite: sameSite(hc.SameSite),
+			Updated:  now,
+		}
+		if d := strings.ToLower(strings.TrimPrefix(hc.Domain, ".")); d != "" {
+			if !domainMatch(host, d) {
+				continue
+			}
+			c.Domain, c.HostOnly = d, false
+		}
+		if c.Path == "" || c.Path[0] != '/' {
+			c.Path = defaultPath(u.Path)
+		}
+		switch {
+		case hc.MaxAge < 0:
+			c.Expires = time.Unix(1, 0)
+		case hc.MaxAge > 0:
+			c.Expires = now.Add(time.Duration(hc.MaxAge) * time.Second)
+		case !hc.Expires.IsZero():
+			c.Expires = hc.Expires
+		}
+		if c.Expired(now) {
+			delete(j.cookies, c.Key())
+			continue
+		}
+		j.cookies[c.Key()] = c
+		n++
+	}
+	return n
+}
+
+func (j *Jar) Set(c Cookie) {
+	if j == nil {
+		return
+	}
+	c.Domain = canonicalHost(c.Domain)
+	if c.Path == "" {
+		c.Path = "/"
+	}
+	if c.Updated.IsZero() {
+		c.Updated = j.now()
+	}
+	j.mu.Lock()
+	j.cookies[c.Key()] = c
+	j.mu.Unlock()
+}
+
+func (j *Jar) Delete(key string) bool {
+	if j == nil {
+		return false
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if _, ok := j.cookies[key]; !ok {
+		return false
+	}
+	delete(j.cookies, key)
+	return true
+}
+
+func (j *Jar) Clear() {
+	if j == nil {
+		return
+	}
+	j.mu.Lock()
+	j.cookies = map[string]Cookie{}
+	j.mu.Unlock()
+}
+
+func (j *Jar) All() []Cookie {
+	if j == nil {
+		return nil
+	}
+	now := j.now()
+	j.mu.Lock()
+	out := make([]Cookie, 0, len(j.cookies))
+	for k, c := range j.cookies {
+		if c.Expired(now) {
+			delete(j.cookies, k)
+			continue
+		}
+		out = append(out, c)
+	}
+	j.mu.Unlock()
+	sort.Slice(out, func(a, b int) bool {
+		if out[a].Domain != out[b].Domain {
+			return out[a].Domain < out[b].Domain
+		}
+		if out[a].Name != out[b].Name {
+			return out[a].Name < out[b].Name
+		}
+		return out[a].Path < out[b].Path
+	})
+	return out
+}
+
+func (j *Jar) Cookies(u *url.URL) []Cookie {
+	if j == nil || u == nil {
+		return nil
+	}
+	host := canonicalHost(u.Host)
+	path := u.Path
+	if path == "" {
+		path = "/"
+	}
+	https := u.Scheme == "https" || u.Scheme == "wss"
+	var out []Cookie
+	for _, c := range j.All() {
+		if c.HostOnly && c.Domain != host || !c.HostOnly && !domainMatch(host, c.Domain) {
+			continue
+		}
+		if !pathMatch(path, c.Path) || c.Secure && !https {
+			continue
+		}
+		out = append(out, c)
+	}
+	sort.SliceStable(out, func(a, b int) bool { return len(out[a].Path) > len(out[b].Path) })
+	return out
+}
+
+func (j *Jar) Header(u *url.URL) string {
+	var parts []string
+	for _, c := range j.Cookies(u) {
+		parts = append(parts, c.Name+"="+c.Value)
+	}
+	return strings.Join(parts, "; ")
+}
+
+func (j *Jar) ApplyRaw(raw string) string {
+	req, _, err := httpraw.ParseRequest(raw)
+	if err != nil {
+		return raw
+	}
+	cookies := j.Cookies(req.URL)
+	if len(cookies) == 0 {
+		return raw
+	}
+	cur, _ := httpraw.HeaderValue(raw, "Cookie")
+	merged := Merge(cur, cookies)
+	if merged == cur {
+		return raw
+	}
+	return httpraw.SetHeader(raw, "Cookie", merged)
+}
+
+func Merge(header string, cookies []Cookie) string {
+	seen := map[string]bool{}
+	var parts []string
+	for _, p := range strings.Split(header, ";") {
+		p = strings.TrimSpace(p)
+		if p == "" {
+			continue
+		}
+		name, _, _ := strings.Cut(p, "=")
+		seen[strings.TrimSpace(name)] = true
+		parts = append(parts, p)
+	}
+	for _, c := range cookies {
+		if seen[c.Name] {
+			continue
+		}
+		seen[c.Name] = true
+		parts = append(parts, c.Name+"="+c.Value)
+	}
+	return strings.Join(parts, "; ")
+}
+
+func canonicalHost(host string) string {
+	host = strings.ToLower(strings.TrimSpace(host))
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	return strings.Trim(host, "[]")
+}
+
+func domainMatch(host, domain string) bool {
+	if host == domain {
+		return true
+	}
+	if net.ParseIP(host) != nil {
+		return false
+	}
+	return strings.HasSuffix(host, "."+domain)
+}
+
+func pathMatch(reqPath, cookiePath string) bool {
+	if reqPath == cookiePath {
+		return true
+	}
+	if !strings.HasPrefix(reqPath, cookiePath) {
+		return false
+	}
+	return strings.HasSuffix(cookiePath, "/") || reqPath[len(cookiePath)] == '/'
+}
+
+func defaultPath(p string) string {
+	if p == "" || p[0] != '/' {
+		return "/"
+	}
+	i := strings.LastIndex(p, "/")
+	if i == 0 {
+		return "/"
+	}
+	return p[:i]
+}
+
+func sameSite(s http.SameSite) string {
+	switch s {
+	case http.SameSiteLaxMode:
+		return "Lax"
+	case http.SameSiteStrictMode:
+		return "Strict"
+	case http.SameSiteNoneMode:
+		return "None"
+	}
+	return ""
+}
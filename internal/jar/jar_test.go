@@ -0,0 +1,114 @@
+package jar
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func mustURL(t *testing.T, s string) *url.URL {
+	t.Helper()
+	u, err := url.Parse(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return u
+}
+
+func TestStoreAndMatch(t *testing.T) {
+	j := New()
+	h := http.Header{}
+	h.Add("Set-Cookie", "sid=abc; Path=/; HttpOnly")
+	h.Add("Set-Cookie", "pref=dark; Domain=.exemplo.com; Path=/app")
+	h.Add("Set-Cookie", "tok=s3; Secure")
+	h.Add("Set-Cookie", "evil=1; Domain=outro.com")
+	if n := j.Store(mustURL(t, "https://www.exemplo.com:8443/login/form"), h); n != 3 {
+		t.Fatalf("stored %d cookies, want 3", n)
+	}
+
+	all := j.All()
+	if len(all) != 3 {
+		t.Fatalf("All = %d cookies", len(all))
+	}
+	for _, c := range all {
+		if c.Name == "tok" && c.Path != "/login" {
+			t.Fatalf("default path = %q", c.Path)
+		}
+		if c.Name == "sid" && (!c.HostOnly || !c.HttpOnly || c.Domain != "www.exemplo.com") {
+			t.Fatalf("unexpected sid cookie: %+v", c)
+		}
+	}
+
+	if got := j.Header(mustURL(t, "https://www.exemplo.com/app/x")); got != "pref=dark; sid=abc" {
+		t.Fatalf("Header = %q", got)
+	}
+	if got := j.Header(mustURL(t, "http://api.exemplo.com/app")); got != "pref=dark" {
+		t.Fatalf("subdomain Header = %q", got)
+	}
+	if got := j.Header(mustURL(t, "http://www.exemplo.com/login/x")); got != "sid=abc" {
+		t.Fatalf("secure cookie sent over http: %q", got)
+	}
+	if got := j.Header(mustURL(t, "https://www.exemplo.com/login/x")); got != "tok=s3; sid=abc" {
+		t.Fatalf("https Header = %q", got)
+	}
+}
+
+func TestStoreExpiry(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	j := New()
+	j.now = func() time.Time { return now }
+	u := mustURL(t, "http://a.test/")
+	j.Store(u, http.Header{"Set-Cookie": {"sid=1; Max-Age=60", "old=1"}})
+	if len(j.All()) != 2 {
+		t.Fatalf("expected 2 cookies")
+	}
+	j.Store(u, http.Header{"Set-Cookie": {"old=; Max-Age=0"}})
+	if got := j.Header(u); got != "sid=1" {
+		t.Fatalf("Header after delete = %q", got)
+	}
+	now = now.Add(2 * time.Minute)
+	if got := j.Header(u); got != "" {
+		t.Fatalf("expired cookie still sent: %q", got)
+	}
+}
+
+func TestSetDeleteClear(t *testing.T) {
+	j := New()
+	j.Set(Cookie{Domain: "A.test:80", Name: "x", Value: "1", HostOnly: true})
+	all := j.All()
+	if len(all) != 1 || all[0].Key() != "a.test;/;x" {
+		t.Fatalf("unexpected cookies: %+v", all)
+	}
+	if !j.Delete("a.test;/;x") || j.Delete("a.test;/;x") {
+		t.Fatalf("Delete did not report correctly")
+	}
+	j.Set(Cookie{Domain: "a.test", Name: "y", Value: "2"})
+	j.Clear()
+	if len(j.All()) != 0 {
+		t.Fatalf("Clear left cookies")
+	}
+
+	var nilJar *Jar
+	nilJar.Set(Cookie{Name: "x"})
+	if nilJar.Header(mustURL(t, "http://a.test/")) != "" {
+		t.Fatalf("nil jar returned cookies")
+	}
+}
+
+func TestApplyRaw(t *testing.T) {
+	j := New()
+	j.Set(Cookie{Domain: "a.test", Name: "sid", Value: "new", HostOnly: true})
+	j.Set(Cookie{Domain: "a.test", Name: "csrf", Value: "t", HostOnly: true})
+
+	raw := "GET /x HTTP/1.1\r\nHost: a.test\r\nCookie: sid=mine\r\n\r\n"
+	got := j.ApplyRaw(raw)
+	want := "GET /x HTTP/1.1\r\nHost: a.test\r\nCookie: sid=mine; csrf=t\r\n\r\n"
+	if got != want {
+		t.Fatalf("ApplyRaw = %q", got)
+	}
+	other := "GET / HTTP/1.1\nHost: b.test\n\n"
+	if j.ApplyRaw(other) != other {
+		t.Fatalf("cookies attached to unrelated host")
+	}
+}
@@ -0,0 +1,114 @@
+package jwt
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+type Token struct {
+	Raw       string
+	Header    map[string]any
+	Claims    map[string]any
+	Signature []byte
+}
+
+var tokenRe = regexp.MustCompile(`eyJ[A-Za-z0-9_-]{5,}\.eyJ[A-Za-z0-9_-]*\.[A-Za-z0-9_-]*`)
+
+func Looks(s string) bool {
+	s = strings.TrimSpace(s)
+	m := tokenRe.FindString(s)
+	return m != "" && m == s
+}
+
+func Find(s string) []string {
+	return tokenRe.FindAllString(s, -1)
+}
+
+func Parse(s string) (*Token, error) {
+	s = strings.TrimSpace(s)
+	parts := strings.Split(s, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("jwt: esperado 3 partes, veio %d", len(parts))
+	}
+	t := &Token{Raw: s}
+	if err := decodePart(parts[0], &t.Header); err != nil {
+		return nil, fmt.Errorf("jwt: header: %w", err)
+	}
+	if err := decodePart(parts[1], &t.Claims); err != nil {
+		return nil, fmt.Errorf("jwt: payload: %w", err)
+	}
+	sig, err := decodeSegment(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("jwt: assinatura: %w", err)
+	}
+	t.Signature = sig
+	return t, nil
+}
+
+func (t *Token) Alg() string {
+	alg, _ := t.Header["alg"].(string)
+	return alg
+}
+
+func (t *Token) Pretty() string {
+	var b strings.Builder
+	b.WriteString("header:\n")
+	b.WriteString(indentJSON(t.Header))
+	b.WriteString("\npayload:\n")
+	b.WriteString(indentJSON(t.Claims))
+	b.WriteString("\nassinatura: ")
+	if len(t.Signature) == 0 {
+		b.WriteString("(vazia)")
+	} else {
+		b.WriteString(base64.RawURLEncoding.EncodeToString(t.Signature))
+	}
+	return b.String()
+}
+
+func (t *Token) HeaderJSON() string {
+	return indentJSON(t.Header)
+}
+
+func (t *Token) ClaimsJSON() string {
+	return indentJSON(t.Claims)
+}
+
+func DecodeJSON(s string) (map[string]any, error) {
+	var v map[string]any
+	dec := json.NewDecoder(strings.NewReader(s))
+	dec.UseNumber()
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	if dec.More() {
+		return nil, fmt.Errorf("conteúdo extra depois do objeto JSON")
+	}
+	return v, nil
+}
+
+func indentJSON(v any) string {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	_ = enc.Encode(v)
+	return strings.TrimRight(buf.String(), "\n")
+}
+
+func decodePart(seg string, v *map[string]any) error {
+	b, err := decodeSegment(seg)
+	if err != nil {
+		return err
+	}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	return dec.Decode(v)
+}
+
+func decodeSegment(seg string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(strings.TrimRight(seg, "="))
+}
@@ -0,0 +1,191 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"hash"
+	"strings"
+	"time"
+)
+
+const KeepSignature = ""
+
+var Algs = []string{KeepSignature, "none", "None", "NONE", "HS256", "HS384", "HS512", "RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512"}
+
+func IsNone(alg string) bool {
+	return strings.EqualFold(alg, "none")
+}
+
+func (t *Token) SigningInput() string {
+	i := strings.LastIndex(t.Raw, ".")
+	if i < 0 {
+		return t.Raw
+	}
+	return t.Raw[:i]
+}
+
+func Encode(header, claims map[string]any) (string, error) {
+	h, err := json.Marshal(header)
+	if err != nil {
+		return "", fmt.Errorf("jwt: header: %w", err)
+	}
+	c, err := json.Marshal(claims)
+	if err != nil {
+		return "", fmt.Errorf("jwt: payload: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(h) + "." + base64.RawURLEncoding.EncodeToString(c), nil
+}
+
+func Sign(header, claims map[string]any, alg string, key []byte, keep []byte) (string, error) {
+	h := make(map[string]any, len(header)+1)
+	for k, v := range header {
+		h[k] = v
+	}
+	if alg != KeepSignature {
+		h["alg"] = alg
+	}
+	input, err := Encode(h, claims)
+	if err != nil {
+		return "", err
+	}
+	var sig []byte
+	switch {
+	case alg == KeepSignature:
+		sig = keep
+	case IsNone(alg):
+	default:
+		if len(key) == 0 {
+			return "", fmt.Errorf("jwt: %s precisa de chave", alg)
+		}
+		sig, err = signature(alg, []byte(input), key)
+		if err != nil {
+			return "", err
+		}
+	}
+	return input + "." + base64.RawURLEncoding.EncodeToString(sig), nil
+}
+
+func Verify(t *Token, key []byte) (bool, error) {
+	alg := t.Alg()
+	switch {
+	case IsNone(alg):
+		return len(t.Signature) == 0, nil
+	case strings.HasPrefix(alg, "HS"):
+		want, err := signature(alg, []byte(t.SigningInput()), key)
+		if err != nil {
+			return false, err
+		}
+		return hmac.Equal(want, t.Signature), nil
+	}
+	return false, fmt.Errorf("jwt: verificação de %s não suportada", alg)
+}
+
+func (t *Token) Time(claim string) (time.Time, bool) {
+	var n float64
+	switch v := t.Claims[claim].(type) {
+	case json.Number:
+		f, err := v.Float64()
+		if err != nil {
+			return time.Time{}, false
+		}
+		n = f
+	case float64:
+		n = v
+	default:
+		return time.Time{}, false
+	}
+	return time.Unix(int64(n), 0), true
+}
+
+func (t *Token) Expired(now time.Time) bool {
+	exp, ok := t.Time("exp")
+	return ok && now.After(exp)
+}
+
+func signature(alg string, input, key []byte) ([]byte, error) {
+	h, ch, err := hashFor(alg)
+	if err != nil {
+		return nil, err
+	}
+	if strings.HasPrefix(alg, "HS") {
+		mac := hmac.New(h, key)
+		mac.Write(input)
+		return mac.Sum(nil), nil
+	}
+	d := h()
+	d.Write(input)
+	digest := d.Sum(nil)
+
+	priv, err := parsePrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+	switch alg[:2] {
+	case "RS", "PS":
+		k, ok := priv.(*rsa.PrivateKey)
+		if !ok {
+			return nil, fmt.Errorf("jwt: %s precisa de chave RSA", alg)
+		}
+		if alg[:2] == "PS" {
+			return rsa.SignPSS(rand.Reader, k, ch, digest, &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash})
+		}
+		return rsa.SignPKCS1v15(rand.Reader, k, ch, digest)
+	case "ES":
+		k, ok := priv.(*ecdsa.PrivateKey)
+		if !ok {
+			return nil, fmt.Errorf("jwt: %s precisa de chave EC", alg)
+		}
+		r, s, err := ecdsa.Sign(rand.Reader, k, digest)
+		if err != nil {
+			return nil, err
+		}
+		size := (k.Curve.Params().BitSize + 7) / 8
+		return append(r.FillBytes(make([]byte, size)), s.FillBytes(make([]byte, size))...), nil
+	}
+	return nil, fmt.Errorf("jwt: algoritmo não suportado: %s", alg)
+}
+
+func hashFor(alg string) (func() hash.Hash, crypto.Hash, error) {
+	if len(alg) != 5 {
+		return nil, 0, fmt.Errorf("jwt: algoritmo não suportado: %s", alg)
+	}
+	switch alg[2:] {
+	case "256":
+		return sha256.New, crypto.SHA256, nil
+	case "384":
+		return sha512.New384, crypto.SHA384, nil
+	case "512":
+		return sha512.New, crypto.SHA512, nil
+	}
+	return nil, 0, fmt.Errorf("jwt: algoritmo não suportado: %s", alg)
+}
+
+func parsePrivateKey(key []byte) (crypto.Signer, error) {
+	block, _ := pem.Decode(key)
+	if block == nil {
+		return nil, errors.New("jwt: chave privada PEM esperada")
+	}
+	if k, err := x509.ParsePKCS8PrivateKey(block.Bytes); err == nil {
+		if s, ok := k.(crypto.Signer); ok {
+			return s, nil
+		}
+	}
+	if k, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return k, nil
+	}
+	if k, err := x509.ParseECPrivateKey(block.Bytes); err == nil {
+		return k, nil
+	}
+	return nil, errors.New("jwt: chave privada PEM não reconhecida")
+}
@@ -0,0 +1,97 @@
+package macro
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"burpui/internal/sequencer"
+)
+
+type stepSpec struct {
+	Request string            `json:"request"`
+	Extract map[string]string `json:"extract,omitempty"`
+}
+
+type macroSpec struct {
+	Name  string     `json:"name"`
+	Steps []stepSpec `json:"steps"`
+}
+
+type fileSpec struct {
+	Macros []macroSpec       `json:"macros"`
+	Rules  []string          `json:"rules,omitempty"`
+	Vars   map[string]string `json:"vars,omitempty"`
+}
+
+func (e *Engine) Load(path string) error {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	var spec fileSpec
+	if err := json.Unmarshal(b, &spec); err != nil {
+		return fmt.Errorf("macros %s: %w", path, err)
+	}
+	for k, v := range spec.Vars {
+		e.SetVar(k, v)
+	}
+	for _, ms := range spec.Macros {
+		if err := e.AddMacro(ms.Name); err != nil {
+			return fmt.Errorf("macros %s: %w", path, err)
+		}
+		for i, st := range ms.Steps {
+			if _, err := e.AddStep(ms.Name, st.Request); err != nil {
+				return fmt.Errorf("macros %s: %s passo %d: %w", path, ms.Name, i+1, err)
+			}
+			for v, exSpec := range st.Extract {
+				ex, err := sequencer.ParseExtractor(exSpec)
+				if err != nil {
+					return fmt.Errorf("macros %s: %s passo %d: %w", path, ms.Name, i+1, err)
+				}
+				if err := e.AddExtract(ms.Name, i, v, ex); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	for i, rs := range spec.Rules {
+		r, err := ParseRule(rs)
+		if err != nil {
+			return fmt.Errorf("macros %s: regra %d: %w", path, i+1, err)
+		}
+		e.AddRule(r)
+	}
+	return nil
+}
+
+func (e *Engine) Save(path string) error {
+	spec := fileSpec{Macros: []macroSpec{}}
+	for _, m := range e.Macros() {
+		ms := macroSpec{Name: m.Name, Steps: []stepSpec{}}
+		for _, st := range m.Steps {
+			ss := stepSpec{Request: st.Request, Extract: map[string]string{}}
+			for v, ex := range st.Extract {
+				ss.Extract[v] = ex.String()
+			}
+			ms.Steps = append(ms.Steps, ss)
+		}
+		spec.Macros = append(spec.Macros, ms)
+	}
+	for _, r := range e.Rules() {
+		spec.Rules = append(spec.Rules, r.String())
+	}
+	e.mu.Lock()
+	for k := range e.static {
+		if spec.Vars == nil {
+			spec.Vars = map[string]string{}
+		}
+		spec.Vars[k] = e.vars[k]
+	}
+	e.mu.Unlock()
+	b, err := json.MarshalIndent(spec, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(b, '\n'), 0o600)
+}
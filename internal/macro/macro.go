@@ -0,0 +1,371 @@
+package macro
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"burpui/internal/httpraw"
+	"burpui/internal/repeater"
+	"burpui/internal/sequencer"
+)
+
+var placeholder = regexp.MustCompile(`\{\{\s*([A-Za-z0-9_.-]+)\s*\}\}`)
+
+type Step struct {
+	Request string
+	Extract map[string]sequencer.Extractor
+}
+
+type Macro struct {
+	Name  string
+	Steps []Step
+}
+
+type Options struct {
+	Repeater repeater.Options
+	Send     func(raw string, opts repeater.Options) (*repeater.Result, error)
+}
+
+type Engine struct {
+	opts Options
+
+	mu     sync.Mutex
+	macros []Macro
+	rules  []Rule
+	vars   map[string]string
+	static map[string]bool
+	runs   map[string]time.Time
+
+	runMu sync.Mutex
+}
+
+func New(opts Options) *Engine {
+	if opts.Send == nil {
+		opts.Send = repeater.Send
+	}
+	return &Engine{opts: opts, vars: map[string]string{}, static: map[string]bool{}, runs: map[string]time.Time{}}
+}
+
+func (e *Engine) Macros() []Macro {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	out := make([]Macro, len(e.macros))
+	for i, m := range e.macros {
+		out[i] = Macro{Name: m.Name, Steps: cloneSteps(m.Steps)}
+	}
+	return out
+}
+
+func (e *Engine) AddMacro(name string) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if name == "" {
+		return fmt.Errorf("macro: nome vazio")
+	}
+	if e.find(name) >= 0 {
+		return fmt.Errorf("macro: %s já existe", name)
+	}
+	e.macros = append(e.macros, Macro{Name: name})
+	return nil
+}
+
+func (e *Engine) RemoveMacro(name string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if i := e.find(name); i >= 0 {
+		e.macros = append(e.macros[:i], e.macros[i+1:]...)
+	}
+}
+
+func (e *Engine) AddStep(name, raw string) (int, error) {
+	if _, _, err := httpraw.ParseRequest(raw); err != nil {
+		return 0, fmt.Errorf("macro: requisição inválida: %w", err)
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	i := e.find(name)
+	if i < 0 {
+		return 0, fmt.Errorf("macro: %s não existe", name)
+	}
+	e.macros[i].Steps = append(e.macros[i].Steps, Step{Request: raw, Extract: map[string]sequencer.Extractor{}})
+	return len(e.macros[i].Steps), nil
+}
+
+func (e *Engine) AddExtract(name string, step int, variable string, ex sequencer.Extractor) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	i := e.find(name)
+	if i < 0 {
+		return fmt.Errorf("macro: %s não existe", name)
+	}
+	steps := e.macros[i].Steps
+	if step < 0 {
+		step = len(steps) - 1
+	}
+	if step < 0 || step >= len(steps) {
+		return fmt.Errorf("macro: %s não tem o passo %d", name, step+1)
+	}
+	if variable == "" {
+		return fmt.Errorf("macro: variável sem nome")
+	}
+	if steps[step].Extract == nil {
+		steps[step].Extract = map[string]sequencer.Extractor{}
+	}
+	steps[step].Extract[variable] = ex
+	return nil
+}
+
+func cloneSteps(steps []Step) []Step {
+	out := make([]Step, len(steps))
+	for i, st := range steps {
+		out[i] = Step{Request: st.Request, Extract: make(map[string]sequencer.Extractor, len(st.Extract))}
+		for k, v := range st.Extract {
+			out[i].Extract[k] = v
+		}
+	}
+	return out
+}
+
+func (e *Engine) find(name string) int {
+	for i, m := range e.macros {
+		if m.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+func (e *Engine) Rules() []Rule {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return append([]Rule(nil), e.rules...)
+}
+
+func (e *Engine) AddRule(r Rule) {
+	e.mu.Lock()
+	e.rules = append(e.rules, r)
+	e.mu.Unlock()
+}
+
+func (e *Engine) RemoveRule(i int) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if i >= 0 && i < len(e.rules) {
+		e.rules = append(e.rules[:i], e.rules[i+1:]...)
+	}
+}
+
+func (e *Engine) Vars() map[string]string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	out := make(map[string]string, len(e.vars))
+	for k, v := range e.vars {
+		out[k] = v
+	}
+	return out
+}
+
+func (e *Engine) VarNames() []string {
+	vars := e.Vars()
+	names := make([]string, 0, len(vars))
+	for k := range vars {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (e *Engine) SetVar(name, value string) {
+	e.mu.Lock()
+	e.vars[name] = value
+	e.static[name] = true
+	e.mu.Unlock()
+}
+
+func (e *Engine) Expand(s string) string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	out, _ := e.expand(s)
+	return out
+}
+
+func (e *Engine) expand(s string) (string, bool) {
+	ok := true
+	out := placeholder.ReplaceAllStringFunc(s, func(m string) string {
+		name := placeholder.FindStringSubmatch(m)[1]
+		if v, found := e.vars[name]; found {
+			return v
+		}
+		ok = false
+		return m
+	})
+	return out, ok
+}
+
+func (e *Engine) expandRequest(raw string) string {
+	out, _ := e.expand(raw)
+	if out == raw {
+		return raw
+	}
+	r := splitRaw(out)
+	if n, ok := r.header("Content-Length"); ok && n != strconv.Itoa(len(r.body)) {
+		r.setHeader("Content-Length", strconv.Itoa(len(r.body)))
+		return r.String()
+	}
+	return out
+}
+
+func (e *Engine) Run(name string) (map[string]string, error) {
+	e.runMu.Lock()
+	defer e.runMu.Unlock()
+	return e.run(name)
+}
+
+func (e *Engine) run(name string) (map[string]string, error) {
+	e.mu.Lock()
+	i := e.find(name)
+	var steps []Step
+	if i >= 0 {
+		steps = cloneSteps(e.macros[i].Steps)
+	}
+	e.mu.Unlock()
+	if i < 0 {
+		return nil, fmt.Errorf("macro: %s não existe", name)
+	}
+	if len(steps) == 0 {
+		return nil, fmt.Errorf("macro: %s não tem passos", name)
+	}
+
+	got := map[string]string{}
+	for n, st := range steps {
+		e.mu.Lock()
+		raw := httpraw.DelHeader(e.expandRequest(st.Request), "Accept-Encoding")
+		e.mu.Unlock()
+		res, err := e.opts.Send(raw, e.opts.Repeater)
+		if err != nil {
+			return got, fmt.Errorf("macro %s passo %d: %w", name, n+1, err)
+		}
+		for v, ex := range st.Extract {
+			val, ok := ex.Extract(res.Header, res.Body)
+			if !ok {
+				return got, fmt.Errorf("macro %s passo %d: %s não encontrado (%s, status %d)", name, n+1, v, ex, res.StatusCode)
+			}
+			got[v] = val
+			e.mu.Lock()
+			e.vars[v] = val
+			e.mu.Unlock()
+		}
+	}
+	e.mu.Lock()
+	e.runs[name] = time.Now()
+	e.mu.Unlock()
+	return got, nil
+}
+
+func (e *Engine) ApplyRaw(raw string) string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	raw = e.expandRequest(raw)
+	req, _, err := httpraw.ParseRequest(raw)
+	if err != nil {
+		return raw
+	}
+	r := splitRaw(raw)
+	applied := false
+	for _, rule := range e.rules {
+		if !rule.Matches(req.URL.String()) {
+			continue
+		}
+		for _, s := range rule.Set {
+			if v, ok := e.expand(s.Value); ok {
+				r.apply(s, v)
+				applied = true
+			}
+		}
+	}
+	if !applied {
+		return raw
+	}
+	return r.String()
+}
+
+func (e *Engine) Active() bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return len(e.rules) > 0
+}
+
+func (e *Engine) Prepare(urlStr string, req *http.Request) bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	applied := false
+	for _, rule := range e.rules {
+		if !rule.Matches(urlStr) {
+			continue
+		}
+		for _, s := range rule.Set {
+			v, ok := e.expand(s.Value)
+			if !ok {
+				continue
+			}
+			applyRequest(req, s, v)
+			if s.Kind == SetParam {
+				setFormParam(req, s.Name, v)
+			}
+			applied = true
+		}
+	}
+	return applied
+}
+
+func (e *Engine) Refresh(urlStr string, status int, since time.Time) (string, error) {
+	e.mu.Lock()
+	name := ""
+	for _, rule := range e.rules {
+		if rule.Matches(urlStr) && rule.triggers(status) {
+			name = rule.Run
+			break
+		}
+	}
+	e.mu.Unlock()
+	if name == "" {
+		return "", nil
+	}
+
+	e.runMu.Lock()
+	defer e.runMu.Unlock()
+	e.mu.Lock()
+	last := e.runs[name]
+	e.mu.Unlock()
+	if last.After(since) {
+		return name, nil
+	}
+	_, err := e.run(name)
+	return name, err
+}
+
+func (e *Engine) Send(raw string, opts repeater.Options) (*repeater.Result, error) {
+	since := time.Now()
+	out := e.ApplyRaw(raw)
+	res, err := e.opts.Send(out, opts)
+	if err != nil {
+		return nil, err
+	}
+	req, _, err := httpraw.ParseRequest(out)
+	if err != nil {
+		return res, nil
+	}
+	name, err := e.Refresh(req.URL.String(), res.StatusCode, since)
+	if err != nil {
+		return nil, err
+	}
+	if name == "" {
+		return res, nil
+	}
+	return e.opts.Send(e.ApplyRaw(raw), opts)
+}
@@ -0,0 +1,186 @@
+package macro
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"burpui/internal/httpraw"
+	"burpui/internal/repeater"
+	"burpui/internal/sequencer"
+)
+
+func TestParseRule(t *testing.T) {
+	r, err := ParseRule("match=api.test|header:authorization=Bearer {{token}}|cookie:csrf={{csrf}}|run=login")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Match != "api.test" || len(r.Set) != 2 || r.Set[0].Name != "Authorization" || r.Run != "login" || len(r.On) != 1 || r.On[0] != 401 {
+		t.Fatalf("rule = %+v", r)
+	}
+	if back, err := ParseRule(r.String()); err != nil || back.String() != r.String() {
+		t.Fatalf("round trip = %q %v", back.String(), err)
+	}
+	for _, bad := range []string{"match=x", "on=401", "xml:a=b|run=x", "match=x|on=abc|run=y", "header:=v", "semigual"} {
+		if _, err := ParseRule(bad); err == nil {
+			t.Fatalf("expected error for %q", bad)
+		}
+	}
+}
+
+func TestApplyRaw(t *testing.T) {
+	e := New(Options{})
+	e.SetVar("token", "t1")
+	e.SetVar("csrf", "c 2")
+	r, _ := ParseRule("match=app.test|header:Authorization=Bearer {{token}}|cookie:csrf={{csrf}}|param:csrf={{csrf}}|header:X-Missing={{nope}}")
+	e.AddRule(r)
+
+	raw := "POST http://app.test/save?csrf=old&x=1 HTTP/1.1\r\nHost: app.test\r\nAuthorization: Basic abc\r\nCookie: a=1; csrf=old\r\nContent-Type: application/x-www-form-urlencoded\r\nContent-Length: 16\r\n\r\nname=z&csrf=old1"
+	out := e.ApplyRaw(raw)
+	req, body, err := httpraw.ParseRequest(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if req.Header.Get("Authorization") != "Bearer t1" || req.Header.Get("Cookie") != "a=1; csrf=c 2" || req.Header.Get("X-Missing") != "" {
+		t.Fatalf("headers = %v", req.Header)
+	}
+	if req.URL.Query().Get("csrf") != "c 2" || req.URL.Query().Get("x") != "1" || string(body) != "name=z&csrf=c+2" {
+		t.Fatalf("url=%s body=%q", req.URL, body)
+	}
+	if other := "GET http://other.test/{{token}} HTTP/1.1\nHost: other.test\n\n"; e.ApplyRaw(other) != "GET http://other.test/t1 HTTP/1.1\nHost: other.test\n\n" {
+		t.Fatalf("placeholders not expanded: %q", e.ApplyRaw(other))
+	}
+}
+
+func TestPrepare(t *testing.T) {
+	e := New(Options{})
+	e.SetVar("csrf", "new")
+	r, _ := ParseRule("header:X-Csrf={{csrf}}|param:csrf={{csrf}}")
+	e.AddRule(r)
+	req, _ := http.NewRequest("POST", "http://app.test/x?csrf=1", strings.NewReader("csrf=old&a=b"))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if !e.Prepare(req.URL.String(), req) {
+		t.Fatalf("rule not applied")
+	}
+	b, _ := io.ReadAll(req.Body)
+	if req.Header.Get("X-Csrf") != "new" || req.URL.RawQuery != "csrf=new" || string(b) != "csrf=new&a=b" || req.ContentLength != int64(len(b)) {
+		t.Fatalf("req = %v %s %q", req.Header, req.URL.RawQuery, b)
+	}
+}
+
+func TestSendRefreshesOn401(t *testing.T) {
+	var logins, current atomic.Int64
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/login":
+			if r.FormValue("user") != "alice" {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			n := logins.Add(1)
+			current.Store(n)
+			fmt.Fprintf(w, `{"data":{"access_token":"tok-%d"}}`, n)
+		default:
+			if r.Header.Get("Authorization") != fmt.Sprintf("Bearer tok-%d", current.Load()) {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			io.WriteString(w, "ok")
+		}
+	}))
+	defer srv.Close()
+	host := strings.TrimPrefix(srv.URL, "http://")
+
+	e := New(Options{Repeater: repeater.Options{Timeout: 5 * time.Second}})
+	e.SetVar("user", "alice")
+	if err := e.AddMacro("login"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := e.AddStep("login", "POST "+srv.URL+"/login HTTP/1.1\r\nHost: "+host+"\r\nContent-Type: application/x-www-form-urlencoded\r\nContent-Length: 13\r\n\r\nuser={{user}}"); err != nil {
+		t.Fatal(err)
+	}
+	ex, _ := sequencer.ParseExtractor("json:$.data.access_token")
+	if err := e.AddExtract("login", -1, "token", ex); err != nil {
+		t.Fatal(err)
+	}
+	r, _ := ParseRule("match=" + host + "/api|header:Authorization=Bearer {{token}}|on=401|run=login")
+	e.AddRule(r)
+
+	raw := "GET " + srv.URL + "/api/me HTTP/1.1\r\nHost: " + host + "\r\n\r\n"
+	res, err := e.Send(raw, repeater.Options{Timeout: 5 * time.Second})
+	if err != nil || res.StatusCode != 200 || logins.Load() != 1 || e.Vars()["token"] != "tok-1" {
+		t.Fatalf("first send: %+v %v logins=%d", res, err, logins.Load())
+	}
+	if res, err := e.Send(raw, repeater.Options{Timeout: 5 * time.Second}); err != nil || res.StatusCode != 200 || logins.Load() != 1 {
+		t.Fatalf("second send should reuse token: %+v %v", res, err)
+	}
+
+	current.Store(99)
+	if res, err := e.Send(raw, repeater.Options{Timeout: 5 * time.Second}); err != nil || res.StatusCode != 200 || logins.Load() != 2 {
+		t.Fatalf("expired token not refreshed: %+v %v", res, err)
+	}
+
+	e.SetVar("user", "bob")
+	current.Store(99)
+	if _, err := e.Send(raw, repeater.Options{Timeout: 5 * time.Second}); err == nil || !strings.Contains(err.Error(), "token não encontrado") {
+		t.Fatalf("expected macro failure, got %v", err)
+	}
+}
+
+func TestRunCompressed(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if strings.Contains(r.Header.Get("Accept-Encoding"), "gzip") {
+			w.Header().Set("Content-Encoding", "gzip")
+			zw := gzip.NewWriter(w)
+			defer zw.Close()
+			io.WriteString(zw, `{"token":"abc"}`)
+			return
+		}
+		io.WriteString(w, `{"token":"abc"}`)
+	}))
+	defer srv.Close()
+
+	e := New(Options{Repeater: repeater.Options{Timeout: 5 * time.Second}})
+	e.AddMacro("login")
+	e.AddStep("login", "GET "+srv.URL+"/login HTTP/1.1\r\nHost: "+strings.TrimPrefix(srv.URL, "http://")+"\r\nAccept-Encoding: gzip, deflate, br\r\n\r\n")
+	ex, _ := sequencer.ParseExtractor("json:$.token")
+	e.AddExtract("login", -1, "token", ex)
+	if got, err := e.Run("login"); err != nil || got["token"] != "abc" {
+		t.Fatalf("run = %v %v", got, err)
+	}
+}
+
+func TestLoadSave(t *testing.T) {
+	e := New(Options{})
+	e.SetVar("user", "alice")
+	e.AddMacro("login")
+	e.AddStep("login", "GET http://app.test/login HTTP/1.1\nHost: app.test\n\n")
+	ex, _ := sequencer.ParseExtractor("cookie:sid")
+	e.AddExtract("login", 0, "sid", ex)
+	r, _ := ParseRule("match=app.test|cookie:sid={{sid}}|on=401,403|run=login")
+	e.AddRule(r)
+
+	path := filepath.Join(t.TempDir(), "macros.json")
+	if err := e.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	got := New(Options{})
+	if err := got.Load(path); err != nil {
+		t.Fatal(err)
+	}
+	ms := got.Macros()
+	if len(ms) != 1 || len(ms[0].Steps) != 1 || ms[0].Steps[0].Extract["sid"].String() != "cookie:sid" {
+		t.Fatalf("macros = %+v", ms)
+	}
+	if rs := got.Rules(); len(rs) != 1 || rs[0].String() != r.String() || got.Vars()["user"] != "alice" {
+		t.Fatalf("rules = %v vars = %v", rs, got.Vars())
+	}
+}
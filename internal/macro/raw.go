@@ -0,0 +1,120 @@
+package macro
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const maxFormBody = 1 << 20
+
+type rawRequest struct {
+	line    string
+	headers []string
+	body    string
+	sep     string
+}
+
+func splitRaw(raw string) rawRequest {
+	r := rawRequest{sep: "\r\n"}
+	head, body, ok := strings.Cut(raw, "\r\n\r\n")
+	if !ok {
+		r.sep = "\n"
+		head, body, _ = strings.Cut(raw, "\n\n")
+	}
+	lines := strings.Split(head, r.sep)
+	r.line, r.headers, r.body = lines[0], lines[1:], body
+	return r
+}
+
+func (r rawRequest) String() string {
+	lines := append([]string{r.line}, r.headers...)
+	return strings.Join(lines, r.sep) + r.sep + r.sep + r.body
+}
+
+func (r rawRequest) header(name string) (string, bool) {
+	for _, l := range r.headers {
+		k, v, ok := strings.Cut(l, ":")
+		if ok && http.CanonicalHeaderKey(strings.TrimSpace(k)) == http.CanonicalHeaderKey(name) {
+			return strings.TrimSpace(v), true
+		}
+	}
+	return "", false
+}
+
+func (r *rawRequest) setHeader(name, value string) {
+	out := r.headers[:0:0]
+	set := false
+	for _, l := range r.headers {
+		k, _, ok := strings.Cut(l, ":")
+		if ok && http.CanonicalHeaderKey(strings.TrimSpace(k)) == http.CanonicalHeaderKey(name) {
+			if !set {
+				out = append(out, name+": "+value)
+				set = true
+			}
+			continue
+		}
+		out = append(out, l)
+	}
+	if !set {
+		out = append(out, name+": "+value)
+	}
+	r.headers = out
+}
+
+func (r *rawRequest) apply(s Setter, value string) {
+	switch s.Kind {
+	case SetHeader:
+		r.setHeader(s.Name, value)
+	case SetCookie:
+		cur, _ := r.header("Cookie")
+		r.setHeader("Cookie", setCookie(cur, s.Name, value))
+	case SetParam:
+		fields := strings.Fields(r.line)
+		if len(fields) >= 2 {
+			if path, query, ok := strings.Cut(fields[1], "?"); ok {
+				if q, ok := replaceParam(query, s.Name, value); ok {
+					fields[1] = path + "?" + q
+					r.line = strings.Join(fields, " ")
+				}
+			}
+		}
+		if ct, _ := r.header("Content-Type"); isForm(ct) {
+			if b, ok := replaceParam(r.body, s.Name, value); ok {
+				r.body = b
+				if _, ok := r.header("Content-Length"); ok {
+					r.setHeader("Content-Length", strconv.Itoa(len(b)))
+				}
+			}
+		}
+	}
+}
+
+func isForm(contentType string) bool {
+	return strings.HasPrefix(strings.ToLower(strings.TrimSpace(contentType)), "application/x-www-form-urlencoded")
+}
+
+func setFormParam(req *http.Request, name, value string) {
+	if req.Body == nil || req.Body == http.NoBody || !isForm(req.Header.Get("Content-Type")) {
+		return
+	}
+	if req.ContentLength < 0 || req.ContentLength > maxFormBody {
+		return
+	}
+	b, err := io.ReadAll(req.Body)
+	_ = req.Body.Close()
+	if err != nil {
+		req.Body = io.NopCloser(bytes.NewReader(b))
+		return
+	}
+	if nb, ok := replaceParam(string(b), name, value); ok {
+		b = []byte(nb)
+	}
+	req.Body = io.NopCloser(bytes.NewReader(b))
+	req.ContentLength = int64(len(b))
+	req.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(b)), nil
+	}
+}
@@ -0,0 +1,187 @@
+package macro
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+type SetterKind int
+
+const (
+	SetHeader SetterKind = iota
+	SetCookie
+	SetParam
+)
+
+type Setter struct {
+	Kind  SetterKind
+	Name  string
+	Value string
+}
+
+func (s Setter) String() string {
+	kind := "header"
+	switch s.Kind {
+	case SetCookie:
+		kind = "cookie"
+	case SetParam:
+		kind = "param"
+	}
+	return kind + ":" + s.Name + "=" + s.Value
+}
+
+type Rule struct {
+	Match string
+	Set   []Setter
+	On    []int
+	Run   string
+}
+
+func ParseRule(spec string) (Rule, error) {
+	var r Rule
+	for _, part := range strings.Split(spec, "|") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		k, v, ok := strings.Cut(part, "=")
+		if !ok {
+			return Rule{}, fmt.Errorf("macro: parte inválida na regra: %q", part)
+		}
+		k, v = strings.TrimSpace(k), strings.TrimSpace(v)
+		if kind, name, ok := strings.Cut(k, ":"); ok {
+			s := Setter{Name: strings.TrimSpace(name), Value: v}
+			switch strings.ToLower(kind) {
+			case "header":
+				s.Kind = SetHeader
+				s.Name = http.CanonicalHeaderKey(s.Name)
+			case "cookie":
+				s.Kind = SetCookie
+			case "param":
+				s.Kind = SetParam
+			default:
+				return Rule{}, fmt.Errorf("macro: destino desconhecido: %q (use header:, cookie: ou param:)", kind)
+			}
+			if s.Name == "" {
+				return Rule{}, fmt.Errorf("macro: nome vazio em %q", part)
+			}
+			r.Set = append(r.Set, s)
+			continue
+		}
+		switch strings.ToLower(k) {
+		case "match":
+			r.Match = v
+		case "on":
+			for _, c := range strings.Split(v, ",") {
+				n, err := strconv.Atoi(strings.TrimSpace(c))
+				if err != nil || n < 100 || n > 599 {
+					return Rule{}, fmt.Errorf("macro: status inválido em on=: %q", c)
+				}
+				r.On = append(r.On, n)
+			}
+		case "run":
+			r.Run = v
+		default:
+			return Rule{}, fmt.Errorf("macro: chave desconhecida na regra: %q", k)
+		}
+	}
+	if len(r.Set) == 0 && r.Run == "" {
+		return Rule{}, fmt.Errorf("macro: regra sem header:/cookie:/param: nem run=")
+	}
+	if len(r.On) > 0 && r.Run == "" {
+		return Rule{}, fmt.Errorf("macro: on= precisa de run=MACRO")
+	}
+	if r.Run != "" && len(r.On) == 0 {
+		r.On = []int{http.StatusUnauthorized}
+	}
+	return r, nil
+}
+
+func (r Rule) String() string {
+	parts := []string{"match=" + r.Match}
+	for _, s := range r.Set {
+		parts = append(parts, s.String())
+	}
+	if r.Run != "" {
+		on := make([]string, len(r.On))
+		for i, n := range r.On {
+			on[i] = strconv.Itoa(n)
+		}
+		parts = append(parts, "on="+strings.Join(on, ","), "run="+r.Run)
+	}
+	return strings.Join(parts, "|")
+}
+
+func (r Rule) Matches(urlStr string) bool {
+	return r.Match == "" || strings.Contains(urlStr, r.Match)
+}
+
+func (r Rule) triggers(status int) bool {
+	if r.Run == "" {
+		return false
+	}
+	for _, n := range r.On {
+		if n == status {
+			return true
+		}
+	}
+	return false
+}
+
+func applyRequest(req *http.Request, s Setter, value string) {
+	switch s.Kind {
+	case SetHeader:
+		req.Header.Set(s.Name, value)
+	case SetCookie:
+		req.Header.Set("Cookie", setCookie(strings.Join(req.Header.Values("Cookie"), "; "), s.Name, value))
+	case SetParam:
+		if req.URL != nil {
+			if q, ok := replaceParam(req.URL.RawQuery, s.Name, value); ok {
+				req.URL.RawQuery = q
+			}
+		}
+	}
+}
+
+func setCookie(header, name, value string) string {
+	var out []string
+	found := false
+	for _, p := range strings.Split(header, ";") {
+		p = strings.TrimSpace(p)
+		if p == "" {
+			continue
+		}
+		k, _, _ := strings.Cut(p, "=")
+		if strings.TrimSpace(k) == name {
+			if !found {
+				out = append(out, name+"="+value)
+			}
+			found = true
+			continue
+		}
+		out = append(out, p)
+	}
+	if !found {
+		out = append(out, name+"="+value)
+	}
+	return strings.Join(out, "; ")
+}
+
+func replaceParam(encoded, name, value string) (string, bool) {
+	if encoded == "" {
+		return encoded, false
+	}
+	parts := strings.Split(encoded, "&")
+	found := false
+	for i, p := range parts {
+		k, _, _ := strings.Cut(p, "=")
+		if dk, err := url.QueryUnescape(k); err == nil && dk == name {
+			parts[i] = k + "=" + url.QueryEscape(value)
+			found = true
+		}
+	}
+	return strings.Join(parts, "&"), found
+}
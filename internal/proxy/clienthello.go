@@ -0,0 +1,168 @@
+package proxy
+
+import (
+	"bufio"
+	"crypto/tls"
+	"encoding/binary"
+	"strings"
+)
+
+type ClientHello struct {
+	ServerName string
+	ALPN       []string
+	Versions   []string
+}
+
+func (h *ClientHello) String() string {
+	if h == nil {
+		return ""
+	}
+	sni := h.ServerName
+	if sni == "" {
+		sni = "-"
+	}
+	alpn := strings.Join(h.ALPN, ",")
+	if alpn == "" {
+		alpn = "-"
+	}
+	return "SNI: " + sni + " | ALPN: " + alpn + " | versões: " + strings.Join(h.Versions, ",")
+}
+
+func helloFromInfo(info *tls.ClientHelloInfo) *ClientHello {
+	h := &ClientHello{ServerName: info.ServerName, ALPN: append([]string(nil), info.SupportedProtos...)}
+	for _, v := range info.SupportedVersions {
+		if !isGREASE(v) {
+			h.Versions = append(h.Versions, tls.VersionName(v))
+		}
+	}
+	return h
+}
+
+func peekClientHello(r *bufio.Reader) *ClientHello {
+	hdr, err := r.Peek(5)
+	if err != nil || hdr[0] != 0x16 {
+		return nil
+	}
+	n := 5 + int(binary.BigEndian.Uint16(hdr[3:5]))
+	if n > r.Size() {
+		n = r.Size()
+	}
+	rec, err := r.Peek(n)
+	if err != nil && len(rec) < 5 {
+		return nil
+	}
+	return parseClientHello(rec)
+}
+
+type helloReader []byte
+
+func (b *helloReader) skip(n int) bool {
+	if n < 0 || len(*b) < n {
+		return false
+	}
+	*b = (*b)[n:]
+	return true
+}
+
+func (b *helloReader) uint(n int) (int, bool) {
+	if len(*b) < n {
+		return 0, false
+	}
+	v := 0
+	for _, c := range (*b)[:n] {
+		v = v<<8 | int(c)
+	}
+	*b = (*b)[n:]
+	return v, true
+}
+
+func (b *helloReader) vector(lenBytes int) (helloReader, bool) {
+	n, ok := b.uint(lenBytes)
+	if !ok || len(*b) < n {
+		return nil, false
+	}
+	v := (*b)[:n]
+	*b = (*b)[n:]
+	return v, true
+}
+
+func parseClientHello(rec []byte) *ClientHello {
+	b := helloReader(rec)
+	if t, ok := b.uint(1); !ok || t != 0x16 {
+		return nil
+	}
+	if !b.skip(4) {
+		return nil
+	}
+	if t, ok := b.uint(1); !ok || t != 0x01 {
+		return nil
+	}
+	if !b.skip(3) {
+		return nil
+	}
+	legacy, ok := b.uint(2)
+	if !ok || !b.skip(32) {
+		return nil
+	}
+	h := &ClientHello{}
+	if _, ok := b.vector(1); !ok {
+		return nil
+	}
+	if _, ok := b.vector(2); !ok {
+		return nil
+	}
+	if _, ok := b.vector(1); !ok {
+		return nil
+	}
+	exts, ok := b.vector(2)
+	if !ok {
+		exts = b
+	}
+
+	for len(exts) >= 4 {
+		typ, _ := exts.uint(2)
+		data, ok := exts.vector(2)
+		if !ok {
+			break
+		}
+		switch typ {
+		case 0:
+			list, _ := data.vector(2)
+			for len(list) > 0 {
+				kind, _ := list.uint(1)
+				name, ok := list.vector(2)
+				if !ok {
+					break
+				}
+				if kind == 0 {
+					h.ServerName = string(name)
+				}
+			}
+		case 16:
+			list, _ := data.vector(2)
+			for len(list) > 0 {
+				proto, ok := list.vector(1)
+				if !ok {
+					break
+				}
+				h.ALPN = append(h.ALPN, string(proto))
+			}
+		case 43:
+			list, _ := data.vector(1)
+			for len(list) >= 2 {
+				v, _ := list.uint(2)
+				if !isGREASE(uint16(v)) {
+					h.Versions = append(h.Versions, tls.VersionName(uint16(v)))
+				}
+			}
+		}
+	}
+	if len(h.Versions) == 0 {
+		h.Versions = []string{tls.VersionName(uint16(legacy))}
+	}
+	return h
+}
+
+func isGREASE(v uint16) bool {
+	return v&0x0f0f == 0x0a0a && v>>8 == v&0xff
+}
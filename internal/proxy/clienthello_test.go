@@ -0,0 +1,78 @@
+package proxy
+
+import (
+	"bufio"
+	"crypto/tls"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPeekClientHello_ParsesSNIAndALPN(t *testing.T) {
+	client, server := net.Pipe()
+	defer server.Close()
+	go func() {
+		tc := tls.Client(client, &tls.Config{ServerName: "api.example.com", NextProtos: []string{"h2", "http/1.1"}, MinVersion: tls.VersionTLS12})
+		_ = tc.Handshake()
+		_ = client.Close()
+	}()
+
+	br := bufio.NewReaderSize(server, 16<<10)
+	h := peekClientHello(br)
+	if h == nil {
+		t.Fatalf("no ClientHello parsed")
+	}
+	if h.ServerName != "api.example.com" {
+		t.Fatalf("unexpected SNI %q", h.ServerName)
+	}
+	if strings.Join(h.ALPN, ",") != "h2,http/1.1" {
+		t.Fatalf("unexpected ALPN %v", h.ALPN)
+	}
+	if strings.Join(h.Versions, ",") != "TLS 1.3,TLS 1.2" {
+		t.Fatalf("unexpected versions %v", h.Versions)
+	}
+	if b, _ := br.Peek(1); len(b) == 0 || b[0] != 0x16 {
+		t.Fatalf("peek must not consume the record")
+	}
+}
+
+func TestHandleConnectMITM_RecordsHandshakeFailure(t *testing.T) {
+	ctrl := NewController()
+	flowCh := make(chan *FlowSnapshot, 16)
+	p, err := New(Config{MaxBodyBytes: 1024, MITM: true, CADir: t.TempDir()}, ctrl, flowCh)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	srv := httptest.NewServer(p)
+	defer srv.Close()
+
+	proxyURL, _ := url.Parse(srv.URL)
+	client := &http.Client{Transport: &http.Transport{Proxy: http.ProxyURL(proxyURL)}, Timeout: 5 * time.Second}
+	if _, err := client.Get("https://pinned.example.com/"); err == nil {
+		t.Fatalf("expected certificate error without trusting the CA")
+	}
+
+	deadline := time.After(2 * time.Second)
+	for {
+		select {
+		case snap := <-flowCh:
+			f := snap.Flow
+			if f.Method != http.MethodConnect || f.Pending {
+				continue
+			}
+			if !strings.Contains(f.Error, "handshake TLS") {
+				t.Fatalf("expected handshake error, got %q", f.Error)
+			}
+			if f.ClientHello == nil || f.ClientHello.ServerName != "pinned.example.com" {
+				t.Fatalf("expected ClientHello with SNI, got %+v", f.ClientHello)
+			}
+			return
+		case <-deadline:
+			t.Fatalf("no CONNECT flow emitted")
+		}
+	}
+}
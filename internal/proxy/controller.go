@@ -12,6 +12,20 @@ type Controller struct {
 	mu          sync.RWMutex
 	nextRuleID  atomic.Int64
 	breakpoints []BreakpointRule
+	mapLocal    []MapLocalRule
+	mapRemote   []MapRemoteRule
+	mocks       []MockRule
+	session     SessionHandler
+
+	throttleMode     string
+	throttleOrder    []string
+	throttleProfiles map[string]ThrottleProfile
+	throttleRules    []ThrottleRule
+	throttleGlobal   map[string]*rateLimiter
+
+	passthrough      []PassthroughRule
+	passthroughAfter int
+	tlsFailures      map[string]int
 }
 
 type BreakpointRule struct {
@@ -21,7 +35,11 @@ type BreakpointRule struct {
 }
 
 func NewController() *Controller {
-	return &Controller{}
+	c := &Controller{passthroughAfter: DefaultPassthroughAfter}
+	for _, p := range DefaultThrottleProfiles() {
+		c.SetThrottleProfile(p)
+	}
+	return c
 }
 
 func (c *Controller) InterceptEnabled() bool {
@@ -25,7 +25,17 @@ type Flow struct {
 	Duration       time.Duration
 	Method         string
 	URL            string
+	MappedTo       string
 	Host           string
+	ClientAddr     string
+	RemoteAddr     string
+	ConnReused     bool
+	TLSVersion     string
+	TLSCipher      string
+	ALPN           string
+	UpstreamCerts  []CertInfo
+	ClientHello    *ClientHello
+	Timing         Timing
 	RequestHeader  http.Header
 	RequestBody    []byte
 	ReqTruncated   bool
@@ -36,8 +46,18 @@ type Flow struct {
 	RespTruncated  bool
 	Error          string
 	Intercepted    bool
+	MockID         int64
+	Throttle       string
+	Tunnel         bool
+	Passthrough    bool
+	Session        string
+	BytesSent      int64
+	BytesReceived  int64
 	Pending        bool
 	actionCh       chan Action
+	throttle       *throttleSession
+	conn           *connThrottle
+	firstByteAt    time.Time
 }
 
 type FlowSnapshot struct {
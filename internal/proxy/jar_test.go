@@ -0,0 +1,34 @@
+package proxy
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"burpui/internal/jar"
+)
+
+func TestWriteResponseStoresCookies(t *testing.T) {
+	j := jar.New()
+	p := &Proxy{cfg: Config{MaxBodyBytes: 1024, Jar: j}, ctrl: NewController()}
+	flow := newFlow()
+	flow.URL = "http://app.test/login"
+	resp := &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Set-Cookie": {"sid=abc; Path=/"}},
+		Body:       io.NopCloser(strings.NewReader("ok")),
+	}
+	rec := httptest.NewRecorder()
+	p.writeResponse(rec, resp, flow)
+
+	u, _ := url.Parse("http://app.test/home")
+	if got := j.Header(u); got != "sid=abc" {
+		t.Fatalf("jar header = %q", got)
+	}
+	if rec.Header().Get("Set-Cookie") != "sid=abc; Path=/" {
+		t.Fatalf("Set-Cookie not forwarded: %v", rec.Header())
+	}
+}
@@ -0,0 +1,218 @@
+package proxy
+
+import (
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+type MapLocalRule struct {
+	ID      int64
+	Enabled bool
+	Match   string
+	Path    string
+}
+
+type MapRemoteRule struct {
+	ID           int64
+	Enabled      bool
+	Match        string
+	To           string
+	PreserveHost bool
+}
+
+func (c *Controller) AddMapLocal(match, localPath string) MapLocalRule {
+	r := MapLocalRule{ID: c.nextRuleID.Add(1), Enabled: true, Match: strings.TrimSpace(match), Path: strings.TrimSpace(localPath)}
+	c.mu.Lock()
+	c.mapLocal = append(c.mapLocal, r)
+	c.mu.Unlock()
+	return r
+}
+
+func (c *Controller) ListMapLocal() []MapLocalRule {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	out := make([]MapLocalRule, len(c.mapLocal))
+	copy(out, c.mapLocal)
+	return out
+}
+
+func (c *Controller) RemoveMapLocal(id int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for i := range c.mapLocal {
+		if c.mapLocal[i].ID == id {
+			c.mapLocal = append(c.mapLocal[:i], c.mapLocal[i+1:]...)
+			return
+		}
+	}
+}
+
+func (c *Controller) AddMapRemote(match, to string, preserveHost bool) (MapRemoteRule, error) {
+	to = strings.TrimSpace(to)
+	u, err := url.Parse(to)
+	if err != nil {
+		return MapRemoteRule{}, err
+	}
+	if u.Scheme == "" && u.Host == "" {
+		return MapRemoteRule{}, fmt.Errorf("map remote: destino sem scheme/host: %q", to)
+	}
+	r := MapRemoteRule{ID: c.nextRuleID.Add(1), Enabled: true, Match: strings.TrimSpace(match), To: to, PreserveHost: preserveHost}
+	c.mu.Lock()
+	c.mapRemote = append(c.mapRemote, r)
+	c.mu.Unlock()
+	return r, nil
+}
+
+func (c *Controller) ListMapRemote() []MapRemoteRule {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	out := make([]MapRemoteRule, len(c.mapRemote))
+	copy(out, c.mapRemote)
+	return out
+}
+
+func (c *Controller) RemoveMapRemote(id int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for i := range c.mapRemote {
+		if c.mapRemote[i].ID == id {
+			c.mapRemote = append(c.mapRemote[:i], c.mapRemote[i+1:]...)
+			return
+		}
+	}
+}
+
+func (c *Controller) matchMapLocal(urlStr string) (MapLocalRule, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	for _, r := range c.mapLocal {
+		if r.Enabled && matchURL(r.Match, urlStr) {
+			return r, true
+		}
+	}
+	return MapLocalRule{}, false
+}
+
+func (c *Controller) matchMapRemote(urlStr string) (MapRemoteRule, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	for _, r := range c.mapRemote {
+		if r.Enabled && matchURL(r.Match, urlStr) {
+			return r, true
+		}
+	}
+	return MapRemoteRule{}, false
+}
+
+func (r MapLocalRule) resolve(u *url.URL) string {
+	fi, err := os.Stat(r.Path)
+	if err != nil || !fi.IsDir() {
+		return r.Path
+	}
+
+	rest := u.Path
+	if tail, ok := trimURLPrefix(r.Match, u.String()); ok {
+		rest = tail
+		if i := strings.IndexAny(rest, "?#"); i >= 0 {
+			rest = rest[:i]
+		}
+		if unescaped, err := url.PathUnescape(rest); err == nil {
+			rest = unescaped
+		}
+	}
+	rest = path.Clean("/" + rest)
+	if rest == "/" || strings.HasSuffix(u.Path, "/") {
+		rest = path.Join(rest, "index.html")
+	}
+	return filepath.Join(r.Path, filepath.FromSlash(rest))
+}
+
+func (r MapLocalRule) response(req *http.Request) (*http.Response, string) {
+	file := r.resolve(req.URL)
+	h := http.Header{}
+	b, err := os.ReadFile(file)
+	if err != nil {
+		h.Set("Content-Type", "text/plain; charset=utf-8")
+		return newResponse(req, http.StatusNotFound, h, []byte(fmt.Sprintf("map local: %s\n", err.Error()))), file
+	}
+	ct := mime.TypeByExtension(filepath.Ext(file))
+	if ct == "" {
+		ct = http.DetectContentType(b)
+	}
+	h.Set("Content-Type", ct)
+	return newResponse(req, http.StatusOK, h, b), file
+}
+
+func (r MapRemoteRule) rewrite(u *url.URL) (*url.URL, error) {
+	if tail, ok := trimURLPrefix(r.Match, u.String()); ok {
+		to := r.To
+		if tail != "" && strings.HasSuffix(strings.TrimSuffix(r.Match, "*"), "/") && !strings.HasSuffix(to, "/") {
+			to += "/"
+		}
+		return url.Parse(to + tail)
+	}
+
+	to, err := url.Parse(r.To)
+	if err != nil {
+		return nil, err
+	}
+	out := cloneURL(u)
+	if to.Scheme != "" {
+		out.Scheme = to.Scheme
+	}
+	if to.Host != "" {
+		out.Host = to.Host
+	}
+	if to.Path != "" && to.Path != "/" {
+		out.Path = to.Path
+		out.RawPath = to.RawPath
+	}
+	if to.RawQuery != "" {
+		out.RawQuery = to.RawQuery
+	}
+	return out, nil
+}
+
+func (p *Proxy) applyMapRemote(req *http.Request, flow *Flow) {
+	if req.URL == nil {
+		return
+	}
+	rule, ok := p.ctrl.matchMapRemote(req.URL.String())
+	if !ok {
+		return
+	}
+	u, err := rule.rewrite(req.URL)
+	if err != nil {
+		flow.Error = "map remote: " + err.Error()
+		return
+	}
+	req.URL = u
+	if !rule.PreserveHost {
+		req.Host = u.Host
+	}
+	flow.MappedTo = u.String()
+}
+
+func (p *Proxy) mapLocalResponse(req *http.Request, flow *Flow) (*http.Response, bool) {
+	if req.URL == nil {
+		return nil, false
+	}
+	rule, ok := p.ctrl.matchMapLocal(req.URL.String())
+	if !ok {
+		return nil, false
+	}
+	if req.Body != nil {
+		_, _ = io.Copy(io.Discard, req.Body)
+		_ = req.Body.Close()
+	}
+	resp, file := rule.response(req)
+	flow.MappedTo = "file:" + file
+	return resp, true
+}
@@ -0,0 +1,68 @@
+package proxy
+
+import (
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMatchURL(t *testing.T) {
+	cases := []struct {
+		pattern string
+		url     string
+		want    bool
+	}{
+		{"https://example.com/app.js", "https://example.com/app.js", true},
+		{"https://example.com/*", "https://example.com/a/b?x=1", true},
+		{"example.com/*.js", "https://example.com/static/app.js", true},
+		{"*.example.com/*", "https://api.example.com/v1", true},
+		{"https://example.com/*", "http://example.com/a", false},
+		{"example.com/*.js", "https://example.com/app.css", false},
+	}
+	for _, c := range cases {
+		if got := matchURL(c.pattern, c.url); got != c.want {
+			t.Fatalf("matchURL(%q, %q) = %v, want %v", c.pattern, c.url, got, c.want)
+		}
+	}
+}
+
+func TestMapRemoteRewrite_Prefix(t *testing.T) {
+	r := MapRemoteRule{Match: "https://api.example.com/v1/*", To: "http://staging.local:8080/v2"}
+	u, _ := url.Parse("https://api.example.com/v1/users?id=3")
+	got, err := r.rewrite(u)
+	if err != nil {
+		t.Fatalf("rewrite: %v", err)
+	}
+	if got.String() != "http://staging.local:8080/v2/users?id=3" {
+		t.Fatalf("unexpected url %q", got.String())
+	}
+}
+
+func TestMapRemoteRewrite_Components(t *testing.T) {
+	r := MapRemoteRule{Match: "*api.example.com*", To: "https://staging.example.com"}
+	u, _ := url.Parse("http://api.example.com/v1/users")
+	got, err := r.rewrite(u)
+	if err != nil {
+		t.Fatalf("rewrite: %v", err)
+	}
+	if got.String() != "https://staging.example.com/v1/users" {
+		t.Fatalf("unexpected url %q", got.String())
+	}
+}
+
+func TestMapLocalResolve_Directory(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "js"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	r := MapLocalRule{Match: "https://example.com/static/*", Path: dir}
+	u, _ := url.Parse("https://example.com/static/js/app.js?v=2")
+	if got, want := r.resolve(u), filepath.Join(dir, "js", "app.js"); got != want {
+		t.Fatalf("resolve = %q, want %q", got, want)
+	}
+	u, _ = url.Parse("https://example.com/static/../../etc/passwd")
+	if got, want := r.resolve(u), filepath.Join(dir, "etc", "passwd"); got != want {
+		t.Fatalf("resolve = %q, want %q", got, want)
+	}
+}
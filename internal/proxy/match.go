@@ -0,0 +1,58 @@
+package proxy
+
+import "strings"
+
+func GlobMatch(pattern, s string) bool {
+	pattern = strings.ToLower(strings.TrimSpace(pattern))
+	s = strings.ToLower(s)
+	if pattern == "" {
+		return false
+	}
+
+	parts := strings.Split(pattern, "*")
+	if len(parts) == 1 {
+		return s == pattern
+	}
+	if !strings.HasPrefix(s, parts[0]) {
+		return false
+	}
+	s = s[len(parts[0]):]
+	last := parts[len(parts)-1]
+	for _, part := range parts[1 : len(parts)-1] {
+		if part == "" {
+			continue
+		}
+		i := strings.Index(s, part)
+		if i < 0 {
+			return false
+		}
+		s = s[i+len(part):]
+	}
+	return strings.HasSuffix(s, last)
+}
+
+func matchURL(pattern, urlStr string) bool {
+	if GlobMatch(pattern, urlStr) {
+		return true
+	}
+	if i := strings.Index(urlStr, "://"); i >= 0 && !strings.Contains(pattern, "://") {
+		return GlobMatch(pattern, urlStr[i+3:])
+	}
+	return false
+}
+
+func trimURLPrefix(pattern, urlStr string) (string, bool) {
+	pattern = strings.TrimSpace(pattern)
+	if !strings.HasSuffix(pattern, "*") || strings.Count(pattern, "*") != 1 {
+		return "", false
+	}
+	prefix := strings.TrimSuffix(pattern, "*")
+	s := urlStr
+	if i := strings.Index(s, "://"); i >= 0 && !strings.Contains(prefix, "://") {
+		s = s[i+3:]
+	}
+	if len(s) < len(prefix) || !strings.EqualFold(s[:len(prefix)], prefix) {
+		return "", false
+	}
+	return s[len(prefix):], true
+}
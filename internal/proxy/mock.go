@@ -0,0 +1,214 @@
+package proxy
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"text/template"
+	"time"
+
+	"burpui/internal/httpraw"
+)
+
+type MockRule struct {
+	ID       int64
+	Enabled  bool
+	Match    string
+	Method   string
+	Status   int
+	Header   http.Header
+	Body     string
+	Template bool
+	Delay    time.Duration
+	FromFlow int64
+
+	tmpl *template.Template
+}
+
+type mockRequest struct {
+	Method string
+	URL    string
+	Host   string
+	Path   string
+	Query  url.Values
+	Header http.Header
+	Body   string
+}
+
+func MockFromFlow(f *Flow) (MockRule, error) {
+	if f.RespTruncated {
+		return MockRule{}, fmt.Errorf("mock: resposta truncada em --max-body, aumente o limite")
+	}
+	status := f.StatusCode
+	if status == 0 {
+		status = http.StatusOK
+	}
+	h := cleanHopByHopHeaders(cloneHeader(f.ResponseHeader))
+	body := f.ResponseBody
+	if h != nil {
+		h.Del("Content-Length")
+		if b, err := httpraw.DecodeBody(h, body); err == nil {
+			body = b
+			h.Del("Content-Encoding")
+		}
+	}
+	return MockRule{
+		Enabled:  true,
+		Match:    f.URL,
+		Method:   f.Method,
+		Status:   status,
+		Header:   h,
+		Body:     string(body),
+		FromFlow: f.ID,
+	}, nil
+}
+
+func (c *Controller) AddMock(r MockRule) (MockRule, error) {
+	r.Match = strings.TrimSpace(r.Match)
+	r.Method = strings.ToUpper(strings.TrimSpace(r.Method))
+	if r.Match == "" {
+		return MockRule{}, fmt.Errorf("mock: match vazio")
+	}
+	if r.Status == 0 {
+		r.Status = http.StatusOK
+	}
+	if r.Template {
+		t, err := template.New("mock").Option("missingkey=zero").Parse(r.Body)
+		if err != nil {
+			return MockRule{}, fmt.Errorf("mock: template: %w", err)
+		}
+		r.tmpl = t
+	}
+	r.ID = c.nextRuleID.Add(1)
+	c.mu.Lock()
+	c.mocks = append(c.mocks, r)
+	c.mu.Unlock()
+	return r, nil
+}
+
+func (c *Controller) ListMocks() []MockRule {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	out := make([]MockRule, len(c.mocks))
+	copy(out, c.mocks)
+	return out
+}
+
+func (c *Controller) ToggleMock(id int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for i := range c.mocks {
+		if c.mocks[i].ID == id {
+			c.mocks[i].Enabled = !c.mocks[i].Enabled
+			return
+		}
+	}
+}
+
+func (c *Controller) RemoveMock(id int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for i := range c.mocks {
+		if c.mocks[i].ID == id {
+			c.mocks = append(c.mocks[:i], c.mocks[i+1:]...)
+			return
+		}
+	}
+}
+
+func (c *Controller) matchMock(method, urlStr string) (MockRule, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	for _, r := range c.mocks {
+		if !r.Enabled {
+			continue
+		}
+		if r.Method != "" && !strings.EqualFold(r.Method, method) {
+			continue
+		}
+		if matchURL(r.Match, urlStr) {
+			return r, true
+		}
+	}
+	return MockRule{}, false
+}
+
+func (r MockRule) render(req *http.Request, body []byte) ([]byte, error) {
+	if r.tmpl == nil {
+		return []byte(r.Body), nil
+	}
+	data := mockRequest{
+		Method: req.Method,
+		URL:    req.URL.String(),
+		Host:   req.Host,
+		Path:   req.URL.Path,
+		Query:  req.URL.Query(),
+		Header: req.Header,
+		Body:   string(body),
+	}
+	if data.Host == "" {
+		data.Host = req.URL.Host
+	}
+	var b bytes.Buffer
+	if err := r.tmpl.Execute(&b, data); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+func (p *Proxy) mockResponse(req *http.Request, flow *Flow) (*http.Response, bool, error) {
+	if req.URL == nil {
+		return nil, false, nil
+	}
+	rule, ok := p.ctrl.matchMock(req.Method, req.URL.String())
+	if !ok {
+		return nil, false, nil
+	}
+
+	var reqBody []byte
+	if req.Body != nil {
+		lb := NewLimitBuffer(p.cfg.MaxBodyBytes)
+		_, _ = io.Copy(lb, req.Body)
+		_ = req.Body.Close()
+		reqBody = lb.Bytes()
+	}
+
+	if rule.Delay > 0 {
+		t := time.NewTimer(rule.Delay)
+		select {
+		case <-t.C:
+		case <-req.Context().Done():
+			t.Stop()
+			return nil, true, req.Context().Err()
+		}
+	}
+
+	flow.MockID = rule.ID
+	body, err := rule.render(req, reqBody)
+	if err != nil {
+		return nil, true, fmt.Errorf("mock #%d: %w", rule.ID, err)
+	}
+	return newResponse(req, rule.Status, rule.Header, body), true, nil
+}
+
+func newResponse(req *http.Request, status int, header http.Header, body []byte) *http.Response {
+	h := cloneHeader(header)
+	if h == nil {
+		h = http.Header{}
+	}
+	h.Set("Content-Length", fmt.Sprintf("%d", len(body)))
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", status, http.StatusText(status)),
+		StatusCode:    status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        h,
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}
+}
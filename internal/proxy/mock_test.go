@@ -0,0 +1,81 @@
+package proxy
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestMockTemplateRender(t *testing.T) {
+	c := NewController()
+	if _, err := c.AddMock(MockRule{Enabled: true, Match: "*/users/*", Method: "post", Status: 201, Body: `{"path":"{{.Path}}","id":"{{.Query.Get "id"}}","echo":{{.Body}}}`, Template: true}); err != nil {
+		t.Fatalf("AddMock: %v", err)
+	}
+
+	req, _ := http.NewRequest(http.MethodPost, "http://api.example.com/users/new?id=7", strings.NewReader(`{"a":1}`))
+	p := &Proxy{cfg: Config{MaxBodyBytes: 1024}, ctrl: c}
+	flow := newFlow()
+	resp, ok, err := p.mockResponse(req, flow)
+	if !ok || err != nil {
+		t.Fatalf("expected mock match, ok=%v err=%v", ok, err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != 201 {
+		t.Fatalf("expected 201, got %d", resp.StatusCode)
+	}
+	if string(body) != `{"path":"/users/new","id":"7","echo":{"a":1}}` {
+		t.Fatalf("unexpected body %s", body)
+	}
+	if flow.MockID == 0 {
+		t.Fatalf("expected MockID to be set")
+	}
+}
+
+func TestMockMethodFilter(t *testing.T) {
+	c := NewController()
+	if _, err := c.AddMock(MockRule{Enabled: true, Match: "*", Method: "DELETE"}); err != nil {
+		t.Fatalf("AddMock: %v", err)
+	}
+	if _, ok := c.matchMock("GET", "http://example.com/"); ok {
+		t.Fatalf("GET should not match DELETE mock")
+	}
+	if _, ok := c.matchMock("delete", "http://example.com/"); !ok {
+		t.Fatalf("DELETE should match")
+	}
+}
+
+func TestMockFromFlowDecodesBody(t *testing.T) {
+	var gz bytes.Buffer
+	w := gzip.NewWriter(&gz)
+	w.Write([]byte(`{"ok":true}`))
+	w.Close()
+
+	f := newFlow()
+	f.URL = "http://api.example.com/x"
+	f.Method = "GET"
+	f.StatusCode = 200
+	f.ResponseHeader = http.Header{"Content-Encoding": {"gzip"}, "Content-Type": {"application/json"}, "Content-Length": {"31"}}
+	f.ResponseBody = gz.Bytes()
+	r, err := MockFromFlow(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Body != `{"ok":true}` || r.Header.Get("Content-Encoding") != "" || r.Header.Get("Content-Length") != "" {
+		t.Fatalf("mock = %q %v", r.Body, r.Header)
+	}
+
+	f.ResponseHeader = http.Header{"Content-Encoding": {"br"}}
+	f.ResponseBody = []byte{0x1b, 0x02}
+	r, err = MockFromFlow(f)
+	if err != nil || r.Header.Get("Content-Encoding") != "br" || r.Body != "\x1b\x02" {
+		t.Fatalf("br mock = %q %v %v", r.Body, r.Header, err)
+	}
+
+	f.RespTruncated = true
+	if _, err := MockFromFlow(f); err == nil {
+		t.Fatalf("expected error for truncated body")
+	}
+}
@@ -0,0 +1,278 @@
+package proxy
+
+import (
+	"bytes"
+	"crypto/x509"
+	"encoding/base64"
+	"fmt"
+	"html/template"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"burpui/internal/ca"
+)
+
+type onboardingPage struct {
+	Name        string
+	SHA1        string
+	SHA256      string
+	NotAfter    string
+	MITM        bool
+	ClientAddr  string
+	Scheme      string
+	RequestedAt string
+	Error       string
+}
+
+var onboardingTmpl = template.Must(template.New("onboarding").Parse(`<!doctype html>
+<html lang="pt-BR">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>burpui</title>
+<style>
+body{font-family:system-ui,sans-serif;max-width:720px;margin:2em auto;padding:0 1em;color:#222}
+code{background:#f2f2f2;padding:.1em .3em;word-break:break-all}
+.ok{color:#0a7d28;font-weight:bold}.warn{color:#b45309;font-weight:bold}
+a.btn{display:inline-block;margin:.2em .4em .2em 0;padding:.4em .8em;border:1px solid #888;border-radius:4px;text-decoration:none;color:#222}
+h2{margin-top:1.6em}
+</style>
+</head>
+<body>
+{{define "test"}}
+<h1>burpui: teste de conexão</h1>
+<p class="ok">OK: esta página foi servida pelo proxy burpui.</p>
+<p>O dispositivo está passando pelo proxy ({{.Scheme}}).</p>
+<ul>
+<li>Cliente: <code>{{.ClientAddr}}</code></li>
+<li>Horário do proxy: <code>{{.RequestedAt}}</code></li>
+</ul>
+{{if eq .Scheme "https"}}<p class="ok">HTTPS sem aviso do navegador = CA instalado e confiável.</p>
+{{else if .MITM}}<p>Para conferir o CA, abra <a href="https://burpui.local/test">https://burpui.local/test</a>. Se abrir sem aviso de certificado, o CA está confiável.</p>
+{{else}}<p class="warn">MITM desligado: HTTPS passa em túnel, sem decodificar (rode com <code>--mitm</code>).</p>{{end}}
+<p><a href="/">voltar</a></p>
+{{end}}
+{{define "index"}}
+<h1>burpui</h1>
+{{if .Error}}<p class="warn">CA indisponível: {{.Error}}</p>{{else}}
+<p>Para ver tráfego HTTPS, instale e confie no CA <b>{{.Name}}</b> neste dispositivo.</p>
+<p>
+<a class="btn" href="/ca.pem">PEM</a>
+<a class="btn" href="/ca.crt">DER (.crt)</a>
+<a class="btn" href="/ca.mobileconfig">iOS/macOS (.mobileconfig)</a>
+<a class="btn" href="/test">teste de conexão</a>
+</p>
+<ul>
+<li>SHA-1: <code>{{.SHA1}}</code></li>
+<li>SHA-256: <code>{{.SHA256}}</code></li>
+<li>Válido até: <code>{{.NotAfter}}</code></li>
+</ul>
+<p>Confira a impressão digital mostrada pelo sistema antes de confiar.</p>
+{{if not .MITM}}<p class="warn">O proxy está sem <code>--mitm</code>: o CA só é usado depois de reiniciar com MITM.</p>{{end}}
+
+<h2>Windows</h2>
+<ol>
+<li>Baixe o <a href="/ca.crt">.crt</a> e abra.</li>
+<li>Instalar Certificado → Usuário Atual → “Colocar todos os certificados no repositório a seguir” → Autoridades de Certificação Raiz Confiáveis.</li>
+<li>Ou, no terminal: <code>burpui --install-ca</code>.</li>
+</ol>
+
+<h2>macOS</h2>
+<ol>
+<li>Baixe o <a href="/ca.mobileconfig">.mobileconfig</a> e abra; em Ajustes do Sistema → Privacidade e Segurança → Perfis, instale.</li>
+<li>Ou importe o <a href="/ca.pem">PEM</a> no Acesso às Chaves (login) e marque “Sempre Confiar”.</li>
+<li>Ou, no terminal: <code>burpui --install-ca</code>.</li>
+</ol>
+
+<h2>iOS / iPadOS</h2>
+<ol>
+<li>Abra esta página no Safari e toque em <a href="/ca.mobileconfig">.mobileconfig</a>.</li>
+<li>Ajustes → Perfil Transferido → Instalar.</li>
+<li>Ajustes → Geral → Sobre → Ajustes de Confiança de Certificados → ative o CA <b>{{.Name}}</b>.</li>
+</ol>
+
+<h2>Android</h2>
+<ol>
+<li>Baixe o <a href="/ca.crt">.crt</a>.</li>
+<li>Configurações → Segurança → Criptografia e credenciais → Instalar certificado → Certificado CA.</li>
+<li>Apps com Android 7+ só confiam em CAs de usuário se o <code>network_security_config</code> permitir.</li>
+</ol>
+
+<h2>Linux</h2>
+<ol>
+<li><code>burpui --install-ca</code> (âncoras da distro + NSS do Chromium/Firefox).</li>
+<li>Manual: copie o <a href="/ca.pem">PEM</a> para <code>/usr/local/share/ca-certificates/burpui.crt</code> e rode <code>sudo update-ca-certificates</code>.</li>
+</ol>
+
+<h2>Firefox</h2>
+<ol>
+<li>Configurações → Privacidade e Segurança → Certificados → Ver certificados → Autoridades → Importar o <a href="/ca.pem">PEM</a>.</li>
+<li>Marque “Confiar neste CA para identificar sites”.</li>
+</ol>
+{{end}}
+{{end}}
+{{if .RequestedAt}}{{template "test" .}}{{else}}{{template "index" .}}{{end}}
+</body>
+</html>
+`))
+
+var mobileconfigTmpl = template.Must(template.New("mobileconfig").Parse(`<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+	<key>PayloadContent</key>
+	<array>
+		<dict>
+			<key>PayloadCertificateFileName</key>
+			<string>burpui-ca.cer</string>
+			<key>PayloadContent</key>
+			<data>{{.Data}}</data>
+			<key>PayloadDescription</key>
+			<string>CA raiz do proxy burpui</string>
+			<key>PayloadDisplayName</key>
+			<string>{{.Name}}</string>
+			<key>PayloadIdentifier</key>
+			<string>local.burpui.ca.{{.ID}}</string>
+			<key>PayloadType</key>
+			<string>com.apple.security.root</string>
+			<key>PayloadUUID</key>
+			<string>{{.CertUUID}}</string>
+			<key>PayloadVersion</key>
+			<integer>1</integer>
+		</dict>
+	</array>
+	<key>PayloadDisplayName</key>
+	<string>{{.Name}}</string>
+	<key>PayloadIdentifier</key>
+	<string>local.burpui.profile.{{.ID}}</string>
+	<key>PayloadRemovalDisallowed</key>
+	<false/>
+	<key>PayloadType</key>
+	<string>Configuration</string>
+	<key>PayloadUUID</key>
+	<string>{{.ProfileUUID}}</string>
+	<key>PayloadVersion</key>
+	<integer>1</integer>
+</dict>
+</plist>
+`))
+
+func isOnboardingHost(host string) bool {
+	host = strings.ToLower(stripPort(host))
+	return host == "burpui.local" || host == "burpui"
+}
+
+func (p *Proxy) caStore() (*ca.Store, error) {
+	p.caMu.Lock()
+	defer p.caMu.Unlock()
+	if p.ca != nil {
+		return p.ca, nil
+	}
+	st, err := ca.LoadOrCreate(p.cfg.CADir)
+	if err != nil {
+		return nil, err
+	}
+	p.ca = st
+	return st, nil
+}
+
+func (p *Proxy) serveOnboarding(w http.ResponseWriter, r *http.Request) {
+	resp := p.onboardingResponse(r, r.RemoteAddr)
+	defer resp.Body.Close()
+	for k, vv := range resp.Header {
+		w.Header()[k] = vv
+	}
+	w.WriteHeader(resp.StatusCode)
+	_, _ = io.Copy(w, resp.Body)
+}
+
+func (p *Proxy) onboardingResponse(req *http.Request, clientAddr string) *http.Response {
+	h := http.Header{}
+	h.Set("Cache-Control", "no-store")
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		h.Set("Allow", "GET, HEAD")
+		return newResponse(req, http.StatusMethodNotAllowed, h, nil)
+	}
+
+	page := onboardingPage{MITM: p.cfg.MITM}
+	st, err := p.caStore()
+	var root *x509.Certificate
+	if err == nil {
+		root, err = x509.ParseCertificate(st.RootCertDER())
+	}
+	if err != nil {
+		page.Error = err.Error()
+	} else {
+		page.Name = root.Subject.CommonName
+		page.SHA1 = fingerprint(st.RootThumbprintSHA1())
+		page.SHA256 = fingerprint(st.RootFingerprintSHA256())
+		page.NotAfter = root.NotAfter.Format("2006-01-02")
+	}
+
+	switch strings.TrimSuffix(req.URL.Path, "/") {
+	case "", "/index.html":
+	case "/test":
+		page.ClientAddr = clientAddr
+		page.Scheme = req.URL.Scheme
+		page.RequestedAt = time.Now().Format("2006-01-02 15:04:05")
+	case "/ca", "/cacert", "/ca.pem":
+		if root == nil {
+			break
+		}
+		h.Set("Content-Type", "application/x-pem-file")
+		h.Set("Content-Disposition", "attachment; filename=burpui-ca.pem")
+		return newResponse(req, http.StatusOK, h, st.RootCertPEM())
+	case "/ca.crt", "/ca.der", "/ca.cer":
+		if root == nil {
+			break
+		}
+		h.Set("Content-Type", "application/x-x509-ca-cert")
+		h.Set("Content-Disposition", "attachment; filename=burpui-ca.crt")
+		return newResponse(req, http.StatusOK, h, root.Raw)
+	case "/ca.mobileconfig":
+		if root == nil {
+			break
+		}
+		sum := st.RootFingerprintSHA256()
+		var b bytes.Buffer
+		_ = mobileconfigTmpl.Execute(&b, map[string]string{
+			"Data":        base64.StdEncoding.EncodeToString(root.Raw),
+			"Name":        page.Name,
+			"ID":          sum[:16],
+			"CertUUID":    uuidFromHex(sum),
+			"ProfileUUID": uuidFromHex(sum[32:]),
+		})
+		h.Set("Content-Type", "application/x-apple-aspen-config")
+		h.Set("Content-Disposition", "attachment; filename=burpui-ca.mobileconfig")
+		return newResponse(req, http.StatusOK, h, b.Bytes())
+	default:
+		h.Set("Content-Type", "text/plain; charset=utf-8")
+		return newResponse(req, http.StatusNotFound, h, []byte("burpui: página não encontrada\n"))
+	}
+
+	var b bytes.Buffer
+	if err := onboardingTmpl.Execute(&b, page); err != nil {
+		h.Set("Content-Type", "text/plain; charset=utf-8")
+		return newResponse(req, http.StatusInternalServerError, h, []byte(err.Error()))
+	}
+	h.Set("Content-Type", "text/html; charset=utf-8")
+	return newResponse(req, http.StatusOK, h, b.Bytes())
+}
+
+func fingerprint(hexSum string) string {
+	var parts []string
+	for i := 0; i+2 <= len(hexSum); i += 2 {
+		parts = append(parts, hexSum[i:i+2])
+	}
+	return strings.Join(parts, ":")
+}
+
+func uuidFromHex(h string) string {
+	h = strings.ToUpper(h)
+	if len(h) < 32 {
+		h += strings.Repeat("0", 32-len(h))
+	}
+	return fmt.Sprintf("%s-%s-%s-%s-%s", h[0:8], h[8:12], h[12:16], h[16:20], h[20:32])
+}
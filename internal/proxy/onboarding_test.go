@@ -0,0 +1,53 @@
+package proxy
+
+import (
+	"crypto/x509"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestOnboarding_ServesCAWithoutMITM(t *testing.T) {
+	flowCh := make(chan *FlowSnapshot, 16)
+	p, err := New(Config{MaxBodyBytes: 1024, CADir: t.TempDir()}, NewController(), flowCh)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	get := func(path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		p.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "http://burpui.local"+path, nil))
+		return rec
+	}
+
+	index := get("/")
+	if index.Code != http.StatusOK || !strings.Contains(index.Body.String(), "SHA-256") {
+		t.Fatalf("unexpected index %d %q", index.Code, index.Body.String())
+	}
+	st, _ := p.caStore()
+	if !strings.Contains(index.Body.String(), fingerprint(st.RootFingerprintSHA256())) {
+		t.Fatalf("index missing sha256 fingerprint")
+	}
+
+	der := get("/ca.crt")
+	if _, err := x509.ParseCertificate(der.Body.Bytes()); err != nil {
+		t.Fatalf("DER download: %v", err)
+	}
+	if pemRec := get("/cacert"); !strings.HasPrefix(pemRec.Body.String(), "-----BEGIN CERTIFICATE-----") {
+		t.Fatalf("PEM download: %q", pemRec.Body.String())
+	}
+	mc := get("/ca.mobileconfig")
+	if mc.Header().Get("Content-Type") != "application/x-apple-aspen-config" || !strings.Contains(mc.Body.String(), "com.apple.security.root") {
+		t.Fatalf("unexpected mobileconfig %q", mc.Body.String())
+	}
+	if test := get("/test"); !strings.Contains(test.Body.String(), "servida pelo proxy") {
+		t.Fatalf("unexpected self-test page %q", test.Body.String())
+	}
+	if get("/nope").Code != http.StatusNotFound {
+		t.Fatalf("expected 404")
+	}
+	if len(flowCh) != 0 {
+		t.Fatalf("onboarding requests should not be recorded as flows")
+	}
+}
@@ -0,0 +1,169 @@
+package proxy
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const DefaultPassthroughAfter = 3
+
+type PassthroughRule struct {
+	ID      int64
+	Enabled bool
+	Match   string
+	Auto    bool
+}
+
+func (c *Controller) AddPassthrough(match string) PassthroughRule {
+	r := PassthroughRule{ID: c.nextRuleID.Add(1), Enabled: true, Match: strings.TrimSpace(match)}
+	c.mu.Lock()
+	c.passthrough = append(c.passthrough, r)
+	c.mu.Unlock()
+	return r
+}
+
+func (c *Controller) ListPassthrough() []PassthroughRule {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	out := make([]PassthroughRule, len(c.passthrough))
+	copy(out, c.passthrough)
+	return out
+}
+
+func (c *Controller) TogglePassthrough(id int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for i := range c.passthrough {
+		if c.passthrough[i].ID == id {
+			c.passthrough[i].Enabled = !c.passthrough[i].Enabled
+			return
+		}
+	}
+}
+
+func (c *Controller) RemovePassthrough(id int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for i := range c.passthrough {
+		if c.passthrough[i].ID == id {
+			c.passthrough = append(c.passthrough[:i], c.passthrough[i+1:]...)
+			return
+		}
+	}
+}
+
+func (c *Controller) SetPassthroughAfter(n int) {
+	c.mu.Lock()
+	c.passthroughAfter = n
+	c.mu.Unlock()
+}
+
+func (c *Controller) shouldPassthrough(host string) bool {
+	host = stripPort(host)
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	for _, r := range c.passthrough {
+		if r.Enabled && GlobMatch(r.Match, host) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *Controller) recordHandshake(host string, ok bool) (added bool) {
+	host = strings.ToLower(stripPort(host))
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if ok {
+		delete(c.tlsFailures, host)
+		return false
+	}
+	if c.passthroughAfter <= 0 {
+		return false
+	}
+	if c.tlsFailures == nil {
+		c.tlsFailures = map[string]int{}
+	}
+	c.tlsFailures[host]++
+	if c.tlsFailures[host] < c.passthroughAfter {
+		return false
+	}
+	delete(c.tlsFailures, host)
+	c.passthrough = append(c.passthrough, PassthroughRule{ID: c.nextRuleID.Add(1), Enabled: true, Match: host, Auto: true})
+	return true
+}
+
+type countingReader struct {
+	r io.Reader
+	n *int64
+}
+
+func (c countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	*c.n += int64(n)
+	return n, err
+}
+
+func (p *Proxy) tunnel(w http.ResponseWriter, r *http.Request, flow *Flow) {
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	ts := p.ctrl.throttleFor(r.Host, connThrottleFrom(r.Context()))
+	flow.Throttle = ts.name()
+	ts.delay()
+
+	dialCtx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	targetConn, err := p.cfg.Resolver.DialContext(dialCtx, "tcp", r.Host)
+	cancel()
+	if err != nil {
+		w.WriteHeader(http.StatusBadGateway)
+		flow.Error = err.Error()
+		flow.Pending = false
+		flow.Duration = time.Since(flow.StartedAt)
+		p.emit(flow)
+		return
+	}
+	flow.RemoteAddr = targetConn.RemoteAddr().String()
+
+	clientConn, buf, err := hijacker.Hijack()
+	if err != nil {
+		_ = targetConn.Close()
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	_, _ = clientConn.Write([]byte("HTTP/1.1 200 Connection Established\r\n\r\n"))
+	p.emit(flow)
+
+	var sent, received int64
+	var hello *ClientHello
+	done := make(chan struct{})
+	go func() {
+		hello = peekClientHello(buf.Reader)
+		_ = copyAndClose(writeCloser{Writer: ts.limitWriter(targetConn), Closer: targetConn}, countingReader{r: buf, n: &sent})
+		close(done)
+	}()
+	if err := copyAndClose(writeCloser{Writer: ts.writer(clientConn), Closer: clientConn}, countingReader{r: targetConn, n: &received}); err != nil && !isClosedConnErr(err) {
+		flow.Error = err.Error()
+	}
+	<-done
+
+	flow.ClientHello = hello
+	flow.BytesSent = sent
+	flow.BytesReceived = received
+	flow.Pending = false
+	flow.Duration = time.Since(flow.StartedAt)
+	p.emit(flow)
+}
+
+func isClosedConnErr(err error) bool {
+	return errors.Is(err, net.ErrClosed) || errors.Is(err, io.EOF)
+}
@@ -0,0 +1,86 @@
+package proxy
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestRecordHandshake_AutoPassthrough(t *testing.T) {
+	c := NewController()
+	c.SetPassthroughAfter(2)
+
+	if c.recordHandshake("pinned.example.com:443", false) {
+		t.Fatalf("added after first failure")
+	}
+	c.recordHandshake("pinned.example.com:443", true)
+	if c.recordHandshake("pinned.example.com:443", false) {
+		t.Fatalf("success should reset the failure count")
+	}
+	if !c.recordHandshake("pinned.example.com:443", false) {
+		t.Fatalf("expected auto passthrough after 2 failures")
+	}
+	if !c.shouldPassthrough("pinned.example.com:443") || c.shouldPassthrough("other.example.com:443") {
+		t.Fatalf("unexpected passthrough match")
+	}
+	rules := c.ListPassthrough()
+	if len(rules) != 1 || !rules[0].Auto {
+		t.Fatalf("unexpected rules %+v", rules)
+	}
+}
+
+func TestHandleConnect_PassthroughTunnelsWithMITM(t *testing.T) {
+	upstream := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("pinned ok"))
+	}))
+	defer upstream.Close()
+
+	ctrl := NewController()
+	ctrl.AddPassthrough("127.0.0.1")
+	flowCh := make(chan *FlowSnapshot, 16)
+	p, err := New(Config{MaxBodyBytes: 1024, MITM: true, CADir: t.TempDir()}, ctrl, flowCh)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	srv := httptest.NewServer(p)
+	defer srv.Close()
+
+	proxyURL, _ := url.Parse(srv.URL)
+	tr := upstream.Client().Transport.(*http.Transport).Clone()
+	tr.Proxy = http.ProxyURL(proxyURL)
+	client := &http.Client{Transport: tr, Timeout: 5 * time.Second}
+
+	resp, err := client.Get(upstream.URL)
+	if err != nil {
+		t.Fatalf("GET through passthrough: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if string(body) != "pinned ok" {
+		t.Fatalf("unexpected body %q", body)
+	}
+	tr.CloseIdleConnections()
+
+	deadline := time.After(2 * time.Second)
+	for {
+		select {
+		case snap := <-flowCh:
+			f := snap.Flow
+			if f.Pending {
+				continue
+			}
+			if !f.Tunnel || !f.Passthrough || f.Method != http.MethodConnect {
+				t.Fatalf("expected passthrough tunnel flow, got %+v", f)
+			}
+			if f.BytesSent == 0 || f.BytesReceived == 0 {
+				t.Fatalf("expected byte counts, got %d/%d", f.BytesSent, f.BytesReceived)
+			}
+			return
+		case <-deadline:
+			t.Fatalf("tunnel flow not finished")
+		}
+	}
+}
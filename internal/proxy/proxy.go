@@ -5,17 +5,22 @@ import (
 	"bytes"
 	"context"
 	"crypto/tls"
+	"crypto/x509"
 	"errors"
 	"fmt"
 	"io"
 	"net"
 	"net/http"
+	"net/http/httptrace"
 	"net/url"
 	"strings"
+	"sync"
 	"time"
 
 	"burpui/internal/ca"
 	"burpui/internal/httpraw"
+	"burpui/internal/jar"
+	"burpui/internal/resolver"
 )
 
 type Config struct {
@@ -23,6 +28,11 @@ type Config struct {
 	MaxBodyBytes int
 	MITM         bool
 	CADir        string
+	Resolver     *resolver.Resolver
+	UpstreamTLS  *UpstreamTLS
+	MirrorCerts  bool
+	MaxLeaves    int
+	Jar          *jar.Jar
 }
 
 type Proxy struct {
@@ -30,9 +40,11 @@ type Proxy struct {
 	ctrl   *Controller
 	flowCh chan<- *FlowSnapshot
 
-	server    *http.Server
-	transport *http.Transport
-	ca        *ca.Store
+	server     *http.Server
+	transport  *http.Transport
+	transports []*http.Transport
+	caMu       sync.Mutex
+	ca         *ca.Store
 }
 
 type readerCloser struct {
@@ -40,20 +52,36 @@ type readerCloser struct {
 	io.Closer
 }
 
+type writeCloser struct {
+	io.Writer
+	io.Closer
+}
+
 func New(cfg Config, ctrl *Controller, flowCh chan<- *FlowSnapshot) (*Proxy, error) {
 	tr := http.DefaultTransport.(*http.Transport).Clone()
 	tr.Proxy = nil
-	tr.TLSClientConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+	tr.DialContext = cfg.Resolver.DialContext
+	tr.TLSClientConfig = defaultUpstreamTLSConfig()
 
 	p := &Proxy{cfg: cfg, ctrl: ctrl, flowCh: flowCh, transport: tr}
+	if cfg.UpstreamTLS != nil {
+		for _, tlsCfg := range cfg.UpstreamTLS.configs {
+			rt := tr.Clone()
+			rt.TLSClientConfig = tlsCfg
+			p.transports = append(p.transports, rt)
+		}
+	}
 	if cfg.MITM {
 		st, err := ca.LoadOrCreate(cfg.CADir)
 		if err != nil {
 			return nil, err
 		}
+		if cfg.MaxLeaves > 0 {
+			st.MaxLeaves = cfg.MaxLeaves
+		}
 		p.ca = st
 	}
-	p.server = &http.Server{Addr: cfg.ListenAddr, Handler: p}
+	p.server = &http.Server{Addr: cfg.ListenAddr, Handler: p, ConnContext: withConnThrottle}
 	return p, nil
 }
 
@@ -82,8 +110,8 @@ func (p *Proxy) Serve(ctx context.Context) error {
 }
 
 func (p *Proxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	if p.cfg.MITM && r.Method != http.MethodConnect && r.URL != nil && r.URL.IsAbs() && r.URL.Scheme == "http" && (r.URL.Host == "burpui.local" || r.URL.Host == "burpui") && (r.URL.Path == "/ca" || r.URL.Path == "/cacert") {
-		p.serveCA(w)
+	if r.Method != http.MethodConnect && r.URL != nil && r.URL.IsAbs() && r.URL.Scheme == "http" && isOnboardingHost(r.URL.Host) {
+		p.serveOnboarding(w, r)
 		return
 	}
 	if r.Method == http.MethodConnect {
@@ -98,8 +126,10 @@ func (p *Proxy) handleHTTP(w http.ResponseWriter, r *http.Request) {
 	flow := newFlow()
 	flow.Method = r.Method
 	flow.Host = r.Host
+	flow.ClientAddr = r.RemoteAddr
 	flow.URL = requestURLString(r)
 	flow.RequestHeader = cloneHeader(r.Header)
+	flow.conn = connThrottleFrom(r.Context())
 
 	p.emit(flow)
 
@@ -179,6 +209,10 @@ func (p *Proxy) handleHTTP(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	if p.ctrl.sessionHandler() != nil && r.ContentLength > 0 && canBufferRequest(r, p.cfg.MaxBodyBytes) {
+		p.sendBufferedRequest(w, r, flow)
+		return
+	}
 	p.sendStreamedRequest(w, r, flow)
 }
 
@@ -194,7 +228,7 @@ func (p *Proxy) sendStreamedRequest(w http.ResponseWriter, r *http.Request, flow
 
 	lb := NewLimitBuffer(p.cfg.MaxBodyBytes)
 	var body io.ReadCloser
-	if r.Body != nil {
+	if r.Body != nil && r.Body != http.NoBody {
 		tee := io.TeeReader(r.Body, lb)
 		body = readerCloser{Reader: tee, Closer: r.Body}
 	}
@@ -208,7 +242,7 @@ func (p *Proxy) sendStreamedRequest(w http.ResponseWriter, r *http.Request, flow
 	outReq.Host = r.Host
 	outReq = prepareRequestForRoundTrip(outReq)
 
-	resp, err := p.transport.RoundTrip(outReq)
+	resp, err := p.roundTrip(outReq, flow)
 	if err != nil {
 		flow.Error = err.Error()
 		flow.Pending = false
@@ -228,7 +262,7 @@ func (p *Proxy) sendStreamedRequest(w http.ResponseWriter, r *http.Request, flow
 }
 
 func (p *Proxy) sendPreparedRequest(w http.ResponseWriter, outReq *http.Request, flow *Flow) {
-	resp, err := p.transport.RoundTrip(outReq)
+	resp, err := p.roundTrip(outReq, flow)
 	if err != nil {
 		flow.Error = err.Error()
 		flow.Pending = false
@@ -243,24 +277,83 @@ func (p *Proxy) sendPreparedRequest(w http.ResponseWriter, outReq *http.Request,
 	p.writeResponse(w, resp, flow)
 }
 
+func (p *Proxy) roundTrip(req *http.Request, flow *Flow) (*http.Response, error) {
+	if req.URL != nil {
+		flow.throttle = p.ctrl.throttleFor(req.URL.Host, flow.conn)
+		flow.Throttle = flow.throttle.name()
+	}
+
+	ft := &flowTrace{}
+	resp, err := p.roundTripUpstream(req, flow, ft)
+	ft.apply(flow, resp)
+	return resp, err
+}
+
+func (p *Proxy) roundTripUpstream(req *http.Request, flow *Flow, ft *flowTrace) (*http.Response, error) {
+	if resp, ok, err := flow.throttle.inject(req); ok {
+		return resp, err
+	}
+	h := p.ctrl.sessionHandler()
+	urlStr, since := req.URL.String(), time.Now()
+	if h != nil {
+		p.prepareSession(h, urlStr, req, flow)
+	}
+	if resp, ok, err := p.mockResponse(req, flow); ok {
+		return resp, err
+	}
+	if resp, ok := p.mapLocalResponse(req, flow); ok {
+		return resp, nil
+	}
+	p.applyMapRemote(req, flow)
+	resp, err := p.transportFor(req.URL.Host).RoundTrip(req.WithContext(httptrace.WithClientTrace(req.Context(), ft.clientTrace())))
+	if err != nil || h == nil {
+		return resp, err
+	}
+	return p.refreshSession(h, urlStr, since, req, resp, flow, ft)
+}
+
+func (p *Proxy) storeCookies(flow *Flow, h http.Header) {
+	if p.cfg.Jar == nil || len(h.Values("Set-Cookie")) == 0 {
+		return
+	}
+	if u, err := url.Parse(flow.URL); err == nil {
+		p.cfg.Jar.Store(u, h)
+	}
+}
+
+func (p *Proxy) transportFor(host string) *http.Transport {
+	if i := p.cfg.UpstreamTLS.match(host); i >= 0 && i < len(p.transports) {
+		return p.transports[i]
+	}
+	return p.transport
+}
+
 func (p *Proxy) writeResponse(w http.ResponseWriter, resp *http.Response, flow *Flow) {
 	flow.StatusCode = resp.StatusCode
 	flow.ResponseHeader = cloneHeader(resp.Header)
+	p.storeCookies(flow, resp.Header)
 
 	for k, vv := range cleanHopByHopHeaders(cloneHeader(resp.Header)) {
 		for _, v := range vv {
 			w.Header().Add(k, v)
 		}
 	}
+	flow.throttle.delay()
 	w.WriteHeader(resp.StatusCode)
 
+	out := flow.throttle.writer(w)
 	respLB := NewLimitBuffer(p.cfg.MaxBodyBytes)
 	buf := make([]byte, 32*1024)
+	reset := false
 	for {
 		n, readErr := resp.Body.Read(buf)
 		if n > 0 {
 			_, _ = respLB.Write(buf[:n])
-			_, _ = w.Write(buf[:n])
+			if _, err := out.Write(buf[:n]); errors.Is(err, errSimulatedReset) {
+				flow.Error = err.Error()
+				reset = true
+				break
+			}
 		}
 		if readErr != nil {
 			if readErr != io.EOF {
@@ -273,8 +366,11 @@ func (p *Proxy) writeResponse(w http.ResponseWriter, resp *http.Response, flow *
 	flow.ResponseBody = respLB.Bytes()
 	flow.RespTruncated = respLB.Truncated
 	flow.Pending = false
-	flow.Duration = time.Since(flow.StartedAt)
+	flow.finishTiming()
 	p.emit(flow)
+	if reset {
+		panic(http.ErrAbortHandler)
+	}
 }
 
 func canBufferRequest(r *http.Request, maxBodyBytes int) bool {
@@ -314,6 +410,9 @@ func buildOutgoingRequestFromOriginal(r *http.Request, body []byte) *http.Reques
 	outReq.RequestURI = ""
 	outReq.Header = cleanHopByHopHeaders(cloneHeader(r.Header))
 	outReq.Body = io.NopCloser(bytes.NewReader(body))
+	outReq.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(body)), nil
+	}
 	outReq.Host = r.Host
 	return prepareRequestForRoundTrip(outReq)
 }
@@ -362,45 +461,20 @@ func renderRawRequest(method, urlStr, host string, header http.Header, body []by
 }
 
 func (p *Proxy) handleConnect(w http.ResponseWriter, r *http.Request) {
-	if p.cfg.MITM && p.ca != nil {
+	passthrough := p.cfg.MITM && p.ctrl.shouldPassthrough(r.Host)
+	if p.cfg.MITM && p.ca != nil && !passthrough {
 		p.handleConnectMITM(w, r)
 		return
 	}
-	hijacker, ok := w.(http.Hijacker)
-	if !ok {
-		w.WriteHeader(http.StatusInternalServerError)
-		return
-	}
 
-	targetConn, err := net.DialTimeout("tcp", r.Host, 10*time.Second)
-	if err != nil {
-		w.WriteHeader(http.StatusBadGateway)
-		return
-	}
-
-	clientConn, buf, err := hijacker.Hijack()
-	if err != nil {
-		_ = targetConn.Close()
-		w.WriteHeader(http.StatusInternalServerError)
-		return
-	}
-
-	_, _ = clientConn.Write([]byte("HTTP/1.1 200 Connection Established\r\n\r\n"))
-
-	go func() {
-		_ = copyAndClose(targetConn, buf)
-	}()
-	_ = copyAndClose(clientConn, targetConn)
-}
-
-func (p *Proxy) serveCA(w http.ResponseWriter) {
-	if p.ca == nil {
-		w.WriteHeader(http.StatusNotFound)
-		return
-	}
-	w.Header().Set("Content-Type", "application/x-pem-file")
-	w.Header().Set("Content-Disposition", "attachment; filename=burpui-ca.pem")
-	_, _ = w.Write(p.ca.RootCertPEM())
+	flow := newFlow()
+	flow.Method = http.MethodConnect
+	flow.URL = r.Host
+	flow.Host = r.Host
+	flow.ClientAddr = r.RemoteAddr
+	flow.Tunnel = true
+	flow.Passthrough = passthrough
+	p.tunnel(w, r, flow)
 }
 
 func (p *Proxy) handleConnectMITM(w http.ResponseWriter, r *http.Request) {
@@ -420,34 +494,74 @@ func (p *Proxy) handleConnectMITM(w http.ResponseWriter, r *http.Request) {
 
 	host := r.Host
 	hostname := host
+	urlHost := host
 	if strings.Contains(host, ":") {
-		if h, _, splitErr := net.SplitHostPort(host); splitErr == nil {
+		if h, port, splitErr := net.SplitHostPort(host); splitErr == nil {
 			hostname = h
+			if port == "443" {
+				urlHost = hostname
+			}
 		}
 	}
 
-	certPEM, keyPEM, err := p.ca.LeafCert(hostname)
+	flow := newFlow()
+	flow.Method = http.MethodConnect
+	flow.URL = host
+	flow.Host = host
+	flow.ClientAddr = clientConn.RemoteAddr().String()
+	fail := func(msg string) {
+		flow.Error = msg
+		flow.Pending = false
+		flow.Duration = time.Since(flow.StartedAt)
+		p.emit(flow)
+	}
+
+	certPEM, keyPEM, err := p.leafFor(host, hostname)
 	if err != nil {
 		_ = clientConn.Close()
+		fail("certificado forjado: " + err.Error())
 		return
 	}
 	leaf, err := tls.X509KeyPair(certPEM, keyPEM)
 	if err != nil {
 		_ = clientConn.Close()
+		fail("certificado forjado: " + err.Error())
 		return
 	}
 
 	_, _ = clientConn.Write([]byte("HTTP/1.1 200 Connection Established\r\n\r\n"))
 
+	var hello *ClientHello
 	tlsSrv := tls.Server(bc, &tls.Config{
 		Certificates: []tls.Certificate{leaf},
 		MinVersion:   tls.VersionTLS12,
+		GetConfigForClient: func(info *tls.ClientHelloInfo) (*tls.Config, error) {
+			hello = helloFromInfo(info)
+			return nil, nil
+		},
 	})
 	if err := tlsSrv.Handshake(); err != nil {
 		_ = tlsSrv.Close()
+		flow.ClientHello = hello
+		msg := "handshake TLS: " + err.Error()
+		if p.ctrl.recordHandshake(hostname, false) {
+			msg += " (host adicionado ao passthrough)"
+		}
+		fail(msg)
 		return
 	}
+	p.ctrl.recordHandshake(hostname, true)
 
+	cs := tlsSrv.ConnectionState()
+	flow.ClientHello = hello
+	flow.TLSVersion = tls.VersionName(cs.Version)
+	flow.TLSCipher = tls.CipherSuiteName(cs.CipherSuite)
+	flow.ALPN = cs.NegotiatedProtocol
+	flow.Pending = false
+	flow.Duration = time.Since(flow.StartedAt)
+	p.emit(flow)
+
+	conn := &connThrottle{}
 	br := bufio.NewReader(tlsSrv)
 	for {
 		req, err := http.ReadRequest(br)
@@ -457,14 +571,60 @@ func (p *Proxy) handleConnectMITM(w http.ResponseWriter, r *http.Request) {
 		}
 
 		req.URL.Scheme = "https"
-		req.URL.Host = host
+		req.URL.Host = urlHost
 		req.RequestURI = ""
 		if req.Host == "" {
 			req.Host = host
 		}
 
-		p.handleMITMRequest(tlsSrv, req, hostname)
+		if isOnboardingHost(hostname) {
+			resp := p.onboardingResponse(req, clientConn.RemoteAddr().String())
+			err := resp.Write(tlsSrv)
+			_ = resp.Body.Close()
+			if err != nil {
+				_ = tlsSrv.Close()
+				return
+			}
+			continue
+		}
+
+		p.handleMITMRequest(tlsSrv, req, hostname, conn)
+	}
+}
+
+func (p *Proxy) leafFor(host, hostname string) ([]byte, []byte, error) {
+	if p.cfg.MirrorCerts && !isOnboardingHost(hostname) {
+		certPEM, keyPEM, err := p.ca.MirrorLeafCert(hostname, func() (*x509.Certificate, error) {
+			return p.fetchUpstreamCert(host, hostname)
+		})
+		if err == nil {
+			return certPEM, keyPEM, nil
+		}
+	}
+	return p.ca.LeafCert(hostname)
+}
+
+func (p *Proxy) fetchUpstreamCert(host, serverName string) (*x509.Certificate, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	conn, err := p.cfg.Resolver.DialContext(ctx, "tcp", host)
+	if err != nil {
+		return nil, err
+	}
+	cfg := p.cfg.UpstreamTLS.ConfigFor(host)
+	cfg.InsecureSkipVerify = true
+	cfg.ServerName = serverName
+	tc := tls.Client(conn, cfg)
+	defer tc.Close()
+	if err := tc.HandshakeContext(ctx); err != nil {
+		return nil, err
 	}
+	certs := tc.ConnectionState().PeerCertificates
+	if len(certs) == 0 {
+		return nil, fmt.Errorf("upstream sem certificado")
+	}
+	return certs[0], nil
 }
 
 type bufferedConn struct {
@@ -476,10 +636,12 @@ func (c bufferedConn) Read(p []byte) (int, error) {
 	return c.r.Read(p)
 }
 
-func (p *Proxy) handleMITMRequest(clientConn net.Conn, req *http.Request, hostname string) {
+func (p *Proxy) handleMITMRequest(clientConn net.Conn, req *http.Request, hostname string, conn *connThrottle) {
 	flow := newFlow()
+	flow.conn = conn
 	flow.Method = req.Method
 	flow.Host = hostname
+	flow.ClientAddr = clientConn.RemoteAddr().String()
 	flow.URL = req.URL.String()
 	flow.RequestHeader = cloneHeader(req.Header)
 
@@ -558,7 +720,7 @@ func (p *Proxy) handleMITMRequest(clientConn net.Conn, req *http.Request, hostna
 }
 
 func (p *Proxy) forwardMITM(clientConn net.Conn, req *http.Request, flow *Flow) {
-	resp, err := p.transport.RoundTrip(req)
+	resp, err := p.roundTrip(req, flow)
 	if err != nil {
 		flow.Error = err.Error()
 		flow.Pending = false
@@ -570,20 +732,28 @@ func (p *Proxy) forwardMITM(clientConn net.Conn, req *http.Request, flow *Flow)
 
 	flow.StatusCode = resp.StatusCode
 	flow.ResponseHeader = cloneHeader(resp.Header)
+	p.storeCookies(flow, resp.Header)
 	flow.ReqTruncated = false
 	flow.RespTruncated = false
 
 	respLB := NewLimitBuffer(p.cfg.MaxBodyBytes)
 	resp.Body = readerCloser{Reader: io.TeeReader(resp.Body, respLB), Closer: resp.Body}
 
-	bw := bufio.NewWriter(clientConn)
-	_ = resp.Write(bw)
-	_ = bw.Flush()
+	flow.throttle.delay()
+	bw := bufio.NewWriter(flow.throttle.writer(clientConn))
+	writeErr := resp.Write(bw)
+	if writeErr == nil {
+		writeErr = bw.Flush()
+	}
+	if errors.Is(writeErr, errSimulatedReset) {
+		flow.Error = writeErr.Error()
+		_ = clientConn.Close()
+	}
 
 	flow.ResponseBody = respLB.Bytes()
 	flow.RespTruncated = respLB.Truncated
 	flow.Pending = false
-	flow.Duration = time.Since(flow.StartedAt)
+	flow.finishTiming()
 	p.emit(flow)
 }
 
@@ -0,0 +1,88 @@
+package proxy
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptrace"
+	"time"
+)
+
+type SessionHandler interface {
+	Active() bool
+	Prepare(urlStr string, req *http.Request) bool
+	Refresh(urlStr string, status int, since time.Time) (string, error)
+}
+
+func (c *Controller) SetSessionHandler(h SessionHandler) {
+	c.mu.Lock()
+	c.session = h
+	c.mu.Unlock()
+}
+
+func (c *Controller) sessionHandler() SessionHandler {
+	c.mu.RLock()
+	h := c.session
+	c.mu.RUnlock()
+	if h == nil || !h.Active() {
+		return nil
+	}
+	return h
+}
+
+func (p *Proxy) prepareSession(h SessionHandler, urlStr string, req *http.Request, flow *Flow) {
+	if h.Prepare(urlStr, req) {
+		flow.RequestHeader = cloneHeader(req.Header)
+		if flow.Session == "" {
+			flow.Session = "regras de sessão aplicadas"
+		}
+	}
+}
+
+func (p *Proxy) refreshSession(h SessionHandler, urlStr string, since time.Time, req *http.Request, resp *http.Response, flow *Flow, ft *flowTrace) (*http.Response, error) {
+	replayable := req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+	if !replayable {
+		return resp, nil
+	}
+	name, err := h.Refresh(urlStr, resp.StatusCode, since)
+	if name == "" {
+		return resp, nil
+	}
+	if err != nil {
+		flow.Session = "macro " + name + " falhou: " + err.Error()
+		return resp, nil
+	}
+
+	retry := req.Clone(req.Context())
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return resp, nil
+		}
+		retry.Body = body
+	}
+	p.prepareSession(h, urlStr, retry, flow)
+	next, err := p.transportFor(retry.URL.Host).RoundTrip(retry.WithContext(httptrace.WithClientTrace(retry.Context(), ft.clientTrace())))
+	if err != nil {
+		flow.Session = "macro " + name + " executada, reenvio falhou: " + err.Error()
+		return resp, nil
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	_ = resp.Body.Close()
+	flow.Session = "macro " + name + " executada após " + resp.Status + ", requisição reenviada"
+	return next, nil
+}
+
+func (p *Proxy) sendBufferedRequest(w http.ResponseWriter, r *http.Request, flow *Flow) {
+	b, err := readBodyAll(r)
+	if err != nil {
+		flow.Error = err.Error()
+		flow.Pending = false
+		flow.Duration = time.Since(flow.StartedAt)
+		p.emit(flow)
+		w.WriteHeader(http.StatusBadGateway)
+		_, _ = w.Write([]byte("bad gateway\n"))
+		return
+	}
+	flow.RequestBody = b
+	p.sendPreparedRequest(w, buildOutgoingRequestFromOriginal(r, b), flow)
+}
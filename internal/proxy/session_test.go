@@ -0,0 +1,83 @@
+package proxy
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+type fakeSession struct {
+	token   string
+	refresh int
+}
+
+func (f *fakeSession) Active() bool { return true }
+
+func (f *fakeSession) Prepare(urlStr string, req *http.Request) bool {
+	if !strings.Contains(urlStr, "/api") {
+		return false
+	}
+	req.Header.Set("Authorization", "Bearer "+f.token)
+	return true
+}
+
+func (f *fakeSession) Refresh(urlStr string, status int, since time.Time) (string, error) {
+	if status != http.StatusUnauthorized {
+		return "", nil
+	}
+	f.refresh++
+	if f.refresh > 1 {
+		return "login", fmt.Errorf("credenciais recusadas")
+	}
+	f.token = "new"
+	return "login", nil
+}
+
+func TestSessionHandlerRefreshesAndRetries(t *testing.T) {
+	valid := "new"
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		if r.Header.Get("Authorization") != "Bearer "+valid {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		fmt.Fprintf(w, "ok %s", b)
+	}))
+	defer srv.Close()
+
+	c := NewController()
+	h := &fakeSession{token: "old"}
+	c.SetSessionHandler(h)
+	p := &Proxy{cfg: Config{MaxBodyBytes: 1024}, ctrl: c, transport: http.DefaultTransport.(*http.Transport).Clone()}
+
+	req, _ := http.NewRequest(http.MethodPost, srv.URL+"/api/x", strings.NewReader("payload"))
+	flow := newFlow()
+	resp, err := p.roundTrip(req, flow)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != 200 || string(body) != "ok payload" || h.refresh != 1 {
+		t.Fatalf("status=%d body=%q refresh=%d", resp.StatusCode, body, h.refresh)
+	}
+	if !strings.Contains(flow.Session, "macro login") || flow.RequestHeader.Get("Authorization") != "Bearer new" {
+		t.Fatalf("flow session=%q header=%v", flow.Session, flow.RequestHeader)
+	}
+
+	valid = "other"
+	req, _ = http.NewRequest(http.MethodGet, srv.URL+"/api/x", nil)
+	flow = newFlow()
+	resp, err = p.roundTrip(req, flow)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnauthorized || !strings.Contains(flow.Session, "falhou") {
+		t.Fatalf("status=%d session=%q", resp.StatusCode, flow.Session)
+	}
+}
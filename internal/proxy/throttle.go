@@ -0,0 +1,377 @@
+package proxy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	ThrottleHostsOnly   = "hosts"
+	defaultTimeoutAfter = 30 * time.Second
+)
+
+var errSimulatedReset = errors.New("throttle: reset simulado")
+
+type ThrottleProfile struct {
+	Name         string
+	Latency      time.Duration
+	Bandwidth    int64
+	Global       bool
+	ResetPct     float64
+	ErrorPct     float64
+	TimeoutPct   float64
+	TimeoutAfter time.Duration
+}
+
+type ThrottleRule struct {
+	ID      int64
+	Enabled bool
+	Match   string
+	Profile string
+}
+
+func DefaultThrottleProfiles() []ThrottleProfile {
+	return []ThrottleProfile{
+		{Name: "3g", Latency: 300 * time.Millisecond, Bandwidth: 96 << 10},
+		{Name: "edge", Latency: 800 * time.Millisecond, Bandwidth: 30 << 10},
+		{Name: "lossy", Latency: 150 * time.Millisecond, ResetPct: 5, ErrorPct: 5, TimeoutPct: 2},
+	}
+}
+
+func ParseThrottleProfile(s string) (ThrottleProfile, error) {
+	name, opts, _ := strings.Cut(strings.TrimSpace(s), ":")
+	name = strings.TrimSpace(name)
+	if name == "" || name == ThrottleHostsOnly {
+		return ThrottleProfile{}, fmt.Errorf("throttle: nome de perfil inválido: %q", name)
+	}
+	p := ThrottleProfile{Name: name}
+	for _, opt := range strings.Split(opts, ",") {
+		k, v, _ := strings.Cut(strings.TrimSpace(opt), "=")
+		k = strings.ToLower(strings.TrimSpace(k))
+		v = strings.TrimSpace(v)
+		var err error
+		switch k {
+		case "":
+		case "latency":
+			p.Latency, err = time.ParseDuration(v)
+		case "bw", "bandwidth":
+			p.Bandwidth, err = parseByteRate(v)
+		case "global":
+			p.Global = true
+		case "reset":
+			p.ResetPct, err = strconv.ParseFloat(v, 64)
+		case "5xx", "error":
+			p.ErrorPct, err = strconv.ParseFloat(v, 64)
+		case "timeout":
+			p.TimeoutPct, err = strconv.ParseFloat(v, 64)
+		case "timeout-after":
+			p.TimeoutAfter, err = time.ParseDuration(v)
+		default:
+			err = fmt.Errorf("opção desconhecida")
+		}
+		if err != nil {
+			return ThrottleProfile{}, fmt.Errorf("throttle %s: %s: %w", name, k, err)
+		}
+	}
+	return p, nil
+}
+
+func parseByteRate(s string) (int64, error) {
+	s = strings.ToLower(strings.TrimSuffix(strings.ToLower(s), "/s"))
+	mult := int64(1)
+	switch {
+	case strings.HasSuffix(s, "k"):
+		mult = 1 << 10
+		s = strings.TrimSuffix(s, "k")
+	case strings.HasSuffix(s, "m"):
+		mult = 1 << 20
+		s = strings.TrimSuffix(s, "m")
+	}
+	n, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, err
+	}
+	return int64(n * float64(mult)), nil
+}
+
+func (c *Controller) SetThrottleProfile(p ThrottleProfile) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.throttleProfiles == nil {
+		c.throttleProfiles = map[string]ThrottleProfile{}
+	}
+	if _, ok := c.throttleProfiles[p.Name]; !ok {
+		c.throttleOrder = append(c.throttleOrder, p.Name)
+	}
+	c.throttleProfiles[p.Name] = p
+	delete(c.throttleGlobal, p.Name)
+}
+
+func (c *Controller) AddThrottleRule(match, profile string) (ThrottleRule, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.throttleProfiles[profile]; !ok {
+		return ThrottleRule{}, fmt.Errorf("throttle: perfil desconhecido: %q", profile)
+	}
+	r := ThrottleRule{ID: c.nextRuleID.Add(1), Enabled: true, Match: strings.TrimSpace(match), Profile: profile}
+	c.throttleRules = append(c.throttleRules, r)
+	return r, nil
+}
+
+func (c *Controller) ListThrottleRules() []ThrottleRule {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	out := make([]ThrottleRule, len(c.throttleRules))
+	copy(out, c.throttleRules)
+	return out
+}
+
+func (c *Controller) ThrottleMode() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.throttleMode
+}
+
+func (c *Controller) SetThrottleMode(mode string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if mode != "" && mode != ThrottleHostsOnly {
+		if _, ok := c.throttleProfiles[mode]; !ok {
+			return fmt.Errorf("throttle: perfil desconhecido: %q", mode)
+		}
+	}
+	c.throttleMode = mode
+	return nil
+}
+
+func (c *Controller) CycleThrottle() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	modes := []string{""}
+	if len(c.throttleRules) > 0 {
+		modes = append(modes, ThrottleHostsOnly)
+	}
+	modes = append(modes, c.throttleOrder...)
+	next := 0
+	for i, m := range modes {
+		if m == c.throttleMode {
+			next = (i + 1) % len(modes)
+			break
+		}
+	}
+	c.throttleMode = modes[next]
+	return c.throttleMode
+}
+
+type throttleSession struct {
+	profile ThrottleProfile
+	lim     *rateLimiter
+}
+
+type connThrottle struct {
+	mu   sync.Mutex
+	lims map[string]*rateLimiter
+}
+
+type connThrottleKey struct{}
+
+func withConnThrottle(ctx context.Context, _ net.Conn) context.Context {
+	return context.WithValue(ctx, connThrottleKey{}, &connThrottle{})
+}
+
+func connThrottleFrom(ctx context.Context) *connThrottle {
+	ct, _ := ctx.Value(connThrottleKey{}).(*connThrottle)
+	return ct
+}
+
+func (ct *connThrottle) limiter(p ThrottleProfile) *rateLimiter {
+	if ct == nil {
+		return &rateLimiter{bps: p.Bandwidth}
+	}
+	ct.mu.Lock()
+	defer ct.mu.Unlock()
+	if ct.lims == nil {
+		ct.lims = map[string]*rateLimiter{}
+	}
+	l := ct.lims[p.Name]
+	if l == nil || l.bps != p.Bandwidth {
+		l = &rateLimiter{bps: p.Bandwidth}
+		ct.lims[p.Name] = l
+	}
+	return l
+}
+
+func (c *Controller) throttleFor(host string, conn *connThrottle) *throttleSession {
+	host = stripPort(host)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.throttleMode == "" {
+		return nil
+	}
+
+	name := ""
+	for _, r := range c.throttleRules {
+		if r.Enabled && GlobMatch(r.Match, host) {
+			name = r.Profile
+			break
+		}
+	}
+	if name == "" && c.throttleMode != ThrottleHostsOnly {
+		name = c.throttleMode
+	}
+	p, ok := c.throttleProfiles[name]
+	if !ok {
+		return nil
+	}
+
+	ts := &throttleSession{profile: p}
+	if p.Bandwidth > 0 {
+		if p.Global {
+			if c.throttleGlobal == nil {
+				c.throttleGlobal = map[string]*rateLimiter{}
+			}
+			if c.throttleGlobal[name] == nil {
+				c.throttleGlobal[name] = &rateLimiter{bps: p.Bandwidth}
+			}
+			ts.lim = c.throttleGlobal[name]
+		} else {
+			ts.lim = conn.limiter(p)
+		}
+	}
+	return ts
+}
+
+func (t *throttleSession) name() string {
+	if t == nil {
+		return ""
+	}
+	return t.profile.Name
+}
+
+func (t *throttleSession) delay() {
+	if t == nil || t.profile.Latency <= 0 {
+		return
+	}
+	time.Sleep(t.profile.Latency)
+}
+
+func (t *throttleSession) writer(w io.Writer) io.Writer {
+	if t == nil {
+		return w
+	}
+	tw := &throttledWriter{w: w, lim: t.lim, budget: -1}
+	if roll(t.profile.ResetPct) {
+		tw.budget = rand.Int64N(32 << 10)
+	}
+	return tw
+}
+
+func (t *throttleSession) limitWriter(w io.Writer) io.Writer {
+	if t == nil || t.lim == nil {
+		return w
+	}
+	return &throttledWriter{w: w, lim: t.lim, budget: -1}
+}
+
+func (t *throttleSession) inject(req *http.Request) (*http.Response, bool, error) {
+	if t == nil {
+		return nil, false, nil
+	}
+	if roll(t.profile.TimeoutPct) {
+		hang := t.profile.TimeoutAfter
+		if hang <= 0 {
+			hang = defaultTimeoutAfter
+		}
+		timer := time.NewTimer(hang)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-req.Context().Done():
+		}
+		return nil, true, fmt.Errorf("throttle: timeout simulado")
+	}
+	if roll(t.profile.ErrorPct) {
+		codes := []int{http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout}
+		code := codes[rand.IntN(len(codes))]
+		h := http.Header{}
+		h.Set("Content-Type", "text/plain; charset=utf-8")
+		return newResponse(req, code, h, []byte("throttle: erro simulado\n")), true, nil
+	}
+	return nil, false, nil
+}
+
+func roll(pct float64) bool {
+	return pct > 0 && rand.Float64()*100 < pct
+}
+
+type rateLimiter struct {
+	mu   sync.Mutex
+	bps  int64
+	next time.Time
+}
+
+func (l *rateLimiter) wait(n int) {
+	if l == nil || l.bps <= 0 || n <= 0 {
+		return
+	}
+	l.mu.Lock()
+	now := time.Now()
+	if l.next.Before(now) {
+		l.next = now
+	}
+	l.next = l.next.Add(time.Duration(float64(n) / float64(l.bps) * float64(time.Second)))
+	sleep := l.next.Sub(now)
+	l.mu.Unlock()
+	time.Sleep(sleep)
+}
+
+type throttledWriter struct {
+	w      io.Writer
+	lim    *rateLimiter
+	budget int64
+}
+
+func (t *throttledWriter) Write(p []byte) (int, error) {
+	written := 0
+	for len(p) > 0 {
+		chunk := p
+		if len(chunk) > 4<<10 {
+			chunk = chunk[:4<<10]
+		}
+		if t.budget >= 0 {
+			if t.budget == 0 {
+				return written, errSimulatedReset
+			}
+			if int64(len(chunk)) > t.budget {
+				chunk = chunk[:t.budget]
+			}
+		}
+		t.lim.wait(len(chunk))
+		n, err := t.w.Write(chunk)
+		written += n
+		if t.budget >= 0 {
+			t.budget -= int64(n)
+		}
+		if err != nil {
+			return written, err
+		}
+		p = p[n:]
+	}
+	return written, nil
+}
+
+func stripPort(host string) string {
+	if h, _, err := parseHostPort(host); err == nil {
+		return h
+	}
+	return host
+}
@@ -0,0 +1,94 @@
+package proxy
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestParseThrottleProfile(t *testing.T) {
+	p, err := ParseThrottleProfile("slow:latency=250ms,bw=64k,global,reset=1.5,5xx=10,timeout=2,timeout-after=45s")
+	if err != nil {
+		t.Fatalf("ParseThrottleProfile: %v", err)
+	}
+	want := ThrottleProfile{Name: "slow", Latency: 250 * time.Millisecond, Bandwidth: 64 << 10, Global: true, ResetPct: 1.5, ErrorPct: 10, TimeoutPct: 2, TimeoutAfter: 45 * time.Second}
+	if p != want {
+		t.Fatalf("got %+v, want %+v", p, want)
+	}
+	if _, err := ParseThrottleProfile("x:foo=1"); err == nil {
+		t.Fatalf("expected error for unknown option")
+	}
+}
+
+func TestCycleThrottle(t *testing.T) {
+	c := NewController()
+	if _, err := c.AddThrottleRule("*.example.com", "edge"); err != nil {
+		t.Fatalf("AddThrottleRule: %v", err)
+	}
+	var got []string
+	for i := 0; i < 5; i++ {
+		got = append(got, c.CycleThrottle())
+	}
+	want := []string{ThrottleHostsOnly, "3g", "edge", "lossy", ""}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("cycle %d: got %q, want %q", i, got[i], want[i])
+		}
+	}
+
+	_ = c.SetThrottleMode(ThrottleHostsOnly)
+	if ts := c.throttleFor("api.example.com:443", nil); ts.name() != "edge" {
+		t.Fatalf("expected edge for rule host, got %q", ts.name())
+	}
+	if ts := c.throttleFor("other.org", nil); ts != nil {
+		t.Fatalf("expected no throttle outside rules, got %q", ts.name())
+	}
+}
+
+func TestThrottleLimiterPerConnection(t *testing.T) {
+	c := NewController()
+	c.SetThrottleProfile(ThrottleProfile{Name: "slow", Bandwidth: 1 << 10})
+	c.SetThrottleProfile(ThrottleProfile{Name: "shared", Bandwidth: 1 << 10, Global: true})
+	_ = c.SetThrottleMode("slow")
+
+	conn1, conn2 := &connThrottle{}, &connThrottle{}
+	a, b := c.throttleFor("a.example.com", conn1), c.throttleFor("b.example.com", conn1)
+	if a.lim == nil || a.lim != b.lim {
+		t.Fatalf("requests on one connection should share the limiter")
+	}
+	if c.throttleFor("a.example.com", conn2).lim == a.lim {
+		t.Fatalf("connections should not share the limiter")
+	}
+
+	_ = c.SetThrottleMode("shared")
+	if c.throttleFor("a.example.com", conn1).lim != c.throttleFor("a.example.com", conn2).lim {
+		t.Fatalf("global profile should share the limiter across connections")
+	}
+}
+
+func TestThrottleTimeoutAfter(t *testing.T) {
+	ts := &throttleSession{profile: ThrottleProfile{Name: "hang", TimeoutPct: 100, TimeoutAfter: 20 * time.Millisecond}}
+	start := time.Now()
+	_, ok, err := ts.inject(httptest.NewRequest(http.MethodGet, "http://a.example.com/", nil))
+	if !ok || err == nil {
+		t.Fatalf("expected simulated timeout, got ok=%v err=%v", ok, err)
+	}
+	if d := time.Since(start); d < 20*time.Millisecond || d > time.Second {
+		t.Fatalf("timeout took %s", d)
+	}
+}
+
+func TestThrottledWriterReset(t *testing.T) {
+	var b bytes.Buffer
+	w := &throttledWriter{w: &b, budget: 10}
+	n, err := w.Write(bytes.Repeat([]byte("a"), 32))
+	if !errors.Is(err, errSimulatedReset) {
+		t.Fatalf("expected reset error, got %v", err)
+	}
+	if n != 10 || b.Len() != 10 {
+		t.Fatalf("expected 10 bytes before reset, got n=%d len=%d", n, b.Len())
+	}
+}
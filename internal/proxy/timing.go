@@ -0,0 +1,98 @@
+package proxy
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptrace"
+	"sync"
+	"time"
+)
+
+type Timing struct {
+	DNS      time.Duration
+	Connect  time.Duration
+	TLS      time.Duration
+	Wait     time.Duration
+	Transfer time.Duration
+}
+
+type flowTrace struct {
+	mu        sync.Mutex
+	dnsStart  time.Time
+	dnsDone   time.Time
+	connStart time.Time
+	connDone  time.Time
+	tlsStart  time.Time
+	tlsDone   time.Time
+	wrote     time.Time
+	firstByte time.Time
+	remote    string
+	reused    bool
+}
+
+func (t *flowTrace) clientTrace() *httptrace.ClientTrace {
+	set := func(dst *time.Time) {
+		t.mu.Lock()
+		if dst.IsZero() {
+			*dst = time.Now()
+		}
+		t.mu.Unlock()
+	}
+	return &httptrace.ClientTrace{
+		DNSStart:          func(httptrace.DNSStartInfo) { set(&t.dnsStart) },
+		DNSDone:           func(httptrace.DNSDoneInfo) { set(&t.dnsDone) },
+		ConnectStart:      func(string, string) { set(&t.connStart) },
+		ConnectDone:       func(string, string, error) { set(&t.connDone) },
+		TLSHandshakeStart: func() { set(&t.tlsStart) },
+		TLSHandshakeDone:  func(tls.ConnectionState, error) { set(&t.tlsDone) },
+		WroteRequest:      func(httptrace.WroteRequestInfo) { set(&t.wrote) },
+		GotFirstResponseByte: func() {
+			set(&t.firstByte)
+		},
+		GotConn: func(info httptrace.GotConnInfo) {
+			t.mu.Lock()
+			if info.Conn != nil {
+				t.remote = info.Conn.RemoteAddr().String()
+			}
+			t.reused = info.Reused
+			t.mu.Unlock()
+		},
+	}
+}
+
+func between(start, end time.Time) time.Duration {
+	if start.IsZero() || end.IsZero() || end.Before(start) {
+		return 0
+	}
+	return end.Sub(start)
+}
+
+func (t *flowTrace) apply(flow *Flow, resp *http.Response) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	flow.RemoteAddr = t.remote
+	flow.ConnReused = t.reused
+	flow.Timing.DNS = between(t.dnsStart, t.dnsDone)
+	flow.Timing.Connect = between(t.connStart, t.connDone)
+	flow.Timing.TLS = between(t.tlsStart, t.tlsDone)
+	flow.Timing.Wait = between(t.wrote, t.firstByte)
+	flow.firstByteAt = t.firstByte
+	if flow.firstByteAt.IsZero() {
+		flow.firstByteAt = time.Now()
+	}
+
+	if resp != nil && resp.TLS != nil {
+		flow.TLSVersion = tls.VersionName(resp.TLS.Version)
+		flow.TLSCipher = tls.CipherSuiteName(resp.TLS.CipherSuite)
+		flow.ALPN = resp.TLS.NegotiatedProtocol
+		flow.UpstreamCerts = certInfos(resp.TLS.PeerCertificates)
+	}
+}
+
+func (f *Flow) finishTiming() {
+	if !f.firstByteAt.IsZero() {
+		f.Timing.Transfer = time.Since(f.firstByteAt)
+	}
+	f.Duration = time.Since(f.StartedAt)
+}
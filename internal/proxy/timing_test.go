@@ -0,0 +1,49 @@
+package proxy
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestHandleHTTP_RecordsTimingAndConn(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(5 * time.Millisecond)
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer upstream.Close()
+
+	flowCh := make(chan *FlowSnapshot, 16)
+	p, err := New(Config{MaxBodyBytes: 1024}, NewController(), flowCh)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, upstream.URL+"/x", nil)
+	rec := httptest.NewRecorder()
+	p.ServeHTTP(rec, req)
+	if rec.Body.String() != "ok" {
+		t.Fatalf("unexpected body %q", rec.Body.String())
+	}
+
+	var last *Flow
+	for len(flowCh) > 0 {
+		last = (<-flowCh).Flow
+	}
+	if last == nil || last.Pending {
+		t.Fatalf("expected finished flow, got %+v", last)
+	}
+	if last.ClientAddr != req.RemoteAddr {
+		t.Fatalf("expected client addr %q, got %q", req.RemoteAddr, last.ClientAddr)
+	}
+	if last.RemoteAddr != upstream.Listener.Addr().String() {
+		t.Fatalf("expected remote addr %q, got %q", upstream.Listener.Addr().String(), last.RemoteAddr)
+	}
+	if last.Timing.Wait < 5*time.Millisecond {
+		t.Fatalf("expected wait >= 5ms, got %s", last.Timing.Wait)
+	}
+	if last.Timing.Connect == 0 {
+		t.Fatalf("expected connect timing")
+	}
+}
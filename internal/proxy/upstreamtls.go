@@ -0,0 +1,195 @@
+package proxy
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"software.sslmate.com/src/go-pkcs12"
+)
+
+type UpstreamTLSRule struct {
+	Match        string
+	Insecure     bool
+	CAFile       string
+	ClientCert   string
+	ClientKey    string
+	PKCS12File   string
+	PKCS12Pass   string
+	MinVersion   string
+	MaxVersion   string
+	CipherSuites []string
+}
+
+type UpstreamTLS struct {
+	rules   []UpstreamTLSRule
+	configs []*tls.Config
+}
+
+type CertInfo struct {
+	Subject   string
+	Issuer    string
+	DNSNames  []string
+	NotBefore time.Time
+	NotAfter  time.Time
+	SHA256    string
+}
+
+func defaultUpstreamTLSConfig() *tls.Config {
+	return &tls.Config{MinVersion: tls.VersionTLS12}
+}
+
+func NewUpstreamTLS(rules []UpstreamTLSRule) (*UpstreamTLS, error) {
+	u := &UpstreamTLS{}
+	for i, r := range rules {
+		cfg, err := r.TLSConfig()
+		if err != nil {
+			return nil, fmt.Errorf("upstream tls[%d] %s: %w", i, r.Match, err)
+		}
+		u.rules = append(u.rules, r)
+		u.configs = append(u.configs, cfg)
+	}
+	return u, nil
+}
+
+func (u *UpstreamTLS) match(host string) int {
+	if u == nil {
+		return -1
+	}
+	host = stripPort(host)
+	for i, r := range u.rules {
+		if GlobMatch(r.Match, host) {
+			return i
+		}
+	}
+	return -1
+}
+
+func (u *UpstreamTLS) ConfigFor(host string) *tls.Config {
+	if i := u.match(host); i >= 0 {
+		return u.configs[i].Clone()
+	}
+	return defaultUpstreamTLSConfig()
+}
+
+func (r UpstreamTLSRule) TLSConfig() (*tls.Config, error) {
+	cfg := defaultUpstreamTLSConfig()
+	cfg.InsecureSkipVerify = r.Insecure
+
+	if r.CAFile != "" {
+		pemBytes, err := os.ReadFile(r.CAFile)
+		if err != nil {
+			return nil, err
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil || pool == nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pemBytes) {
+			return nil, fmt.Errorf("nenhum certificado em %s", r.CAFile)
+		}
+		cfg.RootCAs = pool
+	}
+
+	switch {
+	case r.PKCS12File != "":
+		data, err := os.ReadFile(r.PKCS12File)
+		if err != nil {
+			return nil, err
+		}
+		key, leaf, chain, err := pkcs12.DecodeChain(data, r.PKCS12Pass)
+		if err != nil {
+			return nil, fmt.Errorf("pkcs12: %w", err)
+		}
+		cert := tls.Certificate{Certificate: [][]byte{leaf.Raw}, PrivateKey: key, Leaf: leaf}
+		for _, c := range chain {
+			cert.Certificate = append(cert.Certificate, c.Raw)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	case r.ClientCert != "":
+		keyFile := r.ClientKey
+		if keyFile == "" {
+			keyFile = r.ClientCert
+		}
+		cert, err := tls.LoadX509KeyPair(r.ClientCert, keyFile)
+		if err != nil {
+			return nil, err
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+
+	if r.MinVersion != "" {
+		v, err := parseTLSVersion(r.MinVersion)
+		if err != nil {
+			return nil, err
+		}
+		cfg.MinVersion = v
+	}
+	if r.MaxVersion != "" {
+		v, err := parseTLSVersion(r.MaxVersion)
+		if err != nil {
+			return nil, err
+		}
+		cfg.MaxVersion = v
+	}
+	if cfg.MaxVersion != 0 && cfg.MaxVersion < cfg.MinVersion {
+		return nil, fmt.Errorf("max_version menor que min_version")
+	}
+
+	for _, name := range r.CipherSuites {
+		id, err := parseCipherSuite(name)
+		if err != nil {
+			return nil, err
+		}
+		cfg.CipherSuites = append(cfg.CipherSuites, id)
+	}
+	return cfg, nil
+}
+
+func parseTLSVersion(s string) (uint16, error) {
+	switch strings.TrimPrefix(strings.ToLower(strings.TrimSpace(s)), "tls") {
+	case "1.0", "10":
+		return tls.VersionTLS10, nil
+	case "1.1", "11":
+		return tls.VersionTLS11, nil
+	case "1.2", "12":
+		return tls.VersionTLS12, nil
+	case "1.3", "13":
+		return tls.VersionTLS13, nil
+	}
+	return 0, fmt.Errorf("versão TLS inválida: %q", s)
+}
+
+func parseCipherSuite(name string) (uint16, error) {
+	name = strings.TrimSpace(name)
+	for _, cs := range append(tls.CipherSuites(), tls.InsecureCipherSuites()...) {
+		if strings.EqualFold(cs.Name, name) {
+			return cs.ID, nil
+		}
+	}
+	return 0, fmt.Errorf("cipher suite desconhecida: %q", name)
+}
+
+func certInfos(certs []*x509.Certificate) []CertInfo {
+	if len(certs) == 0 {
+		return nil
+	}
+	out := make([]CertInfo, 0, len(certs))
+	for _, c := range certs {
+		sum := sha256.Sum256(c.Raw)
+		out = append(out, CertInfo{
+			Subject:   c.Subject.String(),
+			Issuer:    c.Issuer.String(),
+			DNSNames:  append([]string(nil), c.DNSNames...),
+			NotBefore: c.NotBefore,
+			NotAfter:  c.NotAfter,
+			SHA256:    strings.ToUpper(hex.EncodeToString(sum[:])),
+		})
+	}
+	return out
+}
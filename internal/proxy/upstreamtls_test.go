@@ -0,0 +1,68 @@
+package proxy
+
+import (
+	"crypto/tls"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestUpstreamTLS_CAFileAndCerts(t *testing.T) {
+	upstream := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer upstream.Close()
+
+	caPath := filepath.Join(t.TempDir(), "ca.pem")
+	caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: upstream.Certificate().Raw})
+	if err := os.WriteFile(caPath, caPEM, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	up, err := NewUpstreamTLS([]UpstreamTLSRule{{Match: "127.0.0.1", CAFile: caPath, MinVersion: "1.2", MaxVersion: "1.3"}})
+	if err != nil {
+		t.Fatalf("NewUpstreamTLS: %v", err)
+	}
+
+	flowCh := make(chan *FlowSnapshot, 16)
+	p, err := New(Config{MaxBodyBytes: 1024, UpstreamTLS: up}, NewController(), flowCh)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	rec := httptest.NewRecorder()
+	p.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, upstream.URL+"/", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200 with custom CA, got %d", rec.Code)
+	}
+
+	var last *Flow
+	for len(flowCh) > 0 {
+		last = (<-flowCh).Flow
+	}
+	if last == nil || len(last.UpstreamCerts) == 0 {
+		t.Fatalf("expected upstream certificates on flow")
+	}
+	if last.TLSVersion == "" {
+		t.Fatalf("expected TLS version on flow")
+	}
+}
+
+func TestUpstreamTLSRule_Invalid(t *testing.T) {
+	if _, err := (UpstreamTLSRule{MinVersion: "1.3", MaxVersion: "1.2"}).TLSConfig(); err == nil {
+		t.Fatalf("expected error for inverted versions")
+	}
+	if _, err := (UpstreamTLSRule{CipherSuites: []string{"NOPE"}}).TLSConfig(); err == nil {
+		t.Fatalf("expected error for unknown cipher")
+	}
+	cfg, err := (UpstreamTLSRule{Insecure: true, CipherSuites: []string{"TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256"}}).TLSConfig()
+	if err != nil {
+		t.Fatalf("TLSConfig: %v", err)
+	}
+	if !cfg.InsecureSkipVerify || len(cfg.CipherSuites) != 1 || cfg.CipherSuites[0] != tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256 {
+		t.Fatalf("unexpected config %+v", cfg)
+	}
+}
@@ -1,31 +1,60 @@
 package repeater
 
 import (
+	"context"
 	"crypto/tls"
 	"io"
+	"net"
 	"net/http"
 	"time"
 
 	"burpui/internal/httpraw"
 )
 
-func SendRaw(raw string, timeout time.Duration) (string, string, error) {
-	req, _, err := httpraw.ParseRequest(raw)
+type Options struct {
+	Timeout     time.Duration
+	DialContext func(ctx context.Context, network, addr string) (net.Conn, error)
+	TLSConfig   func(host string) *tls.Config
+}
+
+type Result struct {
+	Status     string
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+}
+
+func SendRaw(raw string, opts Options) (string, string, error) {
+	res, err := Send(raw, opts)
 	if err != nil {
 		return "", "", err
 	}
+	return res.Status, string(res.Body), nil
+}
+
+func Send(raw string, opts Options) (*Result, error) {
+	req, _, err := httpraw.ParseRequest(raw)
+	if err != nil {
+		return nil, err
+	}
 
 	tr := http.DefaultTransport.(*http.Transport).Clone()
 	tr.Proxy = nil
+	if opts.DialContext != nil {
+		tr.DialContext = opts.DialContext
+	}
 	tr.TLSClientConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+	if opts.TLSConfig != nil {
+		tr.TLSClientConfig = opts.TLSConfig(req.URL.Host)
+	}
 
-	client := &http.Client{Timeout: timeout, Transport: tr}
+	client := &http.Client{Timeout: opts.Timeout, Transport: tr}
 	resp, err := client.Do(req)
 	if err != nil {
-		return "", "", err
+		return nil, err
 	}
 	defer resp.Body.Close()
 
 	body, _ := io.ReadAll(resp.Body)
-	return resp.Status, string(body), nil
+	return &Result{Status: resp.Status, StatusCode: resp.StatusCode, Header: resp.Header, Body: body}, nil
 }
@@ -0,0 +1,129 @@
+package resolver
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http/httptrace"
+	"sort"
+	"strings"
+	"time"
+)
+
+type Resolver struct {
+	hosts     map[string]string
+	wildcards []wildcard
+	dns       *net.Resolver
+	dialer    *net.Dialer
+}
+
+type wildcard struct {
+	suffix string
+	ip     string
+}
+
+func New(hosts map[string]string, dnsServer string) (*Resolver, error) {
+	r := &Resolver{hosts: map[string]string{}, dialer: &net.Dialer{Timeout: 10 * time.Second, KeepAlive: 30 * time.Second}}
+	for host, ip := range hosts {
+		host = strings.ToLower(strings.TrimSuffix(strings.TrimSpace(host), "."))
+		ip = strings.TrimSpace(ip)
+		if host == "" || net.ParseIP(ip) == nil {
+			return nil, fmt.Errorf("host override inválido: %s=%s", host, ip)
+		}
+		if strings.HasPrefix(host, "*.") {
+			r.wildcards = append(r.wildcards, wildcard{suffix: host[1:], ip: ip})
+			continue
+		}
+		r.hosts[host] = ip
+	}
+	sort.Slice(r.wildcards, func(i, j int) bool {
+		if len(r.wildcards[i].suffix) != len(r.wildcards[j].suffix) {
+			return len(r.wildcards[i].suffix) > len(r.wildcards[j].suffix)
+		}
+		return r.wildcards[i].suffix < r.wildcards[j].suffix
+	})
+
+	dnsServer = strings.TrimSpace(dnsServer)
+	if dnsServer != "" {
+		if _, _, err := net.SplitHostPort(dnsServer); err != nil {
+			dnsServer = net.JoinHostPort(dnsServer, "53")
+		}
+		d := &net.Dialer{Timeout: 5 * time.Second}
+		r.dns = &net.Resolver{
+			PreferGo: true,
+			Dial: func(ctx context.Context, network, _ string) (net.Conn, error) {
+				return d.DialContext(ctx, network, dnsServer)
+			},
+		}
+	}
+	return r, nil
+}
+
+func (r *Resolver) Override(host string) (string, bool) {
+	if r == nil {
+		return "", false
+	}
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	if ip, ok := r.hosts[host]; ok {
+		return ip, true
+	}
+	for _, w := range r.wildcards {
+		if strings.HasSuffix(host, w.suffix) {
+			return w.ip, true
+		}
+	}
+	return "", false
+}
+
+func (r *Resolver) LookupIPs(ctx context.Context, host string) ([]string, error) {
+	if ip := net.ParseIP(host); ip != nil {
+		return []string{host}, nil
+	}
+	if ip, ok := r.Override(host); ok {
+		return []string{ip}, nil
+	}
+	if r != nil && r.dns != nil {
+		return r.dns.LookupHost(ctx, host)
+	}
+	return net.DefaultResolver.LookupHost(ctx, host)
+}
+
+func (r *Resolver) DialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	dialer := &net.Dialer{Timeout: 10 * time.Second, KeepAlive: 30 * time.Second}
+	if r != nil {
+		dialer = r.dialer
+	}
+
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, err
+	}
+	_, overridden := r.Override(host)
+	if net.ParseIP(host) != nil || (!overridden && (r == nil || r.dns == nil)) {
+		return dialer.DialContext(ctx, network, addr)
+	}
+
+	trace := httptrace.ContextClientTrace(ctx)
+	if trace != nil && trace.DNSStart != nil && !overridden {
+		trace.DNSStart(httptrace.DNSStartInfo{Host: host})
+	}
+	ips, err := r.LookupIPs(ctx, host)
+	if trace != nil && trace.DNSDone != nil && !overridden {
+		trace.DNSDone(httptrace.DNSDoneInfo{Err: err})
+	}
+	if err != nil {
+		return nil, err
+	}
+	var lastErr error
+	for _, ip := range ips {
+		conn, err := dialer.DialContext(ctx, network, net.JoinHostPort(ip, port))
+		if err == nil {
+			return conn, nil
+		}
+		lastErr = err
+	}
+	if lastErr == nil {
+		lastErr = fmt.Errorf("nenhum endereço para %s", host)
+	}
+	return nil, lastErr
+}
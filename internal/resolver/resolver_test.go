@@ -0,0 +1,82 @@
+package resolver
+
+import (
+	"context"
+	"net"
+	"testing"
+)
+
+func TestOverride(t *testing.T) {
+	r, err := New(map[string]string{"api.example.com": "10.0.0.5", "*.staging.example.com": "10.0.0.9"}, "")
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if ip, ok := r.Override("API.example.com."); !ok || ip != "10.0.0.5" {
+		t.Fatalf("expected exact override, got %q %v", ip, ok)
+	}
+	if ip, ok := r.Override("web.staging.example.com"); !ok || ip != "10.0.0.9" {
+		t.Fatalf("expected wildcard override, got %q %v", ip, ok)
+	}
+	if _, ok := r.Override("example.com"); ok {
+		t.Fatalf("unexpected override for example.com")
+	}
+}
+
+func TestOverride_LongestWildcard(t *testing.T) {
+	r, err := New(map[string]string{
+		"*.example.com":        "10.0.0.1",
+		"*.api.example.com":    "10.0.0.2",
+		"*.v1.api.example.com": "10.0.0.3",
+		"web.api.example.com":  "10.0.0.4",
+	}, "")
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	cases := map[string]string{
+		"www.example.com":        "10.0.0.1",
+		"x.api.example.com":      "10.0.0.2",
+		"a.b.v1.api.example.com": "10.0.0.3",
+		"web.api.example.com":    "10.0.0.4",
+	}
+	for i := 0; i < 20; i++ {
+		for host, want := range cases {
+			if ip, ok := r.Override(host); !ok || ip != want {
+				t.Fatalf("Override(%s) = %q %v, want %s", host, ip, ok, want)
+			}
+		}
+	}
+	if _, ok := r.Override("api.example.com."); !ok {
+		t.Fatalf("expected *.example.com to match api.example.com")
+	}
+}
+
+func TestDialContext_UsesOverride(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer ln.Close()
+	go func() {
+		c, err := ln.Accept()
+		if err == nil {
+			_ = c.Close()
+		}
+	}()
+
+	_, port, _ := net.SplitHostPort(ln.Addr().String())
+	r, err := New(map[string]string{"app.invalid": "127.0.0.1"}, "")
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	conn, err := r.DialContext(context.Background(), "tcp", net.JoinHostPort("app.invalid", port))
+	if err != nil {
+		t.Fatalf("DialContext: %v", err)
+	}
+	_ = conn.Close()
+}
+
+func TestNew_InvalidIP(t *testing.T) {
+	if _, err := New(map[string]string{"a.com": "not-an-ip"}, ""); err == nil {
+		t.Fatalf("expected error")
+	}
+}
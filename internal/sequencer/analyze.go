@@ -0,0 +1,256 @@
+package sequencer
+
+import (
+	"fmt"
+	"math"
+	"math/bits"
+	"sort"
+)
+
+const (
+	MinTokens    = 20
+	monobitSigma = 3.89
+	runsSigma    = 4.0
+	corrSigma    = 4.0
+	longRun      = 26
+	pokerMinBits = 320
+)
+
+type Position struct {
+	Index    int
+	Distinct int
+	Bits     int
+	Entropy  float64
+}
+
+type BitResult struct {
+	Char           int
+	Bit            int
+	Ones           int
+	Monobit        bool
+	Poker          bool
+	Runs           bool
+	LongRun        bool
+	CorrelatedWith int
+}
+
+func (b BitResult) Random() bool {
+	return b.Monobit && b.Poker && b.Runs && b.LongRun
+}
+
+func (b BitResult) Pass() bool {
+	return b.Random() && b.CorrelatedWith < 0
+}
+
+type Report struct {
+	Count         int
+	Duplicates    int
+	MinLen        int
+	MaxLen        int
+	Positions     []Position
+	CharEntropy   float64
+	Bits          []BitResult
+	EffectiveBits int
+	Warnings      []string
+}
+
+func (r Report) Quality() string {
+	switch {
+	case r.EffectiveBits < 32:
+		return "fraca"
+	case r.EffectiveBits < 64:
+		return "razoável"
+	case r.EffectiveBits < 100:
+		return "boa"
+	}
+	return "excelente"
+}
+
+func (r Report) Failed(test func(BitResult) bool) int {
+	n := 0
+	for _, b := range r.Bits {
+		if !test(b) {
+			n++
+		}
+	}
+	return n
+}
+
+func Analyze(tokens []string) (Report, error) {
+	n := len(tokens)
+	if n < MinTokens {
+		return Report{}, fmt.Errorf("sequencer: são necessários pelo menos %d tokens (veio %d)", MinTokens, n)
+	}
+	r := Report{Count: n, MinLen: len(tokens[0]), MaxLen: len(tokens[0])}
+	seen := make(map[string]bool, n)
+	for _, t := range tokens {
+		if seen[t] {
+			r.Duplicates++
+		}
+		seen[t] = true
+		r.MinLen = min(r.MinLen, len(t))
+		r.MaxLen = max(r.MaxLen, len(t))
+	}
+	if n < 100 {
+		r.Warnings = append(r.Warnings, fmt.Sprintf("amostra pequena (%d tokens): os testes ficam pouco confiáveis", n))
+	}
+	if r.MinLen != r.MaxLen {
+		r.Warnings = append(r.Warnings, fmt.Sprintf("tamanhos variam de %d a %d: só as primeiras %d posições são analisadas", r.MinLen, r.MaxLen, r.MinLen))
+	}
+	if r.Duplicates > 0 {
+		r.Warnings = append(r.Warnings, fmt.Sprintf("%d tokens repetidos", r.Duplicates))
+	}
+
+	var streams [][]bool
+	for pos := 0; pos < r.MinLen; pos++ {
+		counts := map[byte]int{}
+		for _, t := range tokens {
+			counts[t[pos]]++
+		}
+		charset := make([]byte, 0, len(counts))
+		for c := range counts {
+			charset = append(charset, c)
+		}
+		sort.Slice(charset, func(i, j int) bool { return charset[i] < charset[j] })
+		index := make(map[byte]int, len(charset))
+		for i, c := range charset {
+			index[c] = i
+		}
+
+		p := Position{Index: pos, Distinct: len(charset), Bits: bits.Len(uint(len(charset) - 1))}
+		for _, c := range counts {
+			f := float64(c) / float64(n)
+			p.Entropy -= f * math.Log2(f)
+		}
+		r.CharEntropy += p.Entropy
+		r.Positions = append(r.Positions, p)
+
+		for b := p.Bits - 1; b >= 0; b-- {
+			s := make([]bool, n)
+			for i, t := range tokens {
+				s[i] = index[t[pos]]>>b&1 == 1
+			}
+			streams = append(streams, s)
+			r.Bits = append(r.Bits, BitResult{Char: pos, Bit: b, CorrelatedWith: -1})
+		}
+	}
+
+	for i, s := range streams {
+		b := &r.Bits[i]
+		b.Ones = ones(s)
+		b.Monobit = monobit(b.Ones, n)
+		b.Poker = poker(s)
+		b.Runs, b.LongRun = runs(s)
+	}
+	correlate(r.Bits, streams)
+	for _, b := range r.Bits {
+		if b.Pass() {
+			r.EffectiveBits++
+		}
+	}
+	return r, nil
+}
+
+func ones(s []bool) int {
+	n := 0
+	for _, v := range s {
+		if v {
+			n++
+		}
+	}
+	return n
+}
+
+func monobit(ones, n int) bool {
+	return math.Abs(float64(ones)-float64(n)/2) <= monobitSigma*math.Sqrt(float64(n))/2
+}
+
+func poker(s []bool) bool {
+	if len(s) < pokerMinBits {
+		return true
+	}
+	k := len(s) / 4
+	var f [16]int
+	for i := 0; i < k; i++ {
+		v := 0
+		for _, bit := range s[i*4 : i*4+4] {
+			v <<= 1
+			if bit {
+				v |= 1
+			}
+		}
+		f[v]++
+	}
+	sum := 0.0
+	for _, c := range f {
+		sum += float64(c * c)
+	}
+	x := 16/float64(k)*sum - float64(k)
+	return x > 2.16 && x < 46.17
+}
+
+func runs(s []bool) (bool, bool) {
+	var counts [2][7]int
+	longest := 0
+	for i := 0; i < len(s); {
+		j := i
+		for j < len(s) && s[j] == s[i] {
+			j++
+		}
+		l := j - i
+		longest = max(longest, l)
+		v := 0
+		if s[i] {
+			v = 1
+		}
+		counts[v][min(l, 6)]++
+		i = j
+	}
+	n := float64(len(s))
+	ok := true
+	for l := 1; l <= 6; l++ {
+		e := n / math.Pow(2, float64(min(l, 5)+2))
+		for v := 0; v < 2; v++ {
+			if math.Abs(float64(counts[v][l])-e) > runsSigma*math.Sqrt(e)+1 {
+				ok = false
+			}
+		}
+	}
+	return ok, longest < longRun
+}
+
+func correlate(res []BitResult, streams [][]bool) {
+	if len(streams) == 0 {
+		return
+	}
+	n := len(streams[0])
+	for j := range res {
+		if !res[j].Random() {
+			continue
+		}
+		for i := 0; i < j; i++ {
+			if !res[i].Pass() {
+				continue
+			}
+			if math.Abs(phi(streams[i], streams[j], res[i].Ones, res[j].Ones))*math.Sqrt(float64(n)) > corrSigma {
+				res[j].CorrelatedWith = i
+				break
+			}
+		}
+	}
+}
+
+func phi(a, b []bool, onesA, onesB int) float64 {
+	n := len(a)
+	both := 0
+	for i := range a {
+		if a[i] && b[i] {
+			both++
+		}
+	}
+	den := float64(onesA) * float64(n-onesA) * float64(onesB) * float64(n-onesB)
+	if den == 0 {
+		return 0
+	}
+	return (float64(n*both) - float64(onesA)*float64(onesB)) / math.Sqrt(den)
+}
@@ -0,0 +1,88 @@
+package sequencer
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"burpui/internal/httpraw"
+	"burpui/internal/repeater"
+)
+
+type Options struct {
+	Count     int
+	Rate      float64
+	MaxErrors int
+	Repeater  repeater.Options
+}
+
+type Stats struct {
+	Sent      int
+	Collected int
+	Missing   int
+	Errors    int
+}
+
+func (s Stats) String() string {
+	return fmt.Sprintf("%d enviadas, %d tokens, %d sem token, %d erros", s.Sent, s.Collected, s.Missing, s.Errors)
+}
+
+func Collect(ctx context.Context, raw string, ex Extractor, opts Options, progress func(Stats)) ([]string, Stats, error) {
+	if opts.Count <= 0 {
+		opts.Count = 500
+	}
+	if opts.MaxErrors <= 0 {
+		opts.MaxErrors = 20
+	}
+
+	raw = httpraw.DelHeader(raw, "Accept-Encoding")
+	var tick <-chan time.Time
+	if opts.Rate > 0 {
+		t := time.NewTicker(time.Duration(float64(time.Second) / opts.Rate))
+		defer t.Stop()
+		tick = t.C
+	}
+
+	var st Stats
+	var tokens []string
+	failures := 0
+	for st.Collected < opts.Count {
+		if tick != nil && st.Sent > 0 {
+			select {
+			case <-ctx.Done():
+				return tokens, st, ctx.Err()
+			case <-tick:
+			}
+		}
+		if err := ctx.Err(); err != nil {
+			return tokens, st, err
+		}
+
+		st.Sent++
+		res, err := repeater.Send(raw, opts.Repeater)
+		switch {
+		case err != nil:
+			st.Errors++
+			failures++
+		default:
+			if tok, ok := ex.Extract(res.Header, res.Body); ok {
+				tokens = append(tokens, tok)
+				st.Collected++
+				failures = 0
+			} else {
+				st.Missing++
+				failures++
+			}
+		}
+		if failures >= opts.MaxErrors {
+			if err == nil {
+				err = fmt.Errorf("%s não encontrado na resposta", ex)
+			}
+			return tokens, st, fmt.Errorf("sequencer: %d falhas seguidas: %w", failures, err)
+		}
+		if progress != nil {
+			progress(st)
+		}
+	}
+	return tokens, st, nil
+}
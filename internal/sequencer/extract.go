@@ -0,0 +1,191 @@
+package sequencer
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+type Source int
+
+const (
+	SourceCookie Source = iota
+	SourceHeader
+	SourceBody
+	SourceJSON
+)
+
+type Extractor struct {
+	Source Source
+	Name   string
+	Re     *regexp.Regexp
+	Path   []string
+}
+
+func ParseExtractor(spec string) (Extractor, error) {
+	kind, arg, ok := strings.Cut(strings.TrimSpace(spec), ":")
+	arg = strings.TrimSpace(arg)
+	if !ok || arg == "" {
+		return Extractor{}, fmt.Errorf("sequencer: use cookie:NOME, header:NOME, body:REGEX ou json:CAMINHO")
+	}
+	switch strings.ToLower(kind) {
+	case "cookie":
+		return Extractor{Source: SourceCookie, Name: arg}, nil
+	case "header":
+		return Extractor{Source: SourceHeader, Name: http.CanonicalHeaderKey(arg)}, nil
+	case "body", "regex":
+		re, err := regexp.Compile(arg)
+		if err != nil {
+			return Extractor{}, fmt.Errorf("sequencer: regex inválida: %w", err)
+		}
+		return Extractor{Source: SourceBody, Re: re}, nil
+	case "json", "jsonpath":
+		path, err := parseJSONPath(arg)
+		if err != nil {
+			return Extractor{}, err
+		}
+		return Extractor{Source: SourceJSON, Name: arg, Path: path}, nil
+	}
+	return Extractor{}, fmt.Errorf("sequencer: origem desconhecida: %q", kind)
+}
+
+func (e Extractor) String() string {
+	switch e.Source {
+	case SourceCookie:
+		return "cookie:" + e.Name
+	case SourceHeader:
+		return "header:" + e.Name
+	case SourceJSON:
+		return "json:" + e.Name
+	default:
+		if e.Re == nil {
+			return "body:"
+		}
+		return "body:" + e.Re.String()
+	}
+}
+
+func (e Extractor) Extract(h http.Header, body []byte) (string, bool) {
+	switch e.Source {
+	case SourceCookie:
+		for _, c := range (&http.Response{Header: h}).Cookies() {
+			if c.Name == e.Name && c.Value != "" {
+				return c.Value, true
+			}
+		}
+	case SourceHeader:
+		if v := strings.TrimSpace(h.Get(e.Name)); v != "" {
+			return v, true
+		}
+	case SourceBody:
+		if e.Re == nil {
+			return "", false
+		}
+		m := e.Re.FindSubmatch(body)
+		if m == nil {
+			return "", false
+		}
+		v := m[0]
+		if len(m) > 1 {
+			v = m[1]
+		}
+		if len(v) > 0 {
+			return string(v), true
+		}
+	case SourceJSON:
+		return jsonLookup(body, e.Path)
+	}
+	return "", false
+}
+
+func parseJSONPath(p string) ([]string, error) {
+	p = strings.TrimPrefix(strings.TrimPrefix(p, "$"), ".")
+	var out []string
+	for p != "" {
+		switch {
+		case p[0] == '[':
+			end := strings.IndexByte(p, ']')
+			if end < 0 {
+				return nil, fmt.Errorf("sequencer: caminho json sem ]: %q", p)
+			}
+			seg := strings.Trim(p[1:end], `'"`)
+			if seg == "" {
+				return nil, fmt.Errorf("sequencer: índice vazio no caminho json")
+			}
+			out = append(out, seg)
+			p = strings.TrimPrefix(p[end+1:], ".")
+		default:
+			end := strings.IndexAny(p, ".[")
+			if end < 0 {
+				end = len(p)
+			}
+			out = append(out, p[:end])
+			p = strings.TrimPrefix(p[end:], ".")
+		}
+	}
+	if len(out) == 0 {
+		return nil, fmt.Errorf("sequencer: caminho json vazio")
+	}
+	return out, nil
+}
+
+func jsonLookup(body []byte, path []string) (string, bool) {
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return "", false
+	}
+	for _, seg := range path {
+		switch x := v.(type) {
+		case map[string]any:
+			next, ok := x[seg]
+			if !ok {
+				return "", false
+			}
+			v = next
+		case []any:
+			i, err := strconv.Atoi(seg)
+			if err != nil || i < 0 || i >= len(x) {
+				return "", false
+			}
+			v = x[i]
+		default:
+			return "", false
+		}
+	}
+	switch x := v.(type) {
+	case nil:
+		return "", false
+	case string:
+		return x, x != ""
+	case json.Number:
+		return x.String(), true
+	case bool:
+		return strconv.FormatBool(x), true
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return "", false
+	}
+	return string(b), true
+}
+
+func Guess(h http.Header) (Extractor, bool) {
+	cookies := (&http.Response{Header: h}).Cookies()
+	for _, c := range cookies {
+		if strings.Contains(strings.ToLower(c.Name), "sess") || strings.Contains(strings.ToLower(c.Name), "token") {
+			return Extractor{Source: SourceCookie, Name: c.Name}, true
+		}
+	}
+	for _, c := range cookies {
+		if len(c.Value) >= 8 {
+			return Extractor{Source: SourceCookie, Name: c.Name}, true
+		}
+	}
+	return Extractor{}, false
+}
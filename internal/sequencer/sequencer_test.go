@@ -0,0 +1,203 @@
+package sequencer
+
+import (
+	"compress/gzip"
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func randomTokens(n, length int, alphabet string, seed uint64) []string {
+	rng := rand.New(rand.NewPCG(seed, seed+1))
+	out := make([]string, n)
+	for i := range out {
+		b := make([]byte, length)
+		for j := range b {
+			b[j] = alphabet[rng.IntN(len(alphabet))]
+		}
+		out[i] = string(b)
+	}
+	return out
+}
+
+func TestAnalyze_Random(t *testing.T) {
+	r, err := Analyze(randomTokens(2000, 32, "0123456789abcdef", 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(r.Bits) != 128 || r.EffectiveBits < 120 || r.Quality() != "excelente" {
+		t.Fatalf("bits=%d effective=%d quality=%s", len(r.Bits), r.EffectiveBits, r.Quality())
+	}
+	if r.CharEntropy < 120 || r.Duplicates != 0 || len(r.Warnings) != 0 {
+		t.Fatalf("char entropy=%.1f dup=%d warnings=%v", r.CharEntropy, r.Duplicates, r.Warnings)
+	}
+}
+
+func TestAnalyze_Weak(t *testing.T) {
+	var counter []string
+	for i := 0; i < 1000; i++ {
+		counter = append(counter, fmt.Sprintf("SESS%08d", 50000+i))
+	}
+	r, err := Analyze(counter)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.EffectiveBits > 8 || r.Quality() != "fraca" {
+		t.Fatalf("counter tokens: effective=%d", r.EffectiveBits)
+	}
+	if r.Positions[0].Distinct != 1 || r.Positions[0].Bits != 0 {
+		t.Fatalf("constant prefix position = %+v", r.Positions[0])
+	}
+
+	short := randomTokens(1000, 4, "0123456789abcdef", 2)
+	for i := range short {
+		short[i] = "prefix-" + short[i]
+	}
+	if r, _ := Analyze(short); r.EffectiveBits > 16 || r.EffectiveBits < 12 || r.Duplicates == 0 {
+		t.Fatalf("16-bit tokens: effective=%d dup=%d", r.EffectiveBits, r.Duplicates)
+	}
+}
+
+func TestAnalyze_Correlated(t *testing.T) {
+	toks := randomTokens(2000, 8, "0123456789abcdef", 3)
+	for i, s := range toks {
+		toks[i] = s + s
+	}
+	r, _ := Analyze(toks)
+	if r.EffectiveBits > 34 || r.EffectiveBits < 28 {
+		t.Fatalf("duplicated halves: effective=%d", r.EffectiveBits)
+	}
+	correlated := 0
+	for _, b := range r.Bits {
+		if b.Random() && b.CorrelatedWith >= 0 {
+			correlated++
+		}
+	}
+	if correlated < 28 {
+		t.Fatalf("correlated bits = %d", correlated)
+	}
+}
+
+func TestAnalyze_Constant(t *testing.T) {
+	same := make([]string, 50)
+	for i := range same {
+		same[i] = "staticvalue"
+	}
+	r, err := Analyze(same)
+	if err != nil || len(r.Bits) != 0 || r.EffectiveBits != 0 || r.Duplicates != 49 {
+		t.Fatalf("report = %+v %v", r, err)
+	}
+
+	empty := make([]string, 50)
+	if r, err := Analyze(empty); err != nil || r.MinLen != 0 || r.EffectiveBits != 0 {
+		t.Fatalf("empty tokens: %+v %v", r, err)
+	}
+}
+
+func TestAnalyze_Errors(t *testing.T) {
+	if _, err := Analyze([]string{"a", "b"}); err == nil {
+		t.Fatalf("expected error for tiny sample")
+	}
+	toks := randomTokens(50, 10, "abc", 4)
+	toks[0] += "xyz"
+	r, err := Analyze(toks)
+	if err != nil || r.MaxLen != 13 || len(r.Warnings) < 2 {
+		t.Fatalf("report = %+v %v", r, err)
+	}
+}
+
+func TestParseAndExtract(t *testing.T) {
+	h := http.Header{"Set-Cookie": {"lang=pt; Path=/", "SESSIONID=abc123def456; HttpOnly"}, "X-Token": {"tok-1"}}
+	body := []byte(`{"csrf":"f00ba7","data":{"items":[{"id":7,"token":"t-9"}]}}`)
+	cases := []struct{ spec, want string }{
+		{"cookie:SESSIONID", "abc123def456"},
+		{"header:x-token", "tok-1"},
+		{`body:"csrf":"([0-9a-f]+)"`, "f00ba7"},
+		{`regex:f00\w+`, "f00ba7"},
+		{"json:$.data.items[0].token", "t-9"},
+		{"json:data.items.0.id", "7"},
+		{`json:$['csrf']`, "f00ba7"},
+	}
+	for _, c := range cases {
+		ex, err := ParseExtractor(c.spec)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, ok := ex.Extract(h, body); !ok || got != c.want {
+			t.Fatalf("%s = %q %v", c.spec, got, ok)
+		}
+	}
+	if _, ok := (Extractor{Source: SourceJSON, Path: []string{"data", "missing"}}).Extract(h, body); ok {
+		t.Fatalf("missing json path should not match")
+	}
+	for _, bad := range []string{"cookie", "xml:a", "body:(", "json:a[0"} {
+		if _, err := ParseExtractor(bad); err == nil {
+			t.Fatalf("expected error for %q", bad)
+		}
+	}
+	if ex, ok := Guess(h); !ok || ex.String() != "cookie:SESSIONID" {
+		t.Fatalf("Guess = %v %v", ex, ok)
+	}
+}
+
+func TestCollect(t *testing.T) {
+	var n atomic.Int64
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		i := n.Add(1)
+		if i%5 == 0 {
+			return
+		}
+		http.SetCookie(w, &http.Cookie{Name: "sid", Value: fmt.Sprintf("v%d", i)})
+	}))
+	defer srv.Close()
+
+	raw := "GET " + srv.URL + "/login HTTP/1.1\r\nHost: " + strings.TrimPrefix(srv.URL, "http://") + "\r\n\r\n"
+	ex, _ := ParseExtractor("cookie:sid")
+	var last Stats
+	toks, st, err := Collect(context.Background(), raw, ex, Options{Count: 8}, func(s Stats) { last = s })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(toks) != 8 || st.Missing != 1 || st.Sent != 9 || last != st || toks[0] != "v1" {
+		t.Fatalf("tokens=%v stats=%+v", toks, st)
+	}
+
+	ex, _ = ParseExtractor("header:X-None")
+	if _, st, err := Collect(context.Background(), raw, ex, Options{Count: 5, MaxErrors: 3}, nil); err == nil || st.Missing != 3 {
+		t.Fatalf("expected abort after missing tokens, got %+v %v", st, err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, _, err := Collect(ctx, raw, ex, Options{Count: 5}, nil); err != context.Canceled {
+		t.Fatalf("expected cancel, got %v", err)
+	}
+}
+
+func TestCollect_Compressed(t *testing.T) {
+	var n atomic.Int64
+	pad := strings.Repeat("x", 200)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.Contains(r.Header.Get("Accept-Encoding"), "gzip") {
+			fmt.Fprintf(w, `{"pad":%q,"token":"t%d"}`, pad, n.Add(1))
+			return
+		}
+		w.Header().Set("Content-Encoding", "gzip")
+		zw := gzip.NewWriter(w)
+		defer zw.Close()
+		fmt.Fprintf(zw, `{"pad":%q,"token":"t%d"}`, pad, n.Add(1))
+	}))
+	defer srv.Close()
+
+	raw := "GET " + srv.URL + "/ HTTP/1.1\r\nHost: " + strings.TrimPrefix(srv.URL, "http://") + "\r\nAccept-Encoding: gzip, br\r\n\r\n"
+	ex, _ := ParseExtractor("json:$.token")
+	toks, st, err := Collect(context.Background(), raw, ex, Options{Count: 3, MaxErrors: 2}, nil)
+	if err != nil || len(toks) != 3 || toks[0] != "t1" {
+		t.Fatalf("tokens=%v stats=%+v err=%v", toks, st, err)
+	}
+}
@@ -0,0 +1,132 @@
+package sitemap
+
+import (
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+)
+
+type ScopeRule struct {
+	Prefix  string
+	Exclude bool
+}
+
+type Scope struct {
+	mu    sync.RWMutex
+	rules map[string]bool
+}
+
+func NewScope() *Scope {
+	return &Scope{rules: map[string]bool{}}
+}
+
+func (s *Scope) Include(prefix string) {
+	s.set(prefix, false)
+}
+
+func (s *Scope) Exclude(prefix string) {
+	s.set(prefix, true)
+}
+
+func (s *Scope) set(prefix string, exclude bool) {
+	prefix = normalizePrefix(prefix)
+	if prefix == "" {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.rules[prefix] = exclude
+}
+
+func (s *Scope) Remove(prefix string) bool {
+	prefix = normalizePrefix(prefix)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.rules[prefix]; !ok {
+		return false
+	}
+	delete(s.rules, prefix)
+	return true
+}
+
+func (s *Scope) Rule(prefix string) (ScopeRule, bool) {
+	prefix = normalizePrefix(prefix)
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	exclude, ok := s.rules[prefix]
+	return ScopeRule{Prefix: prefix, Exclude: exclude}, ok
+}
+
+func (s *Scope) Rules() []ScopeRule {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	out := make([]ScopeRule, 0, len(s.rules))
+	for p, ex := range s.rules {
+		out = append(out, ScopeRule{Prefix: p, Exclude: ex})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Prefix < out[j].Prefix })
+	return out
+}
+
+func (s *Scope) Empty() bool {
+	if s == nil {
+		return true
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.rules) == 0
+}
+
+func (s *Scope) InScope(raw string) bool {
+	if s == nil {
+		return true
+	}
+	key := scopeKey(raw)
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	best, exclude, includes := -1, false, false
+	for p, ex := range s.rules {
+		if !ex {
+			includes = true
+		}
+		k, spec := key, p
+		if i := strings.Index(p, "://"); i >= 0 {
+			spec = p[i+3:]
+		} else if i := strings.Index(k, "://"); i >= 0 {
+			k = k[i+3:]
+		}
+		if (k == p || strings.HasPrefix(k, p+"/")) && len(spec) > best {
+			best, exclude = len(spec), ex
+		}
+	}
+	if best < 0 {
+		return !includes
+	}
+	return !exclude
+}
+
+func normalizePrefix(p string) string {
+	p = strings.TrimSpace(p)
+	if strings.Contains(p, "://") {
+		return strings.TrimRight(scopeKey(p), "/")
+	}
+	host, path, _ := strings.Cut(p, "/")
+	host = strings.ToLower(host)
+	if path = strings.Trim(path, "/"); path != "" {
+		return host + "/" + path
+	}
+	return host
+}
+
+func scopeKey(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return strings.ToLower(raw)
+	}
+	key := origin(u)
+	if segs := segments(u.EscapedPath()); len(segs) > 0 {
+		key += "/" + strings.Join(segs, "/")
+	}
+	return key
+}
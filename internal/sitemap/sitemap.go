@@ -0,0 +1,278 @@
+package sitemap
+
+import (
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+
+	"burpui/internal/proxy"
+)
+
+type Node struct {
+	Name     string
+	URL      string
+	Depth    int
+	Count    int
+	Statuses map[int]int
+	Methods  map[string]bool
+	Params   map[string]bool
+	FlowIDs  []int64
+	children map[string]*Node
+}
+
+type Tree struct {
+	hosts map[string]*Node
+	seen  map[int64]bool
+}
+
+func New() *Tree {
+	return &Tree{hosts: map[string]*Node{}, seen: map[int64]bool{}}
+}
+
+func (t *Tree) AddFlow(f *proxy.Flow) bool {
+	if f == nil || f.Pending || f.Method == http.MethodConnect || t.seen[f.ID] {
+		return false
+	}
+	u, err := url.Parse(f.URL)
+	if err != nil || u.Host == "" {
+		return false
+	}
+	t.seen[f.ID] = true
+
+	n, _ := t.walk(u, func(n *Node) {
+		n.Count++
+		if f.StatusCode != 0 {
+			n.Statuses[f.StatusCode]++
+		}
+	})
+	n.Methods[f.Method] = true
+	n.FlowIDs = append(n.FlowIDs, f.ID)
+	for k := range u.Query() {
+		n.Params[k] = true
+	}
+	for _, k := range bodyParams(f) {
+		n.Params[k] = true
+	}
+	return true
+}
+
+func (t *Tree) AddURL(raw string, params ...string) bool {
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") {
+		return false
+	}
+	n, created := t.walk(u, func(*Node) {})
+	for k := range u.Query() {
+		n.Params[k] = true
+	}
+	for _, k := range params {
+		n.Params[k] = true
+	}
+	return created
+}
+
+func (t *Tree) walk(u *url.URL, visit func(*Node)) (*Node, bool) {
+	base := origin(u)
+	created := false
+	n := t.hosts[base]
+	if n == nil {
+		n = newNode(base, base, 0)
+		t.hosts[base] = n
+		created = true
+	}
+	visit(n)
+	prefix := base
+	for _, seg := range segments(u.EscapedPath()) {
+		prefix += "/" + seg
+		c := n.children[seg]
+		created = c == nil
+		if c == nil {
+			c = newNode(seg, prefix, n.Depth+1)
+			n.children[seg] = c
+		}
+		visit(c)
+		n = c
+	}
+	return n, created
+}
+
+func newNode(name, u string, depth int) *Node {
+	return &Node{
+		Name:     name,
+		URL:      u,
+		Depth:    depth,
+		Statuses: map[int]int{},
+		Methods:  map[string]bool{},
+		Params:   map[string]bool{},
+		children: map[string]*Node{},
+	}
+}
+
+func (t *Tree) Find(raw string) *Node {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return nil
+	}
+	n := t.hosts[origin(u)]
+	for _, seg := range segments(u.EscapedPath()) {
+		if n == nil {
+			return nil
+		}
+		n = n.children[seg]
+	}
+	return n
+}
+
+func (t *Tree) Hosts() []*Node {
+	return sortNodes(t.hosts)
+}
+
+func (t *Tree) Len() int {
+	return len(t.seen)
+}
+
+func (n *Node) Children() []*Node {
+	return sortNodes(n.children)
+}
+
+func (n *Node) Discovered() bool {
+	return n.Count == 0
+}
+
+func (n *Node) HasChildren() bool {
+	return len(n.children) > 0
+}
+
+func (n *Node) Walk(fn func(*Node) bool) {
+	if !fn(n) {
+		return
+	}
+	for _, c := range n.Children() {
+		c.Walk(fn)
+	}
+}
+
+func (n *Node) Endpoints() []*Node {
+	var out []*Node
+	n.Walk(func(c *Node) bool {
+		if len(c.FlowIDs) > 0 || !c.HasChildren() {
+			out = append(out, c)
+		}
+		return true
+	})
+	return out
+}
+
+func (n *Node) LastFlowID() int64 {
+	if len(n.FlowIDs) == 0 {
+		return 0
+	}
+	last := n.FlowIDs[0]
+	for _, id := range n.FlowIDs[1:] {
+		if id > last {
+			last = id
+		}
+	}
+	return last
+}
+
+func (n *Node) StatusList() []int {
+	out := make([]int, 0, len(n.Statuses))
+	for s := range n.Statuses {
+		out = append(out, s)
+	}
+	sort.Ints(out)
+	return out
+}
+
+func (n *Node) MethodList() []string {
+	return sortedKeys(n.Methods)
+}
+
+func (n *Node) ParamList() []string {
+	return sortedKeys(n.Params)
+}
+
+func sortNodes(m map[string]*Node) []*Node {
+	out := make([]*Node, 0, len(m))
+	for _, n := range m {
+		out = append(out, n)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+func sortedKeys(m map[string]bool) []string {
+	out := make([]string, 0, len(m))
+	for k := range m {
+		out = append(out, k)
+	}
+	sort.Strings(out)
+	return out
+}
+
+func origin(u *url.URL) string {
+	scheme := strings.ToLower(u.Scheme)
+	if scheme == "" {
+		scheme = "http"
+	}
+	host := strings.ToLower(u.Hostname())
+	port := u.Port()
+	if port != "" && !(scheme == "http" && port == "80") && !(scheme == "https" && port == "443") {
+		host += ":" + port
+	}
+	return scheme + "://" + host
+}
+
+func segments(p string) []string {
+	var out []string
+	for _, s := range strings.Split(p, "/") {
+		if s != "" {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+func bodyParams(f *proxy.Flow) []string {
+	if len(f.RequestBody) == 0 || f.RequestHeader == nil {
+		return nil
+	}
+	mt, _, _ := mime.ParseMediaType(f.RequestHeader.Get("Content-Type"))
+	if mt != "application/x-www-form-urlencoded" {
+		return nil
+	}
+	vals, err := url.ParseQuery(string(f.RequestBody))
+	if err != nil {
+		return nil
+	}
+	out := make([]string, 0, len(vals))
+	for k := range vals {
+		out = append(out, k)
+	}
+	return out
+}
+
+func (n *Node) WriteEndpoints(w io.Writer) error {
+	for _, e := range n.Endpoints() {
+		methods := strings.Join(e.MethodList(), ",")
+		if methods == "" {
+			methods = "-"
+		}
+		line := methods + " " + e.URL
+		if e.Depth == 0 {
+			line += "/"
+		}
+		if params := e.ParamList(); len(params) > 0 {
+			line += " [" + strings.Join(params, ",") + "]"
+		}
+		if _, err := fmt.Fprintln(w, line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+package sitemap
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"burpui/internal/proxy"
+)
+
+func testTree() *Tree {
+	t := New()
+	t.AddFlow(&proxy.Flow{ID: 1, Method: "GET", URL: "https://app.example.com/api/users?page=2&sort=name", StatusCode: 200})
+	t.AddFlow(&proxy.Flow{ID: 2, Method: "POST", URL: "https://app.example.com/api/login", StatusCode: 401,
+		RequestHeader: http.Header{"Content-Type": {"application/x-www-form-urlencoded"}}, RequestBody: []byte("user=a&pass=b")})
+	t.AddFlow(&proxy.Flow{ID: 3, Method: "GET", URL: "https://app.example.com/", StatusCode: 200})
+	t.AddFlow(&proxy.Flow{ID: 4, Method: "GET", URL: "https://app.example.com/api/users?page=3", StatusCode: 200})
+	t.AddFlow(&proxy.Flow{ID: 5, Method: "GET", URL: "https://app.example.com/pending", Pending: true})
+	t.AddFlow(&proxy.Flow{ID: 4, Method: "GET", URL: "https://app.example.com/api/users", StatusCode: 200})
+	t.AddURL("https://app.example.com/admin/panel")
+	t.AddURL("https://app.example.com/static/app.js")
+	t.AddURL("https://cdn.example.com/lib.js")
+	t.AddURL("https://app.example.com/api/login", "csrf")
+	return t
+}
+
+func TestTree_CountsStatusesParams(t *testing.T) {
+	tr := testTree()
+	if tr.Len() != 4 {
+		t.Fatalf("Len = %d, want 4", tr.Len())
+	}
+	host := tr.Find("https://app.example.com")
+	if host == nil || host.Count != 4 {
+		t.Fatalf("host node = %+v", host)
+	}
+	api := tr.Find("https://app.example.com/api")
+	if api.Count != 3 || api.Statuses[200] != 2 || api.Statuses[401] != 1 {
+		t.Fatalf("api node = count %d statuses %v", api.Count, api.Statuses)
+	}
+	users := tr.Find("https://app.example.com/api/users")
+	if got := strings.Join(users.ParamList(), ","); got != "page,sort" {
+		t.Fatalf("users params = %q", got)
+	}
+	if users.LastFlowID() != 4 {
+		t.Fatalf("LastFlowID = %d", users.LastFlowID())
+	}
+	login := tr.Find("https://app.example.com/api/login")
+	if got := strings.Join(login.ParamList(), ","); got != "csrf,pass,user" {
+		t.Fatalf("login params = %q", got)
+	}
+	if tr.Find("https://app.example.com/pending") != nil {
+		t.Fatalf("pending flow should not be added")
+	}
+}
+
+func TestTree_DiscoveredLinks(t *testing.T) {
+	tr := testTree()
+	for _, u := range []string{"https://app.example.com/admin/panel", "https://app.example.com/static/app.js", "https://cdn.example.com/lib.js"} {
+		n := tr.Find(u)
+		if n == nil || !n.Discovered() {
+			t.Fatalf("%s: expected discovered node, got %+v", u, n)
+		}
+	}
+	if tr.Find("https://app.example.com/api").Discovered() {
+		t.Fatalf("requested node marked as discovered")
+	}
+	if len(tr.Hosts()) != 2 {
+		t.Fatalf("hosts = %d, want 2", len(tr.Hosts()))
+	}
+
+	var b strings.Builder
+	if err := tr.Find("https://app.example.com").WriteEndpoints(&b); err != nil {
+		t.Fatal(err)
+	}
+	want := "GET https://app.example.com/\n- https://app.example.com/admin/panel\nPOST https://app.example.com/api/login [csrf,pass,user]\nGET https://app.example.com/api/users [page,sort]\n- https://app.example.com/static/app.js\n"
+	if b.String() != want {
+		t.Fatalf("endpoints:\n%s\nwant:\n%s", b.String(), want)
+	}
+}
+
+func TestScope(t *testing.T) {
+	s := NewScope()
+	if !s.InScope("https://any.test/x") {
+		t.Fatalf("empty scope should include everything")
+	}
+	s.Include("https://app.example.com")
+	s.Exclude("app.example.com/logout")
+	cases := map[string]bool{
+		"https://app.example.com/":          true,
+		"https://app.example.com/api/x?a=1": true,
+		"https://app.example.com/logout":    false,
+		"https://app.example.com/logout/x":  false,
+		"https://app.example.com/logouts":   true,
+		"http://app.example.com/":           false,
+		"https://cdn.example.com/lib.js":    false,
+	}
+	for u, want := range cases {
+		if got := s.InScope(u); got != want {
+			t.Fatalf("InScope(%q) = %v, want %v", u, got, want)
+		}
+	}
+	if !s.Remove("app.example.com/logout/") || !s.InScope("https://app.example.com/logout") {
+		t.Fatalf("Remove did not drop the exclusion")
+	}
+}
@@ -0,0 +1,377 @@
+package tui
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/table"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"burpui/internal/authz"
+)
+
+type authzUpdateMsg struct{}
+
+func listenForAuthz(a *authz.Matrix) tea.Cmd {
+	return func() tea.Msg {
+		<-a.Updates()
+		return authzUpdateMsg{}
+	}
+}
+
+func (m *Model) authzUpdated() tea.Cmd {
+	bypass := 0
+	for _, r := range m.cfg.Authz.Rows() {
+		if r.Worst() == authz.Bypass {
+			bypass++
+		}
+	}
+	var cmd tea.Cmd
+	if bypass > m.azBypass {
+		cmd = toastCmd(fmt.Sprintf("authz: %d possíveis bypass (A abre a matriz)", bypass))
+	}
+	m.azBypass = bypass
+	if m.scr == screenAuthz {
+		m.refreshAuthz()
+	}
+	return tea.Batch(cmd, listenForAuthz(m.cfg.Authz))
+}
+
+func (m *Model) openAuthz() {
+	m.scr = screenAuthz
+	m.azAdding = false
+	m.azInput.SetValue("")
+	m.azInput.Blur()
+	m.layout()
+	m.refreshAuthz()
+}
+
+func (m Model) authzRows() []authz.Row {
+	rows := m.cfg.Authz.Rows()
+	out := make([]authz.Row, 0, len(rows))
+	for i := len(rows) - 1; i >= 0; i-- {
+		if m.azSuspicious && rows[i].Worst() < authz.Unclear {
+			continue
+		}
+		out = append(out, rows[i])
+	}
+	return out
+}
+
+func (m *Model) refreshAuthz() {
+	if m.cfg.Authz == nil {
+		return
+	}
+	selected := int64(0)
+	if i := m.azTable.Cursor(); i >= 0 && i < len(m.azIDs) {
+		selected = m.azIDs[i]
+	}
+
+	sessions := m.cfg.Authz.Sessions()
+	const idW, methodW, statusW, cellW = 5, 7, 6, 12
+	urlW := m.azTable.Width() - idW - methodW - statusW - len(sessions)*(cellW+2) - 8
+	if urlW < 16 {
+		urlW = 16
+	}
+	cols := []table.Column{
+		{Title: "#", Width: idW},
+		{Title: "Método", Width: methodW},
+		{Title: "URL", Width: urlW},
+		{Title: "Orig.", Width: statusW},
+	}
+	for _, s := range sessions {
+		title := s.Name
+		if !s.Enabled {
+			title = "(" + title + ")"
+		}
+		cols = append(cols, table.Column{Title: title, Width: cellW})
+	}
+
+	rows := m.authzRows()
+	trs := make([]table.Row, 0, len(rows))
+	m.azIDs = m.azIDs[:0]
+	cursor := 0
+	for _, r := range rows {
+		tr := table.Row{strconv.FormatInt(r.FlowID, 10), r.Method, r.URL, strconv.Itoa(r.Status)}
+		for _, s := range sessions {
+			tr = append(tr, authzCellText(r, s.Name))
+		}
+		if r.FlowID == selected {
+			cursor = len(trs)
+		}
+		trs = append(trs, tr)
+		m.azIDs = append(m.azIDs, r.FlowID)
+	}
+
+	m.azTable.SetRows(nil)
+	m.azTable.SetColumns(cols)
+	m.azTable.SetRows(trs)
+	m.azTable.SetCursor(cursor)
+	m.updateAuthzDetail()
+}
+
+func findCell(r authz.Row, session string) (authz.Cell, bool) {
+	for _, c := range r.Cells {
+		if c.Session == session {
+			return c, true
+		}
+	}
+	return authz.Cell{}, false
+}
+
+func authzCellText(r authz.Row, session string) string {
+	c, ok := findCell(r, session)
+	if !ok {
+		return ""
+	}
+	switch c.Verdict {
+	case authz.Pending:
+		return "…"
+	case authz.Failed:
+		return "✗ erro"
+	case authz.Enforced:
+		return fmt.Sprintf("✓ %d", c.Status)
+	case authz.Unclear:
+		return fmt.Sprintf("? %d %.0f%%", c.Status, c.Similarity*100)
+	}
+	return fmt.Sprintf("⚠ %d %.0f%%", c.Status, c.Similarity*100)
+}
+
+func (m *Model) selectedAuthzRow() (authz.Row, bool) {
+	i := m.azTable.Cursor()
+	if i < 0 || i >= len(m.azIDs) {
+		return authz.Row{}, false
+	}
+	for _, r := range m.cfg.Authz.Rows() {
+		if r.FlowID == m.azIDs[i] {
+			return r, true
+		}
+	}
+	return authz.Row{}, false
+}
+
+func (m *Model) updateAuthzDetail() {
+	var b strings.Builder
+	for i, s := range m.cfg.Authz.Sessions() {
+		mark := m.styles.diffAdd.Render("✓")
+		if !s.Enabled {
+			mark = m.styles.diffDel.Render("✗")
+		}
+		b.WriteString(fmt.Sprintf("%s %d %s  ", mark, i+1, s.Name))
+	}
+	b.WriteString("\n\n")
+
+	r, ok := m.selectedAuthzRow()
+	if !ok {
+		b.WriteString(m.styles.dim.Render("Os fluxos capturados no escopo são reenviados com cada sessão. Use --authz-session ou 'a' para cadastrar sessões."))
+		m.azView.SetContent(b.String())
+		return
+	}
+	b.WriteString(fmt.Sprintf("#%d %s %s\n", r.FlowID, r.Method, r.URL))
+	b.WriteString(m.styles.dim.Render(fmt.Sprintf("original: %d, %d bytes", r.Status, r.Length)))
+	b.WriteString("\n\n")
+	for _, c := range r.Cells {
+		verdict := c.Verdict.String()
+		switch c.Verdict {
+		case authz.Bypass:
+			verdict = m.styles.badgeWarn.Render(verdict)
+		case authz.Unclear:
+			verdict = m.styles.key.Render(verdict)
+		case authz.Failed:
+			verdict = m.styles.err.Render(verdict)
+		}
+		switch {
+		case c.Verdict == authz.Pending:
+			b.WriteString(fmt.Sprintf("%-12s %s\n", c.Session, m.styles.dim.Render("aguardando")))
+		case c.Err != "":
+			b.WriteString(fmt.Sprintf("%-12s %s %s\n", c.Session, verdict, m.styles.dim.Render(c.Err)))
+		default:
+			b.WriteString(fmt.Sprintf("%-12s %d  %6d bytes  %3.0f%% igual  %s\n", c.Session, c.Status, c.Length, c.Similarity*100, verdict))
+		}
+	}
+	m.azView.SetContent(b.String())
+}
+
+func (m Model) updateAuthz(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.azAdding {
+		switch {
+		case key.Matches(msg, m.keys.Back):
+			m.azAdding = false
+			m.azInput.SetValue("")
+			m.azInput.Blur()
+			return m, nil
+		case key.Matches(msg, m.keys.Apply):
+			s, err := authz.ParseSession(strings.TrimSpace(m.azInput.Value()))
+			if err != nil {
+				return m, toastCmd(err.Error())
+			}
+			if err := m.cfg.Authz.AddSession(s); err != nil {
+				return m, toastCmd(err.Error())
+			}
+			m.cfg.Authz.SetEnabled(true)
+			m.azAdding = false
+			m.azInput.SetValue("")
+			m.azInput.Blur()
+			m.refreshAuthz()
+			return m, toastCmd("sessão adicionada: " + s.Name)
+		}
+
+		var cmd tea.Cmd
+		m.azInput, cmd = m.azInput.Update(msg)
+		return m, cmd
+	}
+
+	switch {
+	case key.Matches(msg, m.keys.Back):
+		m.scr = screenMain
+		m.layout()
+		return m, nil
+	case key.Matches(msg, m.keys.Add):
+		m.azAdding = true
+		m.azInput.SetValue("")
+		m.azInput.Focus()
+		return m, nil
+	case key.Matches(msg, m.keys.Pause):
+		on := !m.cfg.Authz.Enabled()
+		m.cfg.Authz.SetEnabled(on)
+		if on {
+			return m, toastCmd("matriz de autorização ativa")
+		}
+		return m, toastCmd("matriz de autorização pausada")
+	case key.Matches(msg, m.keys.ToggleSession):
+		i, _ := strconv.Atoi(msg.String())
+		sessions := m.cfg.Authz.Sessions()
+		if i < 1 || i > len(sessions) {
+			return m, nil
+		}
+		on := m.cfg.Authz.ToggleSession(sessions[i-1].Name)
+		m.refreshAuthz()
+		if on {
+			return m, toastCmd("sessão ativada: " + sessions[i-1].Name)
+		}
+		return m, toastCmd("sessão desativada: " + sessions[i-1].Name)
+	case key.Matches(msg, m.keys.Suspicious):
+		m.azSuspicious = !m.azSuspicious
+		m.refreshAuthz()
+		return m, nil
+	case key.Matches(msg, m.keys.Apply):
+		r, ok := m.selectedAuthzRow()
+		if !ok {
+			return m, nil
+		}
+		if !m.cfg.Authz.Replay(r.FlowID) {
+			return m, toastCmd("nada a reenviar (nenhuma sessão ativa?)")
+		}
+		m.refreshAuthz()
+		return m, toastCmd(fmt.Sprintf("reenviando #%d", r.FlowID))
+	case key.Matches(msg, m.keys.Repeater):
+		r, ok := m.selectedAuthzRow()
+		if !ok {
+			return m, nil
+		}
+		f := m.flows[r.FlowID]
+		if f == nil {
+			return m, nil
+		}
+		m.scr = screenRepeater
+		m.editorTitle = "Repeater"
+		m.status = "Ctrl+S envia | Esc volta"
+		m.resp.SetContent("")
+		m.editor.SetValue(renderRawRequest(f))
+		m.editor.Focus()
+		m.layout()
+		return m, nil
+	case key.Matches(msg, m.keys.Export):
+		path, err := exportAuthz(m.cfg.Authz)
+		if err != nil {
+			return m, toastCmd("erro ao exportar")
+		}
+		return m, toastCmd("matriz exportada: " + path)
+	case key.Matches(msg, m.keys.ScrollUp):
+		m.azView.HalfPageUp()
+		return m, nil
+	case key.Matches(msg, m.keys.ScrollDown):
+		m.azView.HalfPageDown()
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.azTable, cmd = m.azTable.Update(msg)
+	m.updateAuthzDetail()
+	return m, cmd
+}
+
+func (m Model) viewAuthz() string {
+	state := m.styles.badgeOn.Render("ATIVA")
+	if !m.cfg.Authz.Enabled() {
+		state = m.styles.badgeOff.Render("PAUSADA")
+	}
+	info := fmt.Sprintf("%d requisições", len(m.azIDs))
+	if m.azSuspicious {
+		info += " (só suspeitas)"
+	}
+	header := lipgloss.JoinHorizontal(lipgloss.Left,
+		m.styles.title.Render("Matriz de autorização"),
+		" ",
+		state,
+		" ",
+		m.styles.dim.Render(info),
+	)
+	if m.azBypass > 0 {
+		header = lipgloss.JoinHorizontal(lipgloss.Left, header, " ", m.styles.badgeWarn.Render(fmt.Sprintf("%d BYPASS?", m.azBypass)))
+	}
+
+	tbl := m.styles.border.Render(m.azTable.View())
+	detail := m.styles.border.Render(m.azView.View())
+	input := m.styles.border.Render(m.azInput.View())
+	if !m.azAdding {
+		input = m.styles.border.Render(m.styles.dim.Render("pressione 'a' para adicionar sessão (nome=Header: valor|Header: valor)"))
+	}
+	footer := m.viewFooter()
+
+	return m.styles.app.Render(lipgloss.JoinVertical(lipgloss.Left, header, tbl, detail, input, footer))
+}
+
+func exportAuthz(a *authz.Matrix) (string, error) {
+	dir := filepath.Join("exports")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, fmt.Sprintf("authz-%s.csv", time.Now().Format("20060102-150405")))
+	f, err := os.Create(path)
+	if err != nil {
+		return "", err
+	}
+	w := csv.NewWriter(f)
+	sessions := a.Sessions()
+	head := []string{"id", "metodo", "url", "status"}
+	for _, s := range sessions {
+		head = append(head, s.Name+" status", s.Name+" similaridade", s.Name+" veredito")
+	}
+	w.Write(head)
+	for _, r := range a.Rows() {
+		rec := []string{strconv.FormatInt(r.FlowID, 10), r.Method, r.URL, strconv.Itoa(r.Status)}
+		for _, s := range sessions {
+			c, ok := findCell(r, s.Name)
+			if !ok {
+				rec = append(rec, "", "", "")
+				continue
+			}
+			rec = append(rec, strconv.Itoa(c.Status), fmt.Sprintf("%.2f", c.Similarity), c.Verdict.String())
+		}
+		w.Write(rec)
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		f.Close()
+		return "", err
+	}
+	return path, f.Close()
+}
@@ -0,0 +1,278 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"burpui/internal/comparer"
+	"burpui/internal/httpraw"
+	"burpui/internal/proxy"
+	"burpui/internal/repeater"
+)
+
+type cmpEntry struct {
+	label string
+	req   comparer.Side
+	resp  comparer.Side
+}
+
+func flowEntry(f *proxy.Flow) *cmpEntry {
+	label := fmt.Sprintf("#%d", f.ID)
+	return &cmpEntry{
+		label: label,
+		req:   comparer.Side{Label: label, Start: f.Method + " " + f.URL, Header: f.RequestHeader, Body: f.RequestBody}.Decoded(),
+		resp:  comparer.Side{Label: label, Start: fmt.Sprintf("HTTP %d", f.StatusCode), Status: f.StatusCode, Header: f.ResponseHeader, Body: f.ResponseBody}.Decoded(),
+	}
+}
+
+func repeaterEntry(raw string, res *repeater.Result) *cmpEntry {
+	e := &cmpEntry{label: "Repeater"}
+	if req, body, err := httpraw.ParseRequest(raw); err == nil {
+		e.req = comparer.Side{Label: e.label, Start: req.Method + " " + req.URL.String(), Header: req.Header, Body: body}.Decoded()
+	}
+	e.resp = comparer.Side{Label: e.label, Start: "HTTP " + res.Status, Status: res.StatusCode, Header: res.Header, Body: res.Body}.Decoded()
+	return e
+}
+
+func (m *Model) addToComparer(e *cmpEntry) (tea.Cmd, bool) {
+	switch {
+	case m.cmpA == nil:
+		m.cmpA = e
+		return toastCmd(fmt.Sprintf("Comparer: A = %s (escolha o B)", e.label)), false
+	case m.cmpB == nil:
+		m.cmpB = e
+	default:
+		m.cmpA, m.cmpB = m.cmpB, e
+	}
+	return nil, true
+}
+
+func (m *Model) refreshComparer() {
+	if m.cmpA == nil || m.cmpB == nil {
+		m.cmpView.SetContent(m.styles.dim.Render("Escolha dois fluxos com C no histórico (ou Ctrl+R no Repeater)"))
+		return
+	}
+	a, b := m.cmpA.resp, m.cmpB.resp
+	if !m.cmpResponse {
+		a, b = m.cmpA.req, m.cmpB.req
+	}
+
+	headers := comparer.Headers(a.Header, b.Header)
+	var ops []comparer.Op
+	if m.cmpBytes {
+		ops = comparer.Bytes(a.Body, b.Body)
+	} else {
+		ops = comparer.Words(string(a.Body), string(b.Body))
+	}
+	sum := comparer.Summarize(a, b, headers, ops)
+
+	var out strings.Builder
+	if sum.Identical() && a.Start == b.Start {
+		out.WriteString(m.styles.badgeOn.Render("IDÊNTICOS"))
+		out.WriteString("\n")
+	}
+	if m.cmpResponse {
+		out.WriteString(fmt.Sprintf("Status: %d → %d", sum.StatusA, sum.StatusB))
+		if sum.StatusA != sum.StatusB {
+			out.WriteString(" " + m.styles.badgeWarn.Render("DIFERENTE"))
+		}
+		out.WriteString("\n")
+	}
+	out.WriteString(fmt.Sprintf("Tamanho: %d → %d (%+d)\n", sum.LenA, sum.LenB, sum.LenB-sum.LenA))
+	out.WriteString(fmt.Sprintf("Headers diferentes: %d | trechos alterados no body: %d\n", sum.HeadersChanged, sum.BodyChanges))
+	if a.Start != b.Start {
+		out.WriteString(m.styles.diffDel.Render("A: "+a.Start) + "\n")
+		out.WriteString(m.styles.diffAdd.Render("B: "+b.Start) + "\n")
+	}
+
+	out.WriteString("\n")
+	out.WriteString(m.styles.dim.Render("Headers"))
+	out.WriteString("\n")
+	out.WriteString(m.renderHeaderRows(headers))
+
+	out.WriteString("\n")
+	out.WriteString(m.styles.dim.Render("Body"))
+	out.WriteString("\n")
+	if m.cmpBytes {
+		out.WriteString(m.renderByteOps(ops))
+	} else {
+		out.WriteString(m.renderWordOps(ops))
+	}
+	m.cmpView.SetContent(out.String())
+}
+
+func (m Model) renderHeaderRows(rows []comparer.HeaderRow) string {
+	nameW := 0
+	for _, r := range rows {
+		if len(r.Name) > nameW {
+			nameW = len(r.Name)
+		}
+	}
+	colW := (m.cmpView.Width - nameW - 8) / 2
+	if colW < 10 {
+		colW = 10
+	}
+	cell := func(v []string) string {
+		s := strings.Join(v, ", ")
+		if len([]rune(s)) > colW {
+			s = string([]rune(s)[:colW-1]) + "…"
+		}
+		return padRight(s, colW)
+	}
+
+	var b strings.Builder
+	b.WriteString(m.styles.dim.Render(padRight("", nameW+3) + padRight("A: "+m.cmpA.label, colW) + "  B: " + m.cmpB.label))
+	b.WriteString("\n")
+	for _, r := range rows {
+		mark := "="
+		switch r.State {
+		case comparer.HeaderChanged:
+			mark = "~"
+		case comparer.HeaderOnlyA:
+			mark = "-"
+		case comparer.HeaderOnlyB:
+			mark = "+"
+		}
+		line := mark + " " + padRight(r.Name, nameW) + " " + cell(r.A) + "  " + cell(r.B)
+		switch r.State {
+		case comparer.HeaderSame:
+			b.WriteString(m.styles.dim.Render(line))
+		case comparer.HeaderOnlyA:
+			b.WriteString(m.styles.diffDel.Render(line))
+		case comparer.HeaderOnlyB:
+			b.WriteString(m.styles.diffAdd.Render(line))
+		default:
+			b.WriteString(line)
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+func (m Model) renderWordOps(ops []comparer.Op) string {
+	var b strings.Builder
+	for _, op := range ops {
+		switch op.Kind {
+		case comparer.Delete:
+			b.WriteString(renderLines(m.styles.diffDel, op.Text))
+		case comparer.Insert:
+			b.WriteString(renderLines(m.styles.diffAdd, op.Text))
+		default:
+			b.WriteString(op.Text)
+		}
+	}
+	return b.String()
+}
+
+func renderLines(s lipgloss.Style, text string) string {
+	lines := strings.Split(text, "\n")
+	for i, l := range lines {
+		if l != "" {
+			lines[i] = s.Render(l)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (m Model) renderByteOps(ops []comparer.Op) string {
+	var b strings.Builder
+	offA, offB := 0, 0
+	for _, op := range ops {
+		n := len(op.Text)
+		switch op.Kind {
+		case comparer.Equal:
+			if n > 32 {
+				b.WriteString(hexRows("  ", offA, []byte(op.Text[:16]), m.styles.dim))
+				b.WriteString(m.styles.dim.Render(fmt.Sprintf("  … %d bytes iguais …", n-32)) + "\n")
+				b.WriteString(hexRows("  ", offA+n-16, []byte(op.Text[n-16:]), m.styles.dim))
+			} else {
+				b.WriteString(hexRows("  ", offA, []byte(op.Text), m.styles.dim))
+			}
+			offA += n
+			offB += n
+		case comparer.Delete:
+			b.WriteString(hexRows("- ", offA, []byte(op.Text), m.styles.diffDel))
+			offA += n
+		case comparer.Insert:
+			b.WriteString(hexRows("+ ", offB, []byte(op.Text), m.styles.diffAdd))
+			offB += n
+		}
+	}
+	return b.String()
+}
+
+func hexRows(prefix string, off int, data []byte, s lipgloss.Style) string {
+	var b strings.Builder
+	for i := 0; i < len(data); i += 16 {
+		end := i + 16
+		if end > len(data) {
+			end = len(data)
+		}
+		row := data[i:end]
+		var hex strings.Builder
+		ascii := make([]byte, len(row))
+		for j, c := range row {
+			fmt.Fprintf(&hex, "%02x ", c)
+			ascii[j] = '.'
+			if c >= 0x20 && c < 0x7f {
+				ascii[j] = c
+			}
+		}
+		b.WriteString(s.Render(fmt.Sprintf("%s%08x  %-48s |%s|", prefix, off+i, hex.String(), ascii)))
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+func (m Model) updateComparer(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, m.keys.Back):
+		m.scr = screenMain
+		m.layout()
+		return m, nil
+	case key.Matches(msg, m.keys.DiffMode):
+		m.cmpBytes = !m.cmpBytes
+		m.refreshComparer()
+		return m, nil
+	case key.Matches(msg, m.keys.DiffTarget):
+		m.cmpResponse = !m.cmpResponse
+		m.refreshComparer()
+		return m, nil
+	case key.Matches(msg, m.keys.Swap):
+		m.cmpA, m.cmpB = m.cmpB, m.cmpA
+		m.refreshComparer()
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.cmpView, cmd = m.cmpView.Update(msg)
+	return m, cmd
+}
+
+func (m Model) viewComparer() string {
+	target, mode := "response", "palavras"
+	if !m.cmpResponse {
+		target = "request"
+	}
+	if m.cmpBytes {
+		mode = "bytes"
+	}
+	labels := ""
+	if m.cmpA != nil && m.cmpB != nil {
+		labels = fmt.Sprintf("A: %s | B: %s | ", m.cmpA.label, m.cmpB.label)
+	}
+	header := lipgloss.JoinHorizontal(lipgloss.Left,
+		m.styles.title.Render("Comparer"),
+		" ",
+		m.styles.dim.Render(labels+target+" | "+mode),
+	)
+
+	body := m.styles.border.Render(m.cmpView.View())
+	footer := m.viewFooter()
+
+	return m.styles.app.Render(lipgloss.JoinVertical(lipgloss.Left, header, body, footer))
+}
@@ -0,0 +1,227 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"burpui/internal/httpraw"
+	"burpui/internal/jar"
+	"burpui/internal/repeater"
+)
+
+type cookieInput int
+
+const (
+	ckInputNone cookieInput = iota
+	ckInputAdd
+	ckInputEdit
+)
+
+type cookieItem struct {
+	cookie jar.Cookie
+}
+
+func (i cookieItem) Title() string {
+	v := i.cookie.Value
+	if len(v) > 60 {
+		v = v[:57] + "..."
+	}
+	return fmt.Sprintf("%s  %s=%s", i.cookie.Domain, i.cookie.Name, v)
+}
+
+func (i cookieItem) Description() string {
+	parts := []string{i.cookie.Path}
+	if i.cookie.Session() {
+		parts = append(parts, "sessão")
+	} else {
+		parts = append(parts, "expira "+i.cookie.Expires.Local().Format("2006-01-02 15:04"))
+	}
+	var flags []string
+	if !i.cookie.HostOnly {
+		flags = append(flags, "subdomínios")
+	}
+	if i.cookie.Secure {
+		flags = append(flags, "Secure")
+	}
+	if i.cookie.HttpOnly {
+		flags = append(flags, "HttpOnly")
+	}
+	if i.cookie.SameSite != "" {
+		flags = append(flags, "SameSite="+i.cookie.SameSite)
+	}
+	if len(flags) > 0 {
+		parts = append(parts, strings.Join(flags, " "))
+	}
+	return strings.Join(parts, " | ")
+}
+
+func (i cookieItem) FilterValue() string { return i.cookie.Domain + " " + i.cookie.Name }
+
+func jarSend(j *jar.Jar, send func(string, repeater.Options) (*repeater.Result, error)) func(string, repeater.Options) (*repeater.Result, error) {
+	return func(raw string, opts repeater.Options) (*repeater.Result, error) {
+		raw = j.ApplyRaw(raw)
+		res, err := send(raw, opts)
+		if err != nil {
+			return nil, err
+		}
+		if req, _, perr := httpraw.ParseRequest(raw); perr == nil {
+			j.Store(req.URL, res.Header)
+		}
+		return res, nil
+	}
+}
+
+func (m *Model) openCookies() {
+	m.scr = screenCookies
+	m.ckInput = ckInputNone
+	m.ckField.SetValue("")
+	m.ckField.Blur()
+	m.layout()
+	m.refreshCookies()
+}
+
+func (m *Model) refreshCookies() {
+	cookies := m.cfg.Jar.All()
+	items := make([]list.Item, 0, len(cookies))
+	for _, c := range cookies {
+		items = append(items, cookieItem{cookie: c})
+	}
+	m.ckList.Title = fmt.Sprintf("Cookies (%d)", len(cookies))
+	m.ckList.SetItems(items)
+}
+
+func (m *Model) selectedCookie() (jar.Cookie, bool) {
+	it, ok := m.ckList.SelectedItem().(cookieItem)
+	if !ok {
+		return jar.Cookie{}, false
+	}
+	return it.cookie, true
+}
+
+func (m *Model) toggleJarAttach() tea.Cmd {
+	m.jarAttach = !m.jarAttach
+	if m.jarAttach {
+		return toastCmd("Repeater/Compose anexam os cookies do jar")
+	}
+	return toastCmd("Repeater/Compose não anexam mais os cookies do jar")
+}
+
+func (m Model) updateCookies(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.ckInput != ckInputNone {
+		switch {
+		case key.Matches(msg, m.keys.Back):
+			m.ckInput = ckInputNone
+			m.ckField.SetValue("")
+			m.ckField.Blur()
+			return m, nil
+		case key.Matches(msg, m.keys.Apply):
+			toast, err := m.applyCookieInput(strings.TrimSpace(m.ckField.Value()))
+			if err != nil {
+				return m, toastCmd(err.Error())
+			}
+			m.ckInput = ckInputNone
+			m.ckField.SetValue("")
+			m.ckField.Blur()
+			m.refreshCookies()
+			return m, toastCmd(toast)
+		}
+
+		var cmd tea.Cmd
+		m.ckField, cmd = m.ckField.Update(msg)
+		return m, cmd
+	}
+
+	switch {
+	case key.Matches(msg, m.keys.Back):
+		m.scr = screenMain
+		m.layout()
+		return m, nil
+	case key.Matches(msg, m.keys.Add):
+		m.ckInput = ckInputAdd
+		m.ckField.Placeholder = "dominio[/caminho] nome=valor (ex: app.exemplo.com sid=abc)"
+		m.ckField.SetValue("")
+		return m, m.ckField.Focus()
+	case key.Matches(msg, m.keys.Edit):
+		c, ok := m.selectedCookie()
+		if !ok {
+			return m, nil
+		}
+		m.ckInput = ckInputEdit
+		m.ckEdit = c
+		m.ckField.Placeholder = "novo valor de " + c.Name
+		m.ckField.SetValue(c.Value)
+		return m, m.ckField.Focus()
+	case key.Matches(msg, m.keys.Remove):
+		c, ok := m.selectedCookie()
+		if !ok {
+			return m, nil
+		}
+		m.cfg.Jar.Delete(c.Key())
+		m.refreshCookies()
+		return m, toastCmd("cookie removido: " + c.Name)
+	case key.Matches(msg, m.keys.ClearChain):
+		m.cfg.Jar.Clear()
+		m.refreshCookies()
+		return m, toastCmd("jar esvaziado")
+	case key.Matches(msg, m.keys.AttachCookies):
+		return m, m.toggleJarAttach()
+	}
+
+	var cmd tea.Cmd
+	m.ckList, cmd = m.ckList.Update(msg)
+	return m, cmd
+}
+
+func (m *Model) applyCookieInput(text string) (string, error) {
+	switch m.ckInput {
+	case ckInputAdd:
+		target, pair, ok := strings.Cut(text, " ")
+		name, value, hasEq := strings.Cut(strings.TrimSpace(pair), "=")
+		if !ok || !hasEq || strings.TrimSpace(name) == "" {
+			return "", fmt.Errorf("use dominio[/caminho] nome=valor")
+		}
+		domain, path, _ := strings.Cut(target, "/")
+		if domain == "" {
+			return "", fmt.Errorf("domínio vazio")
+		}
+		c := jar.Cookie{Domain: domain, Path: "/" + path, Name: strings.TrimSpace(name), Value: strings.TrimSpace(value), HostOnly: true}
+		m.cfg.Jar.Set(c)
+		return "cookie adicionado: " + c.Name, nil
+	case ckInputEdit:
+		c := m.ckEdit
+		c.Value = text
+		c.Updated = time.Now()
+		m.cfg.Jar.Set(c)
+		return "cookie atualizado: " + c.Name, nil
+	}
+	return "", nil
+}
+
+func (m Model) viewCookies() string {
+	attach := m.styles.badgeOff.Render("ANEXAR OFF")
+	if m.jarAttach {
+		attach = m.styles.badgeOn.Render("ANEXAR ON")
+	}
+	header := lipgloss.JoinHorizontal(lipgloss.Left,
+		m.styles.title.Render("Cookie jar"),
+		" ",
+		attach,
+		" ",
+		m.styles.dim.Render("cookies dos Set-Cookie vistos pelo proxy"),
+	)
+
+	listBox := m.styles.border.Width(m.ckList.Width() + 2).Render(m.ckList.View())
+	input := m.styles.border.Width(m.ckList.Width() + 2).Render(m.ckField.View())
+	if m.ckInput == ckInputNone {
+		input = m.styles.border.Width(m.ckList.Width() + 2).Render(m.styles.dim.Render("a adiciona | e edita o valor | Ctrl+T anexa no Repeater/Compose"))
+	}
+	footer := m.viewFooter()
+
+	return m.styles.app.Render(lipgloss.JoinVertical(lipgloss.Left, header, listBox, input, footer))
+}
@@ -0,0 +1,94 @@
+package tui
+
+import (
+	"bytes"
+	"crypto/tls"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"burpui/internal/curl"
+	"burpui/internal/repeater"
+)
+
+var clipboardTools = [][]string{
+	{"pbcopy"},
+	{"wl-copy"},
+	{"xclip", "-selection", "clipboard"},
+	{"xsel", "--clipboard", "--input"},
+	{"clip.exe"},
+}
+
+func copyToClipboard(text string) (string, error) {
+	for _, tool := range clipboardTools {
+		if _, err := exec.LookPath(tool[0]); err != nil {
+			continue
+		}
+		cmd := exec.Command(tool[0], tool[1:]...)
+		cmd.Stdin = bytes.NewBufferString(text)
+		if err := cmd.Run(); err == nil {
+			return tool[0], nil
+		}
+	}
+	return "", fmt.Errorf("nenhuma ferramenta de clipboard encontrada")
+}
+
+func exportCurl(name, cmd string) (string, error) {
+	dir := filepath.Join("exports")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, fmt.Sprintf("curl-%s-%s.sh", name, time.Now().Format("20060102-150405")))
+	return path, os.WriteFile(path, []byte(cmd+"\n"), 0o644)
+}
+
+func copyCurlCmd(name, raw string, insecure bool) tea.Cmd {
+	return func() tea.Msg {
+		cmd, err := curl.Format(curl.Request{Raw: raw, Insecure: insecure})
+		if err != nil {
+			return toastMsg{text: "cURL: " + err.Error()}
+		}
+		path, err := exportCurl(name, cmd)
+		if err != nil {
+			return toastMsg{text: "cURL: erro ao salvar: " + err.Error()}
+		}
+		tool, err := copyToClipboard(cmd)
+		if err != nil {
+			return toastMsg{text: fmt.Sprintf("cURL salvo em %s (%s)", path, err)}
+		}
+		return toastMsg{text: fmt.Sprintf("cURL copiado (%s) e salvo em %s", tool, path)}
+	}
+}
+
+func (m *Model) importCurl() tea.Cmd {
+	r, err := curl.Parse(m.editor.Value())
+	if err != nil {
+		return toastCmd(err.Error())
+	}
+	m.editor.SetValue(strings.ReplaceAll(r.Raw, "\r\n", "\n"))
+	m.rpInsecure = r.Insecure
+	if r.Insecure {
+		return toastCmd("cURL importado (-k: sem verificar o certificado ao enviar)")
+	}
+	return toastCmd("cURL importado")
+}
+
+func insecureOptions(opts repeater.Options) repeater.Options {
+	base := opts.TLSConfig
+	opts.TLSConfig = func(host string) *tls.Config {
+		cfg := &tls.Config{MinVersion: tls.VersionTLS12}
+		if base != nil {
+			if c := base(host); c != nil {
+				cfg = c.Clone()
+			}
+		}
+		cfg.InsecureSkipVerify = true
+		return cfg
+	}
+	return opts
+}
@@ -0,0 +1,336 @@
+package tui
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"burpui/internal/decoder"
+	"burpui/internal/proxy"
+)
+
+type decSource struct {
+	label string
+	text  string
+}
+
+type transformItem struct {
+	name string
+	desc string
+}
+
+func (i transformItem) Title() string       { return i.name }
+func (i transformItem) Description() string { return i.desc }
+func (i transformItem) FilterValue() string { return i.name }
+
+func transformItems() []list.Item {
+	ts := decoder.Transforms()
+	items := make([]list.Item, 0, len(ts))
+	for _, t := range ts {
+		items = append(items, transformItem{name: t.Name, desc: t.Desc})
+	}
+	return items
+}
+
+func flowSources(f *proxy.Flow) []decSource {
+	var out []decSource
+	if u, err := url.Parse(f.URL); err == nil {
+		out = append(out, rawPairs("query", u.RawQuery, "&")...)
+	}
+	for _, k := range sortedKeys(f.RequestHeader) {
+		for _, v := range f.RequestHeader[k] {
+			if strings.EqualFold(k, "Cookie") {
+				out = append(out, rawPairs("cookie", v, ";")...)
+				continue
+			}
+			out = append(out, decSource{label: "header " + k, text: v})
+		}
+	}
+	if len(f.RequestBody) > 0 {
+		if strings.Contains(f.RequestHeader.Get("Content-Type"), "x-www-form-urlencoded") {
+			out = append(out, rawPairs("body", string(f.RequestBody), "&")...)
+		}
+		out = append(out, decSource{label: "corpo da requisição", text: string(f.RequestBody)})
+	}
+	for _, v := range f.ResponseHeader.Values("Set-Cookie") {
+		name, rest, _ := strings.Cut(v, "=")
+		val, _, _ := strings.Cut(rest, ";")
+		out = append(out, decSource{label: "set-cookie " + strings.TrimSpace(name), text: val})
+	}
+	if len(f.ResponseBody) > 0 {
+		out = append(out, decSource{label: "corpo da resposta", text: string(f.ResponseBody)})
+	}
+	return out
+}
+
+func lineSources(line string) []decSource {
+	var out []decSource
+	if name, val, ok := strings.Cut(line, ":"); ok && !strings.ContainsAny(name, " \t/") && name != "" {
+		val = strings.TrimSpace(val)
+		if strings.EqualFold(name, "Cookie") {
+			out = append(out, rawPairs("cookie", val, ";")...)
+		}
+		out = append(out, decSource{label: "header " + name, text: val})
+	} else if fields := strings.Fields(line); len(fields) >= 2 && strings.ToUpper(fields[0]) == fields[0] {
+		if _, q, ok := strings.Cut(fields[1], "?"); ok {
+			out = append(out, rawPairs("query", q, "&")...)
+		}
+	} else if strings.Contains(line, "=") {
+		out = append(out, rawPairs("body", line, "&")...)
+	}
+	return append(out, decSource{label: "linha", text: line})
+}
+
+func rawPairs(kind, s, sep string) []decSource {
+	var out []decSource
+	for _, p := range strings.Split(s, sep) {
+		name, val, ok := strings.Cut(strings.TrimSpace(p), "=")
+		if !ok || val == "" {
+			continue
+		}
+		out = append(out, decSource{label: kind + " " + name, text: val})
+	}
+	return out
+}
+
+func sortedKeys(h http.Header) []string {
+	keys := make([]string, 0, len(h))
+	for k := range h {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func (m *Model) openDecoder(from screen, sources []decSource) {
+	m.decFrom = from
+	m.decSources = sources
+	m.decSrc = 0
+	for i, s := range sources {
+		if len(decoder.Detect([]byte(s.text))) > 0 {
+			m.decSrc = i
+			break
+		}
+	}
+	m.decChain = nil
+	m.decInput.SetValue("")
+	if len(sources) > 0 {
+		m.decInput.SetValue(sources[m.decSrc].text)
+		m.decChain = decoder.Detect([]byte(sources[m.decSrc].text))
+	}
+	m.decListFocus = false
+	m.decInput.Focus()
+	m.editor.Blur()
+	m.scr = screenDecoder
+	m.layout()
+	m.refreshDecoder()
+}
+
+func (m *Model) refreshDecoder() {
+	input := []byte(m.decInput.Value())
+	steps, err := decoder.Run(input, m.decChain)
+
+	var b strings.Builder
+	if len(m.decChain) == 0 {
+		b.WriteString(m.styles.dim.Render("Sem transformações: enter na lista adiciona, Ctrl+G detecta automaticamente"))
+		b.WriteString("\n")
+	}
+	for i, s := range steps {
+		b.WriteString(m.styles.key.Render(fmt.Sprintf("%d. %s", i+1, s.Name)))
+		b.WriteString(m.styles.dim.Render(fmt.Sprintf("  (%d bytes)", len(s.Output))))
+		b.WriteString("\n")
+		b.WriteString(m.renderDecoded(s.Output))
+		b.WriteString("\n\n")
+	}
+	if err != nil {
+		b.WriteString(m.styles.err.Render(err.Error()))
+		b.WriteString("\n")
+	}
+	m.decView.SetContent(b.String())
+	m.decView.GotoBottom()
+}
+
+func (m Model) renderDecoded(b []byte) string {
+	if decoder.Printable(b) {
+		return string(b)
+	}
+	if len(b) > 512 {
+		return hexRows("", 0, b[:512], m.styles.dim) + m.styles.dim.Render(fmt.Sprintf("… mais %d bytes", len(b)-512))
+	}
+	return hexRows("", 0, b, m.styles.dim)
+}
+
+func (m Model) decoderResult() ([]byte, error) {
+	input := []byte(m.decInput.Value())
+	steps, err := decoder.Run(input, m.decChain)
+	if err != nil {
+		return nil, err
+	}
+	if len(steps) == 0 {
+		return input, nil
+	}
+	return steps[len(steps)-1].Output, nil
+}
+
+func (m Model) updateDecoder(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, m.keys.Back):
+		m.decInput.Blur()
+		m.scr = m.decFrom
+		if m.scr == screenRepeater || m.scr == screenCompose {
+			m.editor.Focus()
+		}
+		m.layout()
+		return m, nil
+	case key.Matches(msg, m.keys.FocusNext):
+		m.decListFocus = !m.decListFocus
+		if m.decListFocus {
+			m.decInput.Blur()
+		} else {
+			m.decInput.Focus()
+		}
+		return m, nil
+	case key.Matches(msg, m.keys.AutoDecode):
+		m.decChain = decoder.Detect([]byte(m.decInput.Value()))
+		m.refreshDecoder()
+		if len(m.decChain) == 0 {
+			return m, toastCmd("nenhuma codificação reconhecida")
+		}
+		return m, toastCmd("detectado: " + strings.Join(m.decChain, " → "))
+	case key.Matches(msg, m.keys.NextSource):
+		if len(m.decSources) == 0 {
+			return m, nil
+		}
+		m.decSrc = (m.decSrc + 1) % len(m.decSources)
+		src := m.decSources[m.decSrc]
+		m.decInput.SetValue(src.text)
+		m.decChain = decoder.Detect([]byte(src.text))
+		m.refreshDecoder()
+		return m, toastCmd(fmt.Sprintf("origem %d/%d: %s", m.decSrc+1, len(m.decSources), src.label))
+	case key.Matches(msg, m.keys.ClearChain):
+		m.decChain = nil
+		m.refreshDecoder()
+		return m, nil
+	case key.Matches(msg, m.keys.Send):
+		return m.writeBackDecoder()
+	case key.Matches(msg, m.keys.ScrollUp):
+		m.decView.HalfPageUp()
+		return m, nil
+	case key.Matches(msg, m.keys.ScrollDown):
+		m.decView.HalfPageDown()
+		return m, nil
+	}
+
+	if m.decListFocus {
+		switch {
+		case key.Matches(msg, m.keys.Apply):
+			if it, ok := m.decList.SelectedItem().(transformItem); ok {
+				m.decChain = append(m.decChain, it.name)
+				m.refreshDecoder()
+			}
+			return m, nil
+		case key.Matches(msg, m.keys.Remove):
+			if len(m.decChain) > 0 {
+				m.decChain = m.decChain[:len(m.decChain)-1]
+				m.refreshDecoder()
+			}
+			return m, nil
+		}
+		var cmd tea.Cmd
+		m.decList, cmd = m.decList.Update(msg)
+		return m, cmd
+	}
+
+	var cmd tea.Cmd
+	m.decInput, cmd = m.decInput.Update(msg)
+	m.refreshDecoder()
+	return m, cmd
+}
+
+func (m Model) writeBackDecoder() (tea.Model, tea.Cmd) {
+	out, err := m.decoderResult()
+	if err != nil {
+		return m, toastCmd(err.Error())
+	}
+	if !decoder.Printable(out) {
+		return m, toastCmd("resultado binário: adicione um encode antes de aplicar")
+	}
+	orig := m.decInput.Value()
+	if len(m.decSources) > 0 {
+		orig = m.decSources[m.decSrc].text
+	}
+
+	if m.decFrom == screenRepeater || m.decFrom == screenCompose {
+		lines := strings.Split(m.editor.Value(), "\n")
+		row := m.decLine
+		if row >= len(lines) || orig == "" || !strings.Contains(lines[row], orig) {
+			return m, toastCmd("origem não encontrada no editor")
+		}
+		lines[row] = strings.Replace(lines[row], orig, string(out), 1)
+		m.editor.SetValue(strings.Join(lines, "\n"))
+		for m.editor.Line() > row {
+			m.editor.CursorUp()
+		}
+		m.editor.CursorStart()
+		m.decInput.Blur()
+		m.scr = m.decFrom
+		m.editor.Focus()
+		m.layout()
+		return m, toastCmd("aplicado no editor")
+	}
+
+	f := m.flows[m.decFlowID]
+	if f == nil {
+		return m, toastCmd("sem fluxo de origem")
+	}
+	raw := renderRawRequest(f)
+	if orig == "" || !strings.Contains(raw, orig) {
+		return m, toastCmd("origem não está na requisição")
+	}
+	m.decInput.Blur()
+	m.scr = screenRepeater
+	m.editorTitle = "Repeater"
+	m.status = "Ctrl+S envia | Esc volta"
+	m.resp.SetContent("")
+	m.editor.SetValue(strings.Replace(raw, orig, string(out), 1))
+	m.editor.Focus()
+	m.layout()
+	return m, toastCmd(fmt.Sprintf("#%d aberto no Repeater com o valor aplicado", f.ID))
+}
+
+func (m Model) viewDecoder() string {
+	status := "origem: manual"
+	if len(m.decSources) > 0 {
+		status = fmt.Sprintf("origem %d/%d: %s", m.decSrc+1, len(m.decSources), m.decSources[m.decSrc].label)
+	}
+	chain := "(vazia)"
+	if len(m.decChain) > 0 {
+		chain = strings.Join(m.decChain, " → ")
+	}
+	header := lipgloss.JoinHorizontal(lipgloss.Left,
+		m.styles.title.Render("Decoder"),
+		" ",
+		m.styles.dim.Render(status+" | cadeia: "+chain),
+	)
+
+	listBorder, inputBorder := m.styles.border, m.styles.border
+	if m.decListFocus {
+		listBorder = listBorder.BorderForeground(lipgloss.Color("81"))
+	} else {
+		inputBorder = inputBorder.BorderForeground(lipgloss.Color("81"))
+	}
+	input := inputBorder.Render(m.decInput.View())
+	left := listBorder.Width(m.decList.Width()).Height(m.decList.Height()).Render(m.decList.View())
+	right := m.styles.border.Width(m.decView.Width).Height(m.decView.Height).Render(m.decView.View())
+	row := lipgloss.JoinHorizontal(lipgloss.Top, left, right)
+	footer := m.viewFooter()
+
+	return m.styles.app.Render(lipgloss.JoinVertical(lipgloss.Left, header, input, row, footer))
+}
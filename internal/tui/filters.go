@@ -0,0 +1,198 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"burpui/internal/filter"
+)
+
+type filterItem struct {
+	name  string
+	title string
+	desc  string
+}
+
+func (i filterItem) Title() string       { return i.title }
+func (i filterItem) Description() string { return i.desc }
+func (i filterItem) FilterValue() string { return i.title }
+
+func (m *Model) setFilter(expr string) error {
+	expr = strings.TrimSpace(expr)
+	if expr == "" {
+		m.filter = nil
+		m.rebuildList()
+		m.updateDetail()
+		return nil
+	}
+	expanded, err := filter.Expand(expr, m.savedFilters)
+	if err != nil {
+		return err
+	}
+	f, err := filter.Parse(expanded)
+	if err != nil {
+		return err
+	}
+	f.Expr = expr
+	m.filter = f
+	m.rebuildList()
+	m.updateDetail()
+	return nil
+}
+
+func (m Model) updateFilterInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, m.keys.Back):
+		m.filtering = false
+		m.filterInput.Blur()
+		m.layout()
+		return m, nil
+	case key.Matches(msg, m.keys.Apply):
+		if err := m.setFilter(m.filterInput.Value()); err != nil {
+			return m, toastCmd(err.Error())
+		}
+		m.filtering = false
+		m.filterInput.Blur()
+		m.layout()
+		if m.filter == nil {
+			return m, toastCmd("filtro removido")
+		}
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.filterInput, cmd = m.filterInput.Update(msg)
+	return m, cmd
+}
+
+func (m *Model) refreshFilters() {
+	items := make([]list.Item, 0, len(m.savedFilters))
+	for _, s := range m.savedFilters {
+		title := "@" + s.Name
+		if m.filter != nil && m.filter.Expr == "@"+s.Name {
+			title += " (ativo)"
+		}
+		items = append(items, filterItem{name: s.Name, title: title, desc: s.Expr})
+	}
+	m.filterList.SetItems(items)
+}
+
+func (m *Model) saveFilters() error {
+	return filter.StoreSaved(m.cfg.FiltersFile, m.savedFilters)
+}
+
+func (m Model) updateFilters(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.filterAdding {
+		switch {
+		case key.Matches(msg, m.keys.Back):
+			m.filterAdding = false
+			m.filterInput.SetValue("")
+			m.filterInput.Blur()
+			return m, nil
+		case key.Matches(msg, m.keys.Apply):
+			name, expr, ok := strings.Cut(m.filterInput.Value(), "=")
+			if !ok {
+				if m.filter == nil {
+					return m, toastCmd("use nome = expressão")
+				}
+				expr = m.filter.Expr
+			}
+			saved, err := filter.Put(m.savedFilters, name, strings.TrimSpace(expr))
+			if err != nil {
+				return m, toastCmd(err.Error())
+			}
+			m.savedFilters = saved
+			m.filterAdding = false
+			m.filterInput.SetValue("")
+			m.filterInput.Blur()
+			m.refreshFilters()
+			if err := m.saveFilters(); err != nil {
+				return m, toastCmd("erro ao salvar: " + err.Error())
+			}
+			return m, toastCmd("filtro salvo")
+		}
+
+		var cmd tea.Cmd
+		m.filterInput, cmd = m.filterInput.Update(msg)
+		return m, cmd
+	}
+
+	switch {
+	case key.Matches(msg, m.keys.Back):
+		m.scr = screenMain
+		m.layout()
+		return m, nil
+	case key.Matches(msg, m.keys.Add):
+		m.filterAdding = true
+		m.filterInput.SetValue("")
+		m.filterInput.Placeholder = "nome = expressão (sem '=' salva o filtro ativo)"
+		m.filterInput.Focus()
+		return m, nil
+	case key.Matches(msg, m.keys.Toggle):
+		it, ok := m.filterList.SelectedItem().(filterItem)
+		if !ok {
+			return m, nil
+		}
+		if err := m.setFilter("@" + it.name); err != nil {
+			return m, toastCmd(err.Error())
+		}
+		m.scr = screenMain
+		m.layout()
+		return m, toastCmd("filtro: @" + it.name)
+	case key.Matches(msg, m.keys.Remove):
+		it, ok := m.filterList.SelectedItem().(filterItem)
+		if !ok {
+			return m, nil
+		}
+		for i := range m.savedFilters {
+			if m.savedFilters[i].Name == it.name {
+				m.savedFilters = append(m.savedFilters[:i], m.savedFilters[i+1:]...)
+				break
+			}
+		}
+		m.refreshFilters()
+		if err := m.saveFilters(); err != nil {
+			return m, toastCmd("erro ao salvar: " + err.Error())
+		}
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.filterList, cmd = m.filterList.Update(msg)
+	return m, cmd
+}
+
+func (m Model) viewFilters() string {
+	header := lipgloss.JoinHorizontal(lipgloss.Left,
+		m.styles.title.Render("Filtros salvos"),
+		" ",
+		m.styles.dim.Render("enter aplica | a salva | del remove | esc volta"),
+	)
+
+	listBox := m.styles.border.Render(m.filterList.View())
+	input := ""
+	if m.filterAdding {
+		input = m.styles.border.Render(m.filterInput.View())
+	} else {
+		input = m.styles.border.Render(m.styles.dim.Render("use @nome no filtro (/) para combinar com outros termos"))
+	}
+	footer := m.viewFooter()
+
+	return m.styles.app.Render(lipgloss.JoinVertical(lipgloss.Left, header, listBox, input, footer))
+}
+
+func (m Model) filterBadge() string {
+	if m.filter == nil {
+		return ""
+	}
+	expr := []rune(m.filter.Expr)
+	if len(expr) > 40 {
+		expr = append(expr[:39], '…')
+	}
+	return m.styles.badgeWarn.Render(fmt.Sprintf("FILTRO %s", string(expr)))
+}
@@ -0,0 +1,305 @@
+package tui
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"burpui/internal/jwt"
+	"burpui/internal/proxy"
+)
+
+type foundJWT struct {
+	where string
+	raw   string
+}
+
+func flowJWTs(f *proxy.Flow) []foundJWT {
+	var out []foundJWT
+	seen := map[string]bool{}
+	add := func(where, s string) {
+		for _, raw := range jwt.Find(s) {
+			if !seen[raw] {
+				seen[raw] = true
+				out = append(out, foundJWT{where: where, raw: raw})
+			}
+		}
+	}
+	add("URL", f.URL)
+	for _, k := range sortedKeys(f.RequestHeader) {
+		for _, v := range f.RequestHeader[k] {
+			add("request header "+k, v)
+		}
+	}
+	add("request body", string(f.RequestBody))
+	for _, k := range sortedKeys(f.ResponseHeader) {
+		for _, v := range f.ResponseHeader[k] {
+			add("response header "+k, v)
+		}
+	}
+	add("response body", string(f.ResponseBody))
+	return out
+}
+
+func (m Model) renderJWTs(found []foundJWT) string {
+	var b strings.Builder
+	for _, fj := range found {
+		tok, err := jwt.Parse(fj.raw)
+		b.WriteString(m.styles.badgeOn.Render("JWT"))
+		b.WriteString(" ")
+		b.WriteString(m.styles.dim.Render(fj.where))
+		b.WriteString("\n")
+		if err != nil {
+			b.WriteString(m.styles.err.Render(err.Error()))
+			b.WriteString("\n")
+			continue
+		}
+		b.WriteString(m.jwtSummary(tok))
+		b.WriteString(tok.Pretty())
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+func (m Model) jwtSummary(tok *jwt.Token) string {
+	var b strings.Builder
+	alg := tok.Alg()
+	if alg == "" {
+		alg = "(sem alg)"
+	}
+	b.WriteString("alg: " + alg)
+	if jwt.IsNone(alg) || len(tok.Signature) == 0 {
+		b.WriteString(" " + m.styles.badgeWarn.Render("SEM ASSINATURA"))
+	}
+	b.WriteString("\n")
+	for _, c := range []string{"iat", "nbf", "exp"} {
+		t, ok := tok.Time(c)
+		if !ok {
+			continue
+		}
+		b.WriteString(fmt.Sprintf("%s: %s", c, t.Format("2006-01-02 15:04:05")))
+		if c == "exp" && tok.Expired(time.Now()) {
+			b.WriteString(" " + m.styles.badgeWarn.Render("EXPIRADO"))
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+func (m *Model) openJWTEditor(f *proxy.Flow, found []foundJWT) {
+	m.jwtFlowID = f.ID
+	m.jwtFound = found
+	m.jwtAlg = 0
+	m.jwtKey.SetValue("")
+	m.loadJWT(0)
+	m.scr = screenJWT
+	m.layout()
+	m.refreshJWT()
+}
+
+func (m *Model) loadJWT(i int) {
+	m.jwtIdx = i
+	m.jwtTok = nil
+	m.jwtHeader.SetValue("")
+	m.jwtClaims.SetValue("")
+	if tok, err := jwt.Parse(m.jwtFound[i].raw); err == nil {
+		m.jwtTok = tok
+		m.jwtHeader.SetValue(tok.HeaderJSON())
+		m.jwtClaims.SetValue(tok.ClaimsJSON())
+	}
+	m.jwtHeader.CursorStart()
+	m.jwtClaims.CursorStart()
+	m.focusJWT(0)
+}
+
+func (m *Model) focusJWT(i int) {
+	m.jwtFocus = i
+	m.jwtHeader.Blur()
+	m.jwtClaims.Blur()
+	m.jwtKey.Blur()
+	switch i {
+	case 0:
+		m.jwtHeader.Focus()
+	case 1:
+		m.jwtClaims.Focus()
+	case 2:
+		m.jwtKey.Focus()
+	}
+}
+
+func (m Model) jwtKeyBytes() ([]byte, error) {
+	k := strings.TrimSpace(m.jwtKey.Value())
+	if path, ok := strings.CutPrefix(k, "@"); ok {
+		return os.ReadFile(path)
+	}
+	return []byte(k), nil
+}
+
+func (m Model) buildJWT() (string, error) {
+	if m.jwtTok == nil {
+		return "", fmt.Errorf("token original inválido")
+	}
+	header, err := jwt.DecodeJSON(m.jwtHeader.Value())
+	if err != nil {
+		return "", fmt.Errorf("header: %w", err)
+	}
+	claims, err := jwt.DecodeJSON(m.jwtClaims.Value())
+	if err != nil {
+		return "", fmt.Errorf("payload: %w", err)
+	}
+	k, err := m.jwtKeyBytes()
+	if err != nil {
+		return "", fmt.Errorf("chave: %w", err)
+	}
+	return jwt.Sign(header, claims, jwt.Algs[m.jwtAlg], k, m.jwtTok.Signature)
+}
+
+func jwtAlgLabel(alg string) string {
+	if alg == jwt.KeepSignature {
+		return "manter assinatura"
+	}
+	return alg
+}
+
+func (m *Model) refreshJWT() {
+	var b strings.Builder
+	if m.jwtTok == nil {
+		m.jwtOut.SetContent(m.styles.err.Render("token não pôde ser decodificado"))
+		return
+	}
+	b.WriteString(m.styles.dim.Render("Original"))
+	b.WriteString("\n")
+	b.WriteString(m.jwtSummary(m.jwtTok))
+	if k, err := m.jwtKeyBytes(); err == nil && len(k) > 0 && strings.HasPrefix(m.jwtTok.Alg(), "HS") {
+		if ok, _ := jwt.Verify(m.jwtTok, k); ok {
+			b.WriteString(m.styles.badgeOn.Render("a chave confere com a assinatura original"))
+		} else {
+			b.WriteString(m.styles.dim.Render("a chave não confere com a assinatura original"))
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
+	b.WriteString(m.styles.dim.Render("Novo token (" + jwtAlgLabel(jwt.Algs[m.jwtAlg]) + ")"))
+	b.WriteString("\n")
+	out, err := m.buildJWT()
+	if err != nil {
+		b.WriteString(m.styles.err.Render(err.Error()))
+	} else {
+		b.WriteString(lipgloss.NewStyle().Width(m.jwtOut.Width - 2).Render(out))
+	}
+	m.jwtOut.SetContent(b.String())
+}
+
+func (m Model) updateJWT(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, m.keys.Back):
+		m.focusJWT(-1)
+		m.scr = screenMain
+		m.layout()
+		return m, nil
+	case key.Matches(msg, m.keys.FocusNext):
+		m.focusJWT((m.jwtFocus + 1) % 3)
+		return m, nil
+	case key.Matches(msg, m.keys.NextAlg):
+		m.jwtAlg = (m.jwtAlg + 1) % len(jwt.Algs)
+		m.refreshJWT()
+		return m, toastCmd("alg: " + jwtAlgLabel(jwt.Algs[m.jwtAlg]))
+	case key.Matches(msg, m.keys.StripSig):
+		m.jwtAlg = 1
+		m.refreshJWT()
+		return m, toastCmd("alg: none (sem assinatura)")
+	case key.Matches(msg, m.keys.NextSource):
+		if len(m.jwtFound) < 2 {
+			return m, nil
+		}
+		m.loadJWT((m.jwtIdx + 1) % len(m.jwtFound))
+		m.refreshJWT()
+		return m, toastCmd(fmt.Sprintf("token %d/%d: %s", m.jwtIdx+1, len(m.jwtFound), m.jwtFound[m.jwtIdx].where))
+	case key.Matches(msg, m.keys.Send):
+		return m.sendJWTToRepeater()
+	}
+
+	var cmd tea.Cmd
+	switch m.jwtFocus {
+	case 0:
+		m.jwtHeader, cmd = m.jwtHeader.Update(msg)
+	case 1:
+		m.jwtClaims, cmd = m.jwtClaims.Update(msg)
+	default:
+		m.jwtKey, cmd = m.jwtKey.Update(msg)
+	}
+	m.refreshJWT()
+	return m, cmd
+}
+
+func (m Model) sendJWTToRepeater() (tea.Model, tea.Cmd) {
+	f := m.flows[m.jwtFlowID]
+	if f == nil {
+		return m, toastCmd("sem fluxo de origem")
+	}
+	tok, err := m.buildJWT()
+	if err != nil {
+		return m, toastCmd("jwt: " + err.Error())
+	}
+	orig := m.jwtFound[m.jwtIdx].raw
+	raw := renderRawRequest(f)
+	note := "token trocado na requisição"
+	if strings.Contains(raw, orig) {
+		raw = strings.ReplaceAll(raw, orig, tok)
+	} else {
+		c := *f
+		c.RequestHeader = f.RequestHeader.Clone()
+		if c.RequestHeader == nil {
+			c.RequestHeader = http.Header{}
+		}
+		c.RequestHeader.Set("Authorization", "Bearer "+tok)
+		raw = renderRawRequest(&c)
+		note = "token enviado em Authorization: Bearer"
+	}
+
+	m.focusJWT(-1)
+	m.scr = screenRepeater
+	m.editorTitle = "Repeater"
+	m.status = "Ctrl+S envia | Esc volta"
+	m.resp.SetContent("")
+	m.editor.SetValue(raw)
+	m.editor.Focus()
+	m.layout()
+	return m, toastCmd(note)
+}
+
+func (m Model) viewJWT() string {
+	where := ""
+	if len(m.jwtFound) > 0 {
+		where = fmt.Sprintf("token %d/%d: %s | ", m.jwtIdx+1, len(m.jwtFound), m.jwtFound[m.jwtIdx].where)
+	}
+	header := lipgloss.JoinHorizontal(lipgloss.Left,
+		m.styles.title.Render(fmt.Sprintf("JWT #%d", m.jwtFlowID)),
+		" ",
+		m.styles.dim.Render(where+"alg: "+jwtAlgLabel(jwt.Algs[m.jwtAlg])),
+	)
+
+	box := func(focused bool, title, body string) string {
+		s := m.styles.border
+		if focused {
+			s = s.BorderForeground(lipgloss.Color("81"))
+		}
+		return s.Render(m.styles.dim.Render(title) + "\n" + body)
+	}
+	left := lipgloss.JoinVertical(lipgloss.Left,
+		box(m.jwtFocus == 0, "Header", m.jwtHeader.View()),
+		box(m.jwtFocus == 1, "Payload", m.jwtClaims.View()),
+		box(m.jwtFocus == 2, "Chave (segredo HMAC, ou @arquivo.pem)", m.jwtKey.View()),
+	)
+	right := m.styles.border.Width(m.jwtOut.Width).Height(m.jwtOut.Height).Render(m.jwtOut.View())
+	row := lipgloss.JoinHorizontal(lipgloss.Top, left, right)
+	footer := m.viewFooter()
+
+	return m.styles.app.Render(lipgloss.JoinVertical(lipgloss.Left, header, row, footer))
+}
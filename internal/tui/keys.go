@@ -12,6 +12,53 @@ type keyMap struct {
 	Edit            key.Binding
 	Breakpoints     key.Binding
 	Export          key.Binding
+	Mock            key.Binding
+	Throttle        key.Binding
+	Filter          key.Binding
+	SavedFilters    key.Binding
+	Apply           key.Binding
+	View            key.Binding
+	ColPrev         key.Binding
+	ColNext         key.Binding
+	Sort            key.Binding
+	Narrow          key.Binding
+	Widen           key.Binding
+	HideCol         key.Binding
+	SiteMap         key.Binding
+	ScopeIn         key.Binding
+	ScopeOut        key.Binding
+	Crawl           key.Binding
+	Compare         key.Binding
+	CompareResult   key.Binding
+	DiffMode        key.Binding
+	DiffTarget      key.Binding
+	Swap            key.Binding
+	Decoder         key.Binding
+	DecodeLine      key.Binding
+	FocusNext       key.Binding
+	AutoDecode      key.Binding
+	NextSource      key.Binding
+	ClearChain      key.Binding
+	ScrollUp        key.Binding
+	ScrollDown      key.Binding
+	JWT             key.Binding
+	NextAlg         key.Binding
+	StripSig        key.Binding
+	Sequencer       key.Binding
+	Authz           key.Binding
+	Pause           key.Binding
+	ToggleSession   key.Binding
+	Suspicious      key.Binding
+	Macros          key.Binding
+	Record          key.Binding
+	AddExtract      key.Binding
+	AddRule         key.Binding
+	SetVar          key.Binding
+	Cookies         key.Binding
+	AttachCookies   key.Binding
+	Curl            key.Binding
+	CopyCurl        key.Binding
+	ImportCurl      key.Binding
 	Back            key.Binding
 	Send            key.Binding
 	Add             key.Binding
@@ -30,6 +77,53 @@ func newKeyMap() keyMap {
 		Edit:            key.NewBinding(key.WithKeys("e"), key.WithHelp("e", "edit")),
 		Breakpoints:     key.NewBinding(key.WithKeys("b"), key.WithHelp("b", "breakpoints")),
 		Export:          key.NewBinding(key.WithKeys("x"), key.WithHelp("x", "export")),
+		Mock:            key.NewBinding(key.WithKeys("m"), key.WithHelp("m", "mock")),
+		Throttle:        key.NewBinding(key.WithKeys("n"), key.WithHelp("n", "rede")),
+		Filter:          key.NewBinding(key.WithKeys("/"), key.WithHelp("/", "filtro")),
+		SavedFilters:    key.NewBinding(key.WithKeys("F"), key.WithHelp("F", "filtros salvos")),
+		Apply:           key.NewBinding(key.WithKeys("enter"), key.WithHelp("enter", "aplicar")),
+		View:            key.NewBinding(key.WithKeys("v"), key.WithHelp("v", "tabela/árvore")),
+		ColPrev:         key.NewBinding(key.WithKeys("["), key.WithHelp("[", "coluna anterior")),
+		ColNext:         key.NewBinding(key.WithKeys("]"), key.WithHelp("]", "próxima coluna")),
+		Sort:            key.NewBinding(key.WithKeys("s"), key.WithHelp("s", "ordenar")),
+		Narrow:          key.NewBinding(key.WithKeys("-"), key.WithHelp("-", "estreitar")),
+		Widen:           key.NewBinding(key.WithKeys("+", "="), key.WithHelp("+", "alargar")),
+		HideCol:         key.NewBinding(key.WithKeys("h"), key.WithHelp("h", "mostrar/ocultar")),
+		SiteMap:         key.NewBinding(key.WithKeys("M"), key.WithHelp("M", "site map")),
+		ScopeIn:         key.NewBinding(key.WithKeys("i"), key.WithHelp("i", "incluir no escopo")),
+		ScopeOut:        key.NewBinding(key.WithKeys("o"), key.WithHelp("o", "excluir do escopo")),
+		Crawl:           key.NewBinding(key.WithKeys("c"), key.WithHelp("c", "crawl")),
+		Compare:         key.NewBinding(key.WithKeys("C"), key.WithHelp("C", "comparer")),
+		CompareResult:   key.NewBinding(key.WithKeys("ctrl+r"), key.WithHelp("ctrl+r", "comparer")),
+		DiffMode:        key.NewBinding(key.WithKeys("w"), key.WithHelp("w", "palavras/bytes")),
+		DiffTarget:      key.NewBinding(key.WithKeys("t"), key.WithHelp("t", "request/response")),
+		Swap:            key.NewBinding(key.WithKeys("s"), key.WithHelp("s", "inverter")),
+		Decoder:         key.NewBinding(key.WithKeys("D"), key.WithHelp("D", "decoder")),
+		DecodeLine:      key.NewBinding(key.WithKeys("ctrl+o"), key.WithHelp("ctrl+o", "decoder")),
+		FocusNext:       key.NewBinding(key.WithKeys("tab"), key.WithHelp("tab", "alternar foco")),
+		AutoDecode:      key.NewBinding(key.WithKeys("ctrl+g"), key.WithHelp("ctrl+g", "auto-detectar")),
+		NextSource:      key.NewBinding(key.WithKeys("ctrl+l"), key.WithHelp("ctrl+l", "próxima origem")),
+		ClearChain:      key.NewBinding(key.WithKeys("ctrl+x"), key.WithHelp("ctrl+x", "limpar cadeia")),
+		ScrollUp:        key.NewBinding(key.WithKeys("pgup"), key.WithHelp("pgup", "rolar")),
+		ScrollDown:      key.NewBinding(key.WithKeys("pgdown"), key.WithHelp("pgdown", "rolar")),
+		JWT:             key.NewBinding(key.WithKeys("J"), key.WithHelp("J", "jwt")),
+		NextAlg:         key.NewBinding(key.WithKeys("ctrl+g"), key.WithHelp("ctrl+g", "próximo alg")),
+		StripSig:        key.NewBinding(key.WithKeys("ctrl+x"), key.WithHelp("ctrl+x", "alg none")),
+		Sequencer:       key.NewBinding(key.WithKeys("S"), key.WithHelp("S", "sequencer")),
+		Authz:           key.NewBinding(key.WithKeys("A"), key.WithHelp("A", "authz")),
+		Pause:           key.NewBinding(key.WithKeys("p"), key.WithHelp("p", "pausar")),
+		ToggleSession:   key.NewBinding(key.WithKeys("1", "2", "3", "4", "5", "6", "7", "8", "9"), key.WithHelp("1-9", "liga/desliga sessão")),
+		Suspicious:      key.NewBinding(key.WithKeys("f"), key.WithHelp("f", "só suspeitas")),
+		Macros:          key.NewBinding(key.WithKeys("K"), key.WithHelp("K", "macros")),
+		Record:          key.NewBinding(key.WithKeys("R"), key.WithHelp("R", "gravar passo")),
+		AddExtract:      key.NewBinding(key.WithKeys("x"), key.WithHelp("x", "extração")),
+		AddRule:         key.NewBinding(key.WithKeys("u"), key.WithHelp("u", "regra")),
+		SetVar:          key.NewBinding(key.WithKeys("="), key.WithHelp("=", "variável")),
+		Cookies:         key.NewBinding(key.WithKeys("O"), key.WithHelp("O", "cookies")),
+		AttachCookies:   key.NewBinding(key.WithKeys("ctrl+t"), key.WithHelp("ctrl+t", "anexar cookies")),
+		Curl:            key.NewBinding(key.WithKeys("Y"), key.WithHelp("Y", "copia cURL")),
+		CopyCurl:        key.NewBinding(key.WithKeys("ctrl+y"), key.WithHelp("ctrl+y", "copia cURL")),
+		ImportCurl:      key.NewBinding(key.WithKeys("ctrl+l"), key.WithHelp("ctrl+l", "importa cURL")),
 		Back:            key.NewBinding(key.WithKeys("esc"), key.WithHelp("esc", "voltar")),
 		Send:            key.NewBinding(key.WithKeys("ctrl+s"), key.WithHelp("ctrl+s", "enviar")),
 		Add:             key.NewBinding(key.WithKeys("a"), key.WithHelp("a", "add")),
@@ -0,0 +1,349 @@
+package tui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"burpui/internal/macro"
+	"burpui/internal/proxy"
+	"burpui/internal/sequencer"
+)
+
+type macroInput int
+
+const (
+	macInputNone macroInput = iota
+	macInputName
+	macInputExtract
+	macInputRule
+	macInputVar
+)
+
+type macroItem struct {
+	name  string
+	title string
+	desc  string
+}
+
+func (i macroItem) Title() string       { return i.title }
+func (i macroItem) Description() string { return i.desc }
+func (i macroItem) FilterValue() string { return i.name }
+
+type macroDoneMsg struct {
+	name string
+	vars map[string]string
+	err  error
+}
+
+func runMacroCmd(e *macro.Engine, name string) tea.Cmd {
+	return func() tea.Msg {
+		vars, err := e.Run(name)
+		return macroDoneMsg{name: name, vars: vars, err: err}
+	}
+}
+
+func (m *Model) openMacros() {
+	m.scr = screenMacros
+	m.macInput = macInputNone
+	m.macField.SetValue("")
+	m.macField.Blur()
+	m.layout()
+	m.refreshMacros()
+}
+
+func (m *Model) refreshMacros() {
+	macros := m.cfg.Macros.Macros()
+	items := make([]list.Item, 0, len(macros))
+	for _, mc := range macros {
+		title := fmt.Sprintf("%s (%d passos)", mc.Name, len(mc.Steps))
+		if mc.Name == m.macRec {
+			title += " ●"
+		}
+		var vars []string
+		for _, st := range mc.Steps {
+			for v := range st.Extract {
+				vars = append(vars, v)
+			}
+		}
+		sort.Strings(vars)
+		desc := "sem extrações"
+		if len(vars) > 0 {
+			desc = "extrai " + strings.Join(vars, ", ")
+		}
+		items = append(items, macroItem{name: mc.Name, title: title, desc: desc})
+	}
+	m.macList.SetItems(items)
+	m.updateMacroDetail()
+}
+
+func (m *Model) selectedMacro() (macro.Macro, bool) {
+	it, ok := m.macList.SelectedItem().(macroItem)
+	if !ok {
+		return macro.Macro{}, false
+	}
+	for _, mc := range m.cfg.Macros.Macros() {
+		if mc.Name == it.name {
+			return mc, true
+		}
+	}
+	return macro.Macro{}, false
+}
+
+func (m *Model) updateMacroDetail() {
+	var b strings.Builder
+	if mc, ok := m.selectedMacro(); ok {
+		b.WriteString(m.styles.title.Render("Macro " + mc.Name))
+		b.WriteString("\n")
+		if len(mc.Steps) == 0 {
+			b.WriteString(m.styles.dim.Render("sem passos: selecione fluxos no histórico e pressione R para gravar"))
+			b.WriteString("\n")
+		}
+		for i, st := range mc.Steps {
+			line, _, _ := strings.Cut(st.Request, "\n")
+			b.WriteString(fmt.Sprintf("%d. %s\n", i+1, strings.TrimSpace(line)))
+			names := make([]string, 0, len(st.Extract))
+			for v := range st.Extract {
+				names = append(names, v)
+			}
+			sort.Strings(names)
+			for _, v := range names {
+				b.WriteString(m.styles.dim.Render(fmt.Sprintf("   %s ← %s", v, st.Extract[v])))
+				b.WriteString("\n")
+			}
+		}
+		b.WriteString("\n")
+	}
+
+	b.WriteString(m.styles.title.Render("Variáveis"))
+	b.WriteString("\n")
+	vars := m.cfg.Macros.Vars()
+	names := m.cfg.Macros.VarNames()
+	if len(names) == 0 {
+		b.WriteString(m.styles.dim.Render("nenhuma"))
+		b.WriteString("\n")
+	}
+	for _, k := range names {
+		v := vars[k]
+		if len(v) > 60 {
+			v = v[:57] + "..."
+		}
+		b.WriteString(fmt.Sprintf("%s = %s\n", m.styles.key.Render(k), v))
+	}
+
+	b.WriteString("\n")
+	b.WriteString(m.styles.title.Render("Regras de sessão"))
+	b.WriteString("\n")
+	rules := m.cfg.Macros.Rules()
+	if len(rules) == 0 {
+		b.WriteString(m.styles.dim.Render("nenhuma (u adiciona)"))
+		b.WriteString("\n")
+	}
+	for i, r := range rules {
+		b.WriteString(fmt.Sprintf("%d. %s\n", i+1, r))
+	}
+	m.macView.SetContent(lipgloss.NewStyle().Width(m.macView.Width - 2).Render(b.String()))
+}
+
+func (m *Model) recordMacroStep(f *proxy.Flow) tea.Cmd {
+	name := m.macRec
+	if name == "" {
+		if ms := m.cfg.Macros.Macros(); len(ms) > 0 {
+			name = ms[len(ms)-1].Name
+		} else {
+			name = "login"
+			if err := m.cfg.Macros.AddMacro(name); err != nil {
+				return toastCmd(err.Error())
+			}
+		}
+		m.macRec = name
+	}
+	n, err := m.cfg.Macros.AddStep(name, renderRawRequest(f))
+	if err != nil {
+		return toastCmd(err.Error())
+	}
+	return toastCmd(fmt.Sprintf("passo %d gravado na macro %s (K abre as macros)", n, name))
+}
+
+func (m Model) updateMacros(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.macInput != macInputNone {
+		switch {
+		case key.Matches(msg, m.keys.Back):
+			m.macInput = macInputNone
+			m.macField.SetValue("")
+			m.macField.Blur()
+			return m, nil
+		case key.Matches(msg, m.keys.Apply):
+			text := strings.TrimSpace(m.macField.Value())
+			toast, err := m.applyMacroInput(text)
+			if err != nil {
+				return m, toastCmd(err.Error())
+			}
+			m.macInput = macInputNone
+			m.macField.SetValue("")
+			m.macField.Blur()
+			m.refreshMacros()
+			return m, toastCmd(toast)
+		}
+
+		var cmd tea.Cmd
+		m.macField, cmd = m.macField.Update(msg)
+		return m, cmd
+	}
+
+	switch {
+	case key.Matches(msg, m.keys.Back):
+		m.scr = screenMain
+		m.layout()
+		return m, nil
+	case key.Matches(msg, m.keys.Add):
+		return m, m.startMacroInput(macInputName, "nome da macro")
+	case key.Matches(msg, m.keys.AddExtract):
+		if _, ok := m.selectedMacro(); !ok {
+			return m, nil
+		}
+		return m, m.startMacroInput(macInputExtract, "token=json:$.access_token | csrf=cookie:csrftoken | id=body:REGEX")
+	case key.Matches(msg, m.keys.AddRule):
+		return m, m.startMacroInput(macInputRule, "match=api.exemplo.com|header:Authorization=Bearer {{token}}|on=401|run=login")
+	case key.Matches(msg, m.keys.SetVar):
+		return m, m.startMacroInput(macInputVar, "usuario=alice")
+	case key.Matches(msg, m.keys.Apply):
+		mc, ok := m.selectedMacro()
+		if !ok {
+			return m, nil
+		}
+		if m.macRunning {
+			return m, toastCmd("uma macro já está rodando")
+		}
+		m.macRunning = true
+		return m, tea.Batch(runMacroCmd(m.cfg.Macros, mc.Name), toastCmd("executando macro "+mc.Name))
+	case key.Matches(msg, m.keys.Remove):
+		mc, ok := m.selectedMacro()
+		if !ok {
+			return m, nil
+		}
+		m.cfg.Macros.RemoveMacro(mc.Name)
+		if m.macRec == mc.Name {
+			m.macRec = ""
+		}
+		m.refreshMacros()
+		return m, toastCmd("macro removida: " + mc.Name)
+	case key.Matches(msg, m.keys.Send):
+		if err := m.cfg.Macros.Save(m.cfg.MacrosFile); err != nil {
+			return m, toastCmd("erro ao salvar: " + err.Error())
+		}
+		return m, toastCmd("macros salvas em " + m.cfg.MacrosFile)
+	case key.Matches(msg, m.keys.ScrollUp):
+		m.macView.HalfPageUp()
+		return m, nil
+	case key.Matches(msg, m.keys.ScrollDown):
+		m.macView.HalfPageDown()
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.macList, cmd = m.macList.Update(msg)
+	if mc, ok := m.selectedMacro(); ok {
+		m.macRec = mc.Name
+	}
+	m.refreshMacros()
+	return m, cmd
+}
+
+func (m *Model) startMacroInput(mode macroInput, placeholder string) tea.Cmd {
+	m.macInput = mode
+	m.macField.Placeholder = placeholder
+	m.macField.SetValue("")
+	return m.macField.Focus()
+}
+
+func (m *Model) applyMacroInput(text string) (string, error) {
+	switch m.macInput {
+	case macInputName:
+		if err := m.cfg.Macros.AddMacro(text); err != nil {
+			return "", err
+		}
+		m.macRec = text
+		return "macro criada: " + text + " (R no histórico grava passos)", nil
+	case macInputExtract:
+		mc, _ := m.selectedMacro()
+		v, spec, ok := strings.Cut(text, "=")
+		if !ok {
+			return "", fmt.Errorf("use variavel=origem (cookie:, header:, body: ou json:)")
+		}
+		ex, err := sequencer.ParseExtractor(spec)
+		if err != nil {
+			return "", err
+		}
+		if err := m.cfg.Macros.AddExtract(mc.Name, -1, strings.TrimSpace(v), ex); err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("%s ← %s no último passo de %s", strings.TrimSpace(v), ex, mc.Name), nil
+	case macInputRule:
+		r, err := macro.ParseRule(text)
+		if err != nil {
+			return "", err
+		}
+		m.cfg.Macros.AddRule(r)
+		return "regra adicionada", nil
+	case macInputVar:
+		k, v, ok := strings.Cut(text, "=")
+		if !ok || strings.TrimSpace(k) == "" {
+			return "", fmt.Errorf("use nome=valor")
+		}
+		m.cfg.Macros.SetVar(strings.TrimSpace(k), strings.TrimSpace(v))
+		return "variável definida: " + strings.TrimSpace(k), nil
+	}
+	return "", nil
+}
+
+func (m *Model) finishMacro(msg macroDoneMsg) tea.Cmd {
+	m.macRunning = false
+	if m.scr == screenMacros {
+		m.refreshMacros()
+	}
+	if msg.err != nil {
+		return toastCmd(msg.err.Error())
+	}
+	names := make([]string, 0, len(msg.vars))
+	for k := range msg.vars {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	if len(names) == 0 {
+		return toastCmd("macro " + msg.name + " executada")
+	}
+	return toastCmd("macro " + msg.name + " atualizou " + strings.Join(names, ", "))
+}
+
+func (m Model) viewMacros() string {
+	info := fmt.Sprintf("%d regras", len(m.cfg.Macros.Rules()))
+	if m.macRec != "" {
+		info += " | gravando em " + m.macRec
+	}
+	header := lipgloss.JoinHorizontal(lipgloss.Left,
+		m.styles.title.Render("Macros de sessão"),
+		" ",
+		m.styles.dim.Render(info),
+	)
+	if m.macRunning {
+		header = lipgloss.JoinHorizontal(lipgloss.Left, header, " ", m.styles.badgeWarn.Render("EXECUTANDO"))
+	}
+
+	left := m.styles.border.Width(m.macList.Width() + 2).Height(m.macList.Height()).Render(m.macList.View())
+	right := m.styles.border.Width(m.macView.Width + 2).Height(m.macView.Height).Render(m.macView.View())
+	row := lipgloss.JoinHorizontal(lipgloss.Top, left, right)
+
+	input := m.styles.border.Render(m.macField.View())
+	if m.macInput == macInputNone {
+		input = m.styles.border.Render(m.styles.dim.Render("a nova macro | x extração | u regra | = variável"))
+	}
+	footer := m.viewFooter()
+
+	return m.styles.app.Render(lipgloss.JoinVertical(lipgloss.Left, header, row, input, footer))
+}
@@ -0,0 +1,243 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"burpui/internal/proxy"
+	"burpui/internal/sequencer"
+)
+
+type seqProgressMsg struct {
+	stats sequencer.Stats
+	ch    <-chan sequencer.Stats
+}
+
+type seqDoneMsg struct {
+	tokens []string
+	stats  sequencer.Stats
+	err    error
+}
+
+func (m *Model) openSequencer(f *proxy.Flow) {
+	if !m.seqRunning && m.seqFlowID != f.ID {
+		m.seqFlowID = f.ID
+		m.seqRaw = renderRawRequest(f)
+		m.seqTokens = nil
+		m.seqReport = nil
+		m.seqErr = ""
+		m.seqStats = sequencer.Stats{}
+		m.seqInput.SetValue("")
+		if ex, ok := sequencer.Guess(f.ResponseHeader); ok {
+			m.seqInput.SetValue(ex.String())
+		}
+	}
+	m.seqInput.Focus()
+	m.scr = screenSequencer
+	m.layout()
+	m.refreshSequencer()
+}
+
+func (m Model) updateSequencer(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, m.keys.Back):
+		m.seqInput.Blur()
+		m.scr = screenMain
+		m.layout()
+		return m, nil
+	case key.Matches(msg, m.keys.Apply):
+		if m.seqRunning {
+			m.seqCancel()
+			return m, toastCmd("interrompendo sequencer...")
+		}
+		if m.cfg.Sequence == nil {
+			return m, nil
+		}
+		ex, err := sequencer.ParseExtractor(m.seqInput.Value())
+		if err != nil {
+			return m, toastCmd(err.Error())
+		}
+		ctx, cancel := context.WithCancel(context.Background())
+		ch := make(chan sequencer.Stats, 16)
+		m.seqRunning = true
+		m.seqCancel = cancel
+		m.seqStats = sequencer.Stats{}
+		m.seqTokens = nil
+		m.seqReport = nil
+		m.seqErr = ""
+		m.refreshSequencer()
+		return m, tea.Batch(runSequenceCmd(ctx, m.cfg.Sequence, m.seqRaw, ex, ch), listenForSequence(ch), toastCmd("sequencer: coletando "+ex.String()))
+	case key.Matches(msg, m.keys.Send):
+		if len(m.seqTokens) == 0 {
+			return m, toastCmd("nenhum token coletado")
+		}
+		path, err := exportTokens(m.seqFlowID, m.seqTokens)
+		if err != nil {
+			return m, toastCmd("erro ao exportar")
+		}
+		return m, toastCmd("tokens salvos: " + path)
+	case key.Matches(msg, m.keys.ScrollUp):
+		m.seqView.HalfPageUp()
+		return m, nil
+	case key.Matches(msg, m.keys.ScrollDown):
+		m.seqView.HalfPageDown()
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.seqInput, cmd = m.seqInput.Update(msg)
+	return m, cmd
+}
+
+func (m *Model) finishSequence(msg seqDoneMsg) tea.Cmd {
+	m.seqRunning = false
+	m.seqCancel = nil
+	m.seqStats = msg.stats
+	m.seqTokens = msg.tokens
+	m.seqErr = ""
+	if msg.err != nil && msg.err != context.Canceled {
+		m.seqErr = msg.err.Error()
+	}
+	if r, err := sequencer.Analyze(msg.tokens); err == nil {
+		m.seqReport = &r
+	} else if m.seqErr == "" {
+		m.seqErr = err.Error()
+	}
+	m.refreshSequencer()
+	if m.seqReport != nil {
+		return toastCmd(fmt.Sprintf("sequencer: %d bits efetivos (%s)", m.seqReport.EffectiveBits, m.seqReport.Quality()))
+	}
+	return toastCmd("sequencer: " + msg.stats.String())
+}
+
+func (m *Model) refreshSequencer() {
+	var b strings.Builder
+	if m.seqErr != "" {
+		b.WriteString(m.styles.err.Render(m.seqErr))
+		b.WriteString("\n\n")
+	}
+	r := m.seqReport
+	if r == nil {
+		if m.seqRunning {
+			b.WriteString(m.styles.dim.Render("coletando: " + m.seqStats.String()))
+		} else {
+			b.WriteString(m.styles.dim.Render("Informe de onde tirar o token (cookie:NOME, header:NOME ou body:REGEX) e pressione enter"))
+		}
+		m.seqView.SetContent(b.String())
+		return
+	}
+
+	badge := m.styles.badgeOn
+	if r.EffectiveBits < 64 {
+		badge = m.styles.badgeWarn
+	}
+	b.WriteString(badge.Render(fmt.Sprintf("%d bits efetivos — qualidade %s", r.EffectiveBits, r.Quality())))
+	b.WriteString("\n")
+	size := fmt.Sprintf("%d", r.MinLen)
+	if r.MinLen != r.MaxLen {
+		size = fmt.Sprintf("%d–%d", r.MinLen, r.MaxLen)
+	}
+	b.WriteString(fmt.Sprintf("Tokens: %d | repetidos: %d | tamanho: %s\n", r.Count, r.Duplicates, size))
+	b.WriteString(fmt.Sprintf("Entropia por caractere (Shannon): %.1f bits (no máximo %.1f por posição com esta amostra)\n", r.CharEntropy, math.Log2(float64(r.Count))))
+	for _, w := range r.Warnings {
+		b.WriteString(m.styles.badgeWarn.Render("!") + " " + w + "\n")
+	}
+
+	b.WriteString("\n")
+	b.WriteString(m.styles.dim.Render(fmt.Sprintf("Testes por bit (%d bits, %d amostras cada)", len(r.Bits), r.Count)))
+	b.WriteString("\n")
+	b.WriteString(fmt.Sprintf("monobit: %d falhas | poker: %d | runs: %d | long run: %d | correlacionados: %d\n",
+		r.Failed(func(x sequencer.BitResult) bool { return x.Monobit }),
+		r.Failed(func(x sequencer.BitResult) bool { return x.Poker }),
+		r.Failed(func(x sequencer.BitResult) bool { return x.Runs }),
+		r.Failed(func(x sequencer.BitResult) bool { return x.LongRun }),
+		r.Failed(func(x sequencer.BitResult) bool { return !x.Random() || x.CorrelatedWith < 0 }),
+	))
+
+	b.WriteString("\n")
+	b.WriteString(m.styles.dim.Render("pos  distintos  bits  entropia  bits aprovados"))
+	b.WriteString("\n")
+	bit := 0
+	for _, p := range r.Positions {
+		passed := 0
+		var marks strings.Builder
+		for i := 0; i < p.Bits; i++ {
+			if r.Bits[bit].Pass() {
+				passed++
+				marks.WriteString(m.styles.diffAdd.Render("■"))
+			} else {
+				marks.WriteString(m.styles.diffDel.Render("□"))
+			}
+			bit++
+		}
+		line := fmt.Sprintf("%3d  %9d  %4d  %8.2f  %d/%d ", p.Index, p.Distinct, p.Bits, p.Entropy, passed, p.Bits)
+		if p.Bits == 0 {
+			b.WriteString(m.styles.dim.Render(line + "(constante)"))
+		} else {
+			b.WriteString(line + marks.String())
+		}
+		b.WriteString("\n")
+	}
+	m.seqView.SetContent(b.String())
+}
+
+func (m Model) viewSequencer() string {
+	status := m.seqStats.String()
+	if m.seqRunning {
+		status = m.styles.badgeWarn.Render("COLETANDO") + " " + m.styles.dim.Render(status)
+	} else {
+		status = m.styles.dim.Render(status)
+	}
+	header := lipgloss.JoinHorizontal(lipgloss.Left,
+		m.styles.title.Render(fmt.Sprintf("Sequencer #%d", m.seqFlowID)),
+		" ",
+		status,
+	)
+
+	input := m.styles.border.Render(m.seqInput.View())
+	body := m.styles.border.Render(m.seqView.View())
+	footer := m.viewFooter()
+
+	return m.styles.app.Render(lipgloss.JoinVertical(lipgloss.Left, header, input, body, footer))
+}
+
+func runSequenceCmd(ctx context.Context, seq func(context.Context, string, sequencer.Extractor, func(sequencer.Stats)) ([]string, sequencer.Stats, error), raw string, ex sequencer.Extractor, ch chan sequencer.Stats) tea.Cmd {
+	return func() tea.Msg {
+		toks, st, err := seq(ctx, raw, ex, func(s sequencer.Stats) {
+			select {
+			case ch <- s:
+			default:
+			}
+		})
+		close(ch)
+		return seqDoneMsg{tokens: toks, stats: st, err: err}
+	}
+}
+
+func listenForSequence(ch <-chan sequencer.Stats) tea.Cmd {
+	return func() tea.Msg {
+		s, ok := <-ch
+		if !ok {
+			return nil
+		}
+		return seqProgressMsg{stats: s, ch: ch}
+	}
+}
+
+func exportTokens(id int64, tokens []string) (string, error) {
+	dir := filepath.Join("exports")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, fmt.Sprintf("sequencer-%d-%s.txt", id, time.Now().Format("20060102-150405")))
+	return path, os.WriteFile(path, []byte(strings.Join(tokens, "\n")+"\n"), 0o644)
+}
@@ -0,0 +1,334 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"burpui/internal/crawler"
+	"burpui/internal/sitemap"
+)
+
+type siteItem struct {
+	url   string
+	title string
+	desc  string
+}
+
+func (i siteItem) Title() string       { return i.title }
+func (i siteItem) Description() string { return i.desc }
+func (i siteItem) FilterValue() string { return i.url }
+
+func (m *Model) refreshSiteMap() {
+	selected := ""
+	if it, ok := m.siteList.SelectedItem().(siteItem); ok {
+		selected = it.url
+	}
+
+	var items []list.Item
+	for _, h := range m.siteTree.Hosts() {
+		h.Walk(func(n *sitemap.Node) bool {
+			items = append(items, m.siteItem(n))
+			return m.siteOpen[n.URL]
+		})
+	}
+	m.siteList.Title = fmt.Sprintf("Site map (%d hosts)", len(m.siteTree.Hosts()))
+	m.siteList.SetItems(items)
+	for i, it := range items {
+		if it.(siteItem).url == selected {
+			m.siteList.Select(i)
+			break
+		}
+	}
+	m.updateSiteDetail()
+}
+
+func (m Model) siteItem(n *sitemap.Node) siteItem {
+	icon := " "
+	if n.HasChildren() {
+		icon = "▸"
+		if m.siteOpen[n.URL] {
+			icon = "▾"
+		}
+	}
+	title := strings.Repeat("  ", n.Depth) + icon + " " + n.Name
+	if r, ok := m.scope.Rule(n.URL); ok {
+		if r.Exclude {
+			title += " [-escopo]"
+		} else {
+			title += " [+escopo]"
+		}
+	} else if !m.scope.InScope(n.URL) {
+		title += " [fora]"
+	}
+
+	var desc []string
+	if n.Discovered() {
+		desc = append(desc, "descoberto")
+	} else {
+		desc = append(desc, fmt.Sprintf("%d req", n.Count))
+	}
+	if st := statusSummary(n); st != "" {
+		desc = append(desc, st)
+	}
+	if params := n.ParamList(); len(params) > 0 {
+		desc = append(desc, "params: "+strings.Join(params, ","))
+	}
+	return siteItem{url: n.URL, title: title, desc: strings.Repeat("  ", n.Depth) + "  " + strings.Join(desc, " | ")}
+}
+
+func statusSummary(n *sitemap.Node) string {
+	var parts []string
+	for _, s := range n.StatusList() {
+		if c := n.Statuses[s]; c > 1 {
+			parts = append(parts, fmt.Sprintf("%d×%d", s, c))
+		} else {
+			parts = append(parts, fmt.Sprintf("%d", s))
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+func (m *Model) selectedSiteNode() *sitemap.Node {
+	it, ok := m.siteList.SelectedItem().(siteItem)
+	if !ok {
+		return nil
+	}
+	return m.siteTree.Find(it.url)
+}
+
+func (m *Model) updateSiteDetail() {
+	n := m.selectedSiteNode()
+	if n == nil {
+		m.siteDetail.SetContent(m.styles.dim.Render("Nenhuma requisição capturada ainda"))
+		return
+	}
+
+	var b strings.Builder
+	b.WriteString(m.styles.title.Render(siteNodeURL(n)))
+	b.WriteString("\n")
+	switch r, ok := m.scope.Rule(n.URL); {
+	case ok && r.Exclude:
+		b.WriteString(m.styles.badgeWarn.Render("FORA DO ESCOPO"))
+	case ok:
+		b.WriteString(m.styles.badgeOn.Render("NO ESCOPO"))
+	case m.scope.InScope(n.URL):
+		b.WriteString(m.styles.badgeOff.Render("no escopo"))
+	default:
+		b.WriteString(m.styles.badgeOff.Render("fora do escopo"))
+	}
+	b.WriteString("\n\n")
+	if n.Discovered() {
+		b.WriteString(m.styles.dim.Render("descoberto em respostas, ainda não requisitado"))
+		b.WriteString("\n")
+	} else {
+		b.WriteString(fmt.Sprintf("Requisições: %d\n", n.Count))
+	}
+	if methods := n.MethodList(); len(methods) > 0 {
+		b.WriteString("Métodos: " + strings.Join(methods, ", ") + "\n")
+	}
+	if st := statusSummary(n); st != "" {
+		b.WriteString("Status: " + st + "\n")
+	}
+	if params := n.ParamList(); len(params) > 0 {
+		b.WriteString("Parâmetros: " + strings.Join(params, ", ") + "\n")
+	}
+	if n.HasChildren() {
+		b.WriteString(fmt.Sprintf("Filhos: %d\n", len(n.Children())))
+	}
+	if ids := n.FlowIDs; len(ids) > 0 {
+		if len(ids) > 10 {
+			ids = ids[len(ids)-10:]
+		}
+		var s []string
+		for _, id := range ids {
+			s = append(s, fmt.Sprintf("#%d", id))
+		}
+		b.WriteString("Fluxos: " + strings.Join(s, " ") + "\n")
+	}
+	b.WriteString("\n")
+	b.WriteString(m.styles.dim.Render("Endpoints"))
+	b.WriteString("\n")
+	var eb strings.Builder
+	_ = n.WriteEndpoints(&eb)
+	b.WriteString(eb.String())
+	m.siteDetail.SetContent(b.String())
+}
+
+func (m Model) updateSiteMap(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, m.keys.Back):
+		m.scr = screenMain
+		m.layout()
+		return m, nil
+	case key.Matches(msg, m.keys.Toggle):
+		if n := m.selectedSiteNode(); n != nil && n.HasChildren() {
+			m.siteOpen[n.URL] = !m.siteOpen[n.URL]
+			m.refreshSiteMap()
+		}
+		return m, nil
+	case key.Matches(msg, m.keys.ScopeIn, m.keys.ScopeOut):
+		n := m.selectedSiteNode()
+		if n == nil {
+			return m, nil
+		}
+		exclude := key.Matches(msg, m.keys.ScopeOut)
+		text := ""
+		if r, ok := m.scope.Rule(n.URL); ok && r.Exclude == exclude {
+			m.scope.Remove(n.URL)
+			text = "regra de escopo removida: " + n.URL
+		} else if exclude {
+			m.scope.Exclude(n.URL)
+			text = "fora do escopo: " + n.URL
+		} else {
+			m.scope.Include(n.URL)
+			text = "no escopo: " + n.URL
+		}
+		m.refreshSiteMap()
+		return m, toastCmd(text)
+	case key.Matches(msg, m.keys.Repeater):
+		n := m.selectedSiteNode()
+		if n == nil {
+			return m, nil
+		}
+		raw := ""
+		if f := m.flows[n.LastFlowID()]; f != nil {
+			raw = renderRawRequest(f)
+		} else {
+			u, err := url.Parse(siteNodeURL(n))
+			if err != nil {
+				return m, toastCmd(err.Error())
+			}
+			raw = fmt.Sprintf("GET %s HTTP/1.1\r\nHost: %s\r\n\r\n", u.String(), u.Host)
+		}
+		m.scr = screenRepeater
+		m.editorTitle = "Repeater"
+		m.status = "Ctrl+S envia | Esc volta"
+		m.resp.SetContent("")
+		m.editor.SetValue(raw)
+		m.editor.Focus()
+		m.layout()
+		return m, nil
+	case key.Matches(msg, m.keys.Crawl):
+		if m.crawling {
+			m.crawlCancel()
+			return m, toastCmd("interrompendo crawler...")
+		}
+		n := m.selectedSiteNode()
+		if n == nil || m.cfg.Crawl == nil {
+			return m, nil
+		}
+		seeds := []string{siteNodeURL(n)}
+		for _, e := range n.Endpoints() {
+			if e != n && m.scope.InScope(e.URL) {
+				seeds = append(seeds, siteNodeURL(e))
+			}
+		}
+		ctx, cancel := context.WithCancel(context.Background())
+		ch := make(chan crawler.Stats, 16)
+		m.crawling = true
+		m.crawlCancel = cancel
+		m.crawlStats = crawler.Stats{}
+		return m, tea.Batch(runCrawlCmd(ctx, m.cfg.Crawl, seeds, ch), listenForCrawl(ch), toastCmd("crawler: "+siteNodeURL(n)))
+	case key.Matches(msg, m.keys.Export):
+		n := m.selectedSiteNode()
+		if n == nil {
+			return m, nil
+		}
+		path, err := exportEndpoints(n)
+		if err != nil {
+			return m, toastCmd("erro ao exportar")
+		}
+		return m, toastCmd("exportado: " + path)
+	}
+
+	var cmd tea.Cmd
+	m.siteList, cmd = m.siteList.Update(msg)
+	m.updateSiteDetail()
+	return m, cmd
+}
+
+func (m Model) viewSiteMap() string {
+	header := lipgloss.JoinHorizontal(lipgloss.Left,
+		m.styles.title.Render("Site map"),
+		" ",
+		m.styles.dim.Render(fmt.Sprintf("%d requisições", m.siteTree.Len())),
+	)
+	if m.crawling {
+		header = lipgloss.JoinHorizontal(lipgloss.Left, header, " ", m.styles.badgeWarn.Render("CRAWL"), " ", m.styles.dim.Render(m.crawlStats.String()))
+	}
+
+	left := m.styles.border.Width(m.siteList.Width()).Height(m.siteList.Height()).Render(m.siteList.View())
+	right := m.styles.border.Width(m.siteDetail.Width).Height(m.siteDetail.Height).Render(m.siteDetail.View())
+	row := lipgloss.JoinHorizontal(lipgloss.Top, left, right)
+	footer := m.viewFooter()
+
+	return m.styles.app.Render(lipgloss.JoinVertical(lipgloss.Left, header, row, footer))
+}
+
+type crawlProgressMsg struct {
+	stats crawler.Stats
+	ch    <-chan crawler.Stats
+}
+
+type crawlDoneMsg struct {
+	stats crawler.Stats
+	err   error
+}
+
+func runCrawlCmd(ctx context.Context, crawl func(context.Context, []string, func(crawler.Stats)) (crawler.Stats, error), seeds []string, ch chan crawler.Stats) tea.Cmd {
+	return func() tea.Msg {
+		st, err := crawl(ctx, seeds, func(s crawler.Stats) {
+			select {
+			case ch <- s:
+			default:
+			}
+		})
+		close(ch)
+		return crawlDoneMsg{stats: st, err: err}
+	}
+}
+
+func listenForCrawl(ch <-chan crawler.Stats) tea.Cmd {
+	return func() tea.Msg {
+		s, ok := <-ch
+		if !ok {
+			return nil
+		}
+		return crawlProgressMsg{stats: s, ch: ch}
+	}
+}
+
+func siteNodeURL(n *sitemap.Node) string {
+	if n.Depth == 0 {
+		return n.URL + "/"
+	}
+	return n.URL
+}
+
+func exportEndpoints(n *sitemap.Node) (string, error) {
+	dir := filepath.Join("exports")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+	name := strings.NewReplacer("://", "-", "/", "_", ":", "_").Replace(n.URL)
+	path := filepath.Join(dir, fmt.Sprintf("sitemap-%s-%s.txt", name, time.Now().Format("20060102-150405")))
+	f, err := os.Create(path)
+	if err != nil {
+		return "", err
+	}
+	if err := n.WriteEndpoints(f); err != nil {
+		f.Close()
+		return "", err
+	}
+	return path, f.Close()
+}
@@ -14,6 +14,8 @@ type styles struct {
 	key       lipgloss.Style
 	err       lipgloss.Style
 	dim       lipgloss.Style
+	diffDel   lipgloss.Style
+	diffAdd   lipgloss.Style
 }
 
 func newStyles() styles {
@@ -30,5 +32,7 @@ func newStyles() styles {
 		key:       base.Bold(true).Foreground(lipgloss.Color("81")),
 		err:       base.Foreground(lipgloss.Color("203")),
 		dim:       base.Foreground(lipgloss.Color("244")),
+		diffDel:   base.Foreground(lipgloss.Color("231")).Background(lipgloss.Color("88")),
+		diffAdd:   base.Foreground(lipgloss.Color("231")).Background(lipgloss.Color("22")),
 	}
 }
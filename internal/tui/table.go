@@ -0,0 +1,281 @@
+package tui
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/table"
+	"github.com/charmbracelet/lipgloss"
+
+	"burpui/internal/proxy"
+)
+
+type colID int
+
+const (
+	colFlowID colID = iota
+	colTime
+	colMethod
+	colHost
+	colPath
+	colStatus
+	colLength
+	colMIME
+	colDuration
+	colTags
+)
+
+type column struct {
+	id     colID
+	title  string
+	width  int
+	hidden bool
+}
+
+func defaultColumns() []column {
+	return []column{
+		{id: colFlowID, title: "ID", width: 5},
+		{id: colTime, title: "Hora", width: 8},
+		{id: colMethod, title: "Método", width: 7},
+		{id: colHost, title: "Host", width: 22},
+		{id: colPath, title: "Caminho", width: 32},
+		{id: colStatus, title: "Status", width: 6},
+		{id: colLength, title: "Tam.", width: 8},
+		{id: colMIME, title: "MIME", width: 16},
+		{id: colDuration, title: "Duração", width: 8},
+		{id: colTags, title: "Tags", width: 14},
+	}
+}
+
+func newTable() table.Model {
+	km := table.DefaultKeyMap()
+	km.PageUp = key.NewBinding(key.WithKeys("pgup"), key.WithHelp("pgup", "page up"))
+	km.PageDown = key.NewBinding(key.WithKeys("pgdown", " "), key.WithHelp("pgdn", "page down"))
+	km.HalfPageUp = key.NewBinding(key.WithKeys("ctrl+u"), key.WithHelp("ctrl+u", "½ page up"))
+	km.HalfPageDown = key.NewBinding(key.WithKeys("ctrl+d"), key.WithHelp("ctrl+d", "½ page down"))
+
+	st := table.DefaultStyles()
+	st.Header = st.Header.BorderStyle(lipgloss.NormalBorder()).BorderForeground(lipgloss.Color("238")).BorderBottom(true).Bold(true)
+	st.Selected = st.Selected.Foreground(lipgloss.Color("230")).Background(lipgloss.Color("236")).Bold(false)
+	return table.New(table.WithFocused(true), table.WithKeyMap(km), table.WithStyles(st))
+}
+
+func (m *Model) rebuildTable() {
+	selected := m.selectedFlowID()
+
+	flows := make([]*proxy.Flow, 0, len(m.flows))
+	for _, f := range m.flows {
+		if m.filter.Match(f) {
+			flows = append(flows, f)
+		}
+	}
+	sortID := m.cols[m.sortCol].id
+	sort.SliceStable(flows, func(i, j int) bool {
+		c := compareFlows(flows[i], flows[j], sortID)
+		if c == 0 {
+			return flows[i].ID > flows[j].ID
+		}
+		if m.sortDesc {
+			return c > 0
+		}
+		return c < 0
+	})
+
+	var cols []table.Column
+	for i, c := range m.cols {
+		if c.hidden {
+			continue
+		}
+		title := c.title
+		if i == m.sortCol {
+			if m.sortDesc {
+				title += "▼"
+			} else {
+				title += "▲"
+			}
+		}
+		if i == m.colCursor {
+			title = "›" + title
+		}
+		cols = append(cols, table.Column{Title: title, Width: c.width})
+	}
+
+	rows := make([]table.Row, 0, len(flows))
+	m.tableIDs = m.tableIDs[:0]
+	cursor := 0
+	for _, f := range flows {
+		var row table.Row
+		for _, c := range m.cols {
+			if !c.hidden {
+				row = append(row, cellValue(f, c.id))
+			}
+		}
+		if f.ID == selected {
+			cursor = len(rows)
+		}
+		rows = append(rows, row)
+		m.tableIDs = append(m.tableIDs, f.ID)
+	}
+
+	m.table.SetRows(nil)
+	m.table.SetColumns(cols)
+	m.table.SetRows(rows)
+	m.table.SetCursor(cursor)
+}
+
+func (m *Model) tableSelectedID() int64 {
+	i := m.table.Cursor()
+	if i < 0 || i >= len(m.tableIDs) {
+		return 0
+	}
+	return m.tableIDs[i]
+}
+
+func (m *Model) moveColCursor(delta int) {
+	m.colCursor = (m.colCursor + delta + len(m.cols)) % len(m.cols)
+}
+
+func (m *Model) sortByCursor() {
+	if m.sortCol == m.colCursor {
+		m.sortDesc = !m.sortDesc
+		return
+	}
+	m.sortCol = m.colCursor
+	m.sortDesc = false
+}
+
+func (m *Model) resizeCursor(delta int) {
+	c := &m.cols[m.colCursor]
+	c.width += delta
+	if c.width < 3 {
+		c.width = 3
+	}
+	if c.width > 120 {
+		c.width = 120
+	}
+}
+
+func (m *Model) toggleCursorColumn() bool {
+	visible := 0
+	for _, c := range m.cols {
+		if !c.hidden {
+			visible++
+		}
+	}
+	c := &m.cols[m.colCursor]
+	if !c.hidden && visible == 1 {
+		return false
+	}
+	c.hidden = !c.hidden
+	return true
+}
+
+func (m Model) columnStatus() string {
+	c := m.cols[m.colCursor]
+	state := "visível"
+	if c.hidden {
+		state = "oculta"
+	}
+	return fmt.Sprintf("coluna: %s (%s, %d)", c.title, state, c.width)
+}
+
+func compareFlows(a, b *proxy.Flow, id colID) int {
+	switch id {
+	case colFlowID:
+		return cmpInt(a.ID, b.ID)
+	case colTime:
+		return a.StartedAt.Compare(b.StartedAt)
+	case colStatus:
+		return cmpInt(int64(a.StatusCode), int64(b.StatusCode))
+	case colLength:
+		return cmpInt(flowLength(a), flowLength(b))
+	case colDuration:
+		return cmpInt(int64(a.Duration), int64(b.Duration))
+	}
+	return strings.Compare(strings.ToLower(cellValue(a, id)), strings.ToLower(cellValue(b, id)))
+}
+
+func cmpInt(a, b int64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+func cellValue(f *proxy.Flow, id colID) string {
+	switch id {
+	case colFlowID:
+		return fmt.Sprintf("%d", f.ID)
+	case colTime:
+		return f.StartedAt.Format("15:04:05")
+	case colMethod:
+		return f.Method
+	case colHost:
+		return normalizeHost(f)
+	case colPath:
+		if f.Method == http.MethodConnect {
+			return f.URL
+		}
+		return pathFromURL(f.URL)
+	case colStatus:
+		return statusLabel(f)
+	case colLength:
+		if f.Pending && !f.Tunnel {
+			return ""
+		}
+		return byteSize(flowLength(f))
+	case colMIME:
+		ct := f.ResponseHeader.Get("Content-Type")
+		if i := strings.IndexByte(ct, ';'); i >= 0 {
+			ct = ct[:i]
+		}
+		return strings.TrimSpace(ct)
+	case colDuration:
+		return durationLabel(f)
+	case colTags:
+		return strings.Join(flowTags(f), " ")
+	}
+	return ""
+}
+
+func flowLength(f *proxy.Flow) int64 {
+	if f.Tunnel {
+		return f.BytesReceived
+	}
+	return int64(len(f.ResponseBody))
+}
+
+func flowTags(f *proxy.Flow) []string {
+	var tags []string
+	if f.Intercepted {
+		tags = append(tags, "INT")
+	}
+	if f.MockID != 0 {
+		tags = append(tags, "MOCK")
+	}
+	if f.MappedTo != "" {
+		tags = append(tags, "MAP")
+	}
+	if f.Session != "" {
+		tags = append(tags, "SESSÃO")
+	}
+	if f.Throttle != "" {
+		tags = append(tags, "REDE")
+	}
+	if f.Tunnel {
+		tags = append(tags, "TÚNEL")
+	}
+	if f.ReqTruncated || f.RespTruncated {
+		tags = append(tags, "TRUNC")
+	}
+	if f.Error != "" {
+		tags = append(tags, "ERR")
+	}
+	return tags
+}
@@ -2,6 +2,7 @@ package tui
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"net"
 	"net/http"
@@ -15,24 +16,48 @@ import (
 	"github.com/charmbracelet/bubbles/help"
 	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/table"
 	"github.com/charmbracelet/bubbles/textarea"
 	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 
+	"burpui/internal/authz"
+	"burpui/internal/crawler"
+	"burpui/internal/filter"
+	"burpui/internal/jar"
+	"burpui/internal/jwt"
+	"burpui/internal/macro"
 	"burpui/internal/proxy"
 	"burpui/internal/repeater"
+	"burpui/internal/sequencer"
+	"burpui/internal/sitemap"
 )
 
 type Config struct {
 	ListenAddr   string
 	FlowCh       <-chan *proxy.FlowSnapshot
+	Repeater     repeater.Options
 	SetIntercept func(bool)
+	Throttle     string
 
 	ListBreakpoints  func() []proxy.BreakpointRule
 	AddBreakpoint    func(string)
 	ToggleBreakpoint func(int64)
 	RemoveBreakpoint func(int64)
+
+	ToggleFlowMock func(*proxy.Flow) (bool, error)
+	CycleThrottle  func() string
+
+	FiltersFile string
+	Scope       *sitemap.Scope
+	Crawl       func(ctx context.Context, seeds []string, progress func(crawler.Stats)) (crawler.Stats, error)
+	Sequence    func(ctx context.Context, raw string, ex sequencer.Extractor, progress func(sequencer.Stats)) ([]string, sequencer.Stats, error)
+	Authz       *authz.Matrix
+	Macros      *macro.Engine
+	MacrosFile  string
+	Jar         *jar.Jar
+	JarAttach   bool
 }
 
 type screen int
@@ -43,6 +68,15 @@ const (
 	screenCompose
 	screenEdit
 	screenBreakpoints
+	screenFilters
+	screenSiteMap
+	screenComparer
+	screenDecoder
+	screenJWT
+	screenSequencer
+	screenAuthz
+	screenMacros
+	screenCookies
 )
 
 type Model struct {
@@ -55,11 +89,20 @@ type Model struct {
 	height int
 
 	intercept bool
+	throttle  string
 	flows     map[int64]*proxy.Flow
 	hostOpen  map[string]bool
 	list      list.Model
 	detail    viewport.Model
 
+	tableMode bool
+	table     table.Model
+	tableIDs  []int64
+	cols      []column
+	sortCol   int
+	sortDesc  bool
+	colCursor int
+
 	scr         screen
 	editorTitle string
 	editor      textarea.Model
@@ -70,6 +113,87 @@ type Model struct {
 	bpInput  textarea.Model
 	bpAdding bool
 
+	filter       *filter.Filter
+	filtering    bool
+	filterInput  textarea.Model
+	filterList   list.Model
+	filterAdding bool
+	savedFilters []filter.Saved
+
+	siteTree   *sitemap.Tree
+	scope      *sitemap.Scope
+	siteList   list.Model
+	siteDetail viewport.Model
+	siteOpen   map[string]bool
+
+	crawling    bool
+	crawlStats  crawler.Stats
+	crawlCancel context.CancelFunc
+
+	cmpA        *cmpEntry
+	cmpB        *cmpEntry
+	cmpResponse bool
+	cmpBytes    bool
+	cmpView     viewport.Model
+	rpLastRaw   string
+	rpLast      *repeater.Result
+
+	decInput     textarea.Model
+	decList      list.Model
+	decView      viewport.Model
+	decChain     []string
+	decSources   []decSource
+	decSrc       int
+	decFrom      screen
+	decFlowID    int64
+	decLine      int
+	decListFocus bool
+
+	jwtFound  []foundJWT
+	jwtIdx    int
+	jwtTok    *jwt.Token
+	jwtHeader textarea.Model
+	jwtClaims textarea.Model
+	jwtKey    textarea.Model
+	jwtFocus  int
+	jwtAlg    int
+	jwtOut    viewport.Model
+	jwtFlowID int64
+
+	seqInput   textarea.Model
+	seqView    viewport.Model
+	seqFlowID  int64
+	seqRaw     string
+	seqRunning bool
+	seqCancel  context.CancelFunc
+	seqStats   sequencer.Stats
+	seqTokens  []string
+	seqReport  *sequencer.Report
+	seqErr     string
+
+	azTable      table.Model
+	azView       viewport.Model
+	azInput      textarea.Model
+	azAdding     bool
+	azSuspicious bool
+	azIDs        []int64
+	azBypass     int
+
+	macList    list.Model
+	macView    viewport.Model
+	macField   textarea.Model
+	macInput   macroInput
+	macRec     string
+	macRunning bool
+
+	ckList    list.Model
+	ckField   textarea.Model
+	ckInput   cookieInput
+	ckEdit    jar.Cookie
+	jarAttach bool
+
+	rpInsecure bool
+
 	toast      string
 	toastUntil time.Time
 }
@@ -106,9 +230,9 @@ func (i groupItem) FilterValue() string { return i.host }
 type flowMsg struct{ snap *proxy.FlowSnapshot }
 type toastMsg struct{ text string }
 type rpRespMsg struct {
-	status string
-	body   string
-	err    error
+	raw string
+	res *repeater.Result
+	err error
 }
 
 func New(cfg Config) Model {
@@ -119,6 +243,7 @@ func New(cfg Config) Model {
 	l.Title = "Histórico"
 	l.SetShowHelp(false)
 	l.DisableQuitKeybindings()
+	l.SetFilteringEnabled(false)
 	l.Styles.Title = l.Styles.Title.Foreground(lipgloss.Color("81")).Bold(true)
 	l.Styles.PaginationStyle = l.Styles.PaginationStyle.Foreground(lipgloss.Color("244"))
 	l.Styles.HelpStyle = l.Styles.HelpStyle.Foreground(lipgloss.Color("244"))
@@ -150,25 +275,203 @@ func New(cfg Config) Model {
 	bpi.SetHeight(1)
 	bpi.FocusedStyle.CursorLine = lipgloss.NewStyle().Background(lipgloss.Color("236"))
 
+	fl := list.New([]list.Item{}, list.NewDefaultDelegate(), 0, 0)
+	fl.Title = "Filtros"
+	fl.SetShowHelp(false)
+	fl.DisableQuitKeybindings()
+	fl.Styles.Title = fl.Styles.Title.Foreground(lipgloss.Color("81")).Bold(true)
+	fl.Styles.PaginationStyle = fl.Styles.PaginationStyle.Foreground(lipgloss.Color("244"))
+	fl.Styles.HelpStyle = fl.Styles.HelpStyle.Foreground(lipgloss.Color("244"))
+
+	fi := textarea.New()
+	fi.Placeholder = "host:api.example.com status:>=400 -ext:png dur:>500ms @salvo"
+	fi.Prompt = ""
+	fi.ShowLineNumbers = false
+	fi.SetHeight(1)
+	fi.FocusedStyle.CursorLine = lipgloss.NewStyle().Background(lipgloss.Color("236"))
+
+	sl := list.New([]list.Item{}, list.NewDefaultDelegate(), 0, 0)
+	sl.Title = "Site map"
+	sl.SetShowHelp(false)
+	sl.DisableQuitKeybindings()
+	sl.SetFilteringEnabled(false)
+	sl.Styles.Title = sl.Styles.Title.Foreground(lipgloss.Color("81")).Bold(true)
+	sl.Styles.PaginationStyle = sl.Styles.PaginationStyle.Foreground(lipgloss.Color("244"))
+	sl.Styles.HelpStyle = sl.Styles.HelpStyle.Foreground(lipgloss.Color("244"))
+
+	sd := viewport.New(0, 0)
+	sd.Style = lipgloss.NewStyle().Padding(0, 1)
+
+	cv := viewport.New(0, 0)
+	cv.Style = lipgloss.NewStyle().Padding(0, 1)
+
+	di := textarea.New()
+	di.Placeholder = "Cole o valor a decodificar"
+	di.Prompt = ""
+	di.ShowLineNumbers = false
+	di.FocusedStyle.CursorLine = lipgloss.NewStyle().Background(lipgloss.Color("236"))
+
+	dl := list.New(transformItems(), list.NewDefaultDelegate(), 0, 0)
+	dl.Title = "Transformações"
+	dl.SetShowHelp(false)
+	dl.DisableQuitKeybindings()
+	dl.SetFilteringEnabled(false)
+	dl.Styles.Title = dl.Styles.Title.Foreground(lipgloss.Color("81")).Bold(true)
+	dl.Styles.PaginationStyle = dl.Styles.PaginationStyle.Foreground(lipgloss.Color("244"))
+	dl.Styles.HelpStyle = dl.Styles.HelpStyle.Foreground(lipgloss.Color("244"))
+
+	dv := viewport.New(0, 0)
+	dv.Style = lipgloss.NewStyle().Padding(0, 1)
+
+	newJSONArea := func() textarea.Model {
+		ta := textarea.New()
+		ta.Prompt = ""
+		ta.ShowLineNumbers = false
+		ta.FocusedStyle.CursorLine = lipgloss.NewStyle().Background(lipgloss.Color("236"))
+		return ta
+	}
+	jh, jc := newJSONArea(), newJSONArea()
+
+	jk := textarea.New()
+	jk.Placeholder = "segredo ou @caminho/chave.pem"
+	jk.Prompt = ""
+	jk.ShowLineNumbers = false
+	jk.SetHeight(1)
+	jk.FocusedStyle.CursorLine = lipgloss.NewStyle().Background(lipgloss.Color("236"))
+
+	jo := viewport.New(0, 0)
+	jo.Style = lipgloss.NewStyle().Padding(0, 1)
+
+	si := textarea.New()
+	si.Placeholder = "cookie:SESSIONID | header:X-Token | body:\"token\":\"([^\"]+)\""
+	si.Prompt = ""
+	si.ShowLineNumbers = false
+	si.SetHeight(1)
+	si.FocusedStyle.CursorLine = lipgloss.NewStyle().Background(lipgloss.Color("236"))
+
+	sv := viewport.New(0, 0)
+	sv.Style = lipgloss.NewStyle().Padding(0, 1)
+
+	ai := textarea.New()
+	ai.Placeholder = "admin=Cookie: sid=... | Authorization: Bearer ..."
+	ai.Prompt = ""
+	ai.ShowLineNumbers = false
+	ai.SetHeight(1)
+	ai.FocusedStyle.CursorLine = lipgloss.NewStyle().Background(lipgloss.Color("236"))
+
+	av := viewport.New(0, 0)
+	av.Style = lipgloss.NewStyle().Padding(0, 1)
+
+	ml := list.New([]list.Item{}, list.NewDefaultDelegate(), 0, 0)
+	ml.Title = "Macros"
+	ml.SetShowHelp(false)
+	ml.DisableQuitKeybindings()
+	ml.SetFilteringEnabled(false)
+	ml.Styles.Title = ml.Styles.Title.Foreground(lipgloss.Color("81")).Bold(true)
+	ml.Styles.PaginationStyle = ml.Styles.PaginationStyle.Foreground(lipgloss.Color("244"))
+	ml.Styles.HelpStyle = ml.Styles.HelpStyle.Foreground(lipgloss.Color("244"))
+
+	mv := viewport.New(0, 0)
+	mv.Style = lipgloss.NewStyle().Padding(0, 1)
+
+	mf := textarea.New()
+	mf.Prompt = ""
+	mf.ShowLineNumbers = false
+	mf.SetHeight(1)
+	mf.FocusedStyle.CursorLine = lipgloss.NewStyle().Background(lipgloss.Color("236"))
+
+	ckl := list.New([]list.Item{}, list.NewDefaultDelegate(), 0, 0)
+	ckl.Title = "Cookies"
+	ckl.SetShowHelp(false)
+	ckl.DisableQuitKeybindings()
+	ckl.SetFilteringEnabled(false)
+	ckl.Styles.Title = ckl.Styles.Title.Foreground(lipgloss.Color("81")).Bold(true)
+	ckl.Styles.PaginationStyle = ckl.Styles.PaginationStyle.Foreground(lipgloss.Color("244"))
+	ckl.Styles.HelpStyle = ckl.Styles.HelpStyle.Foreground(lipgloss.Color("244"))
+
+	ckf := textarea.New()
+	ckf.Prompt = ""
+	ckf.ShowLineNumbers = false
+	ckf.SetHeight(1)
+	ckf.FocusedStyle.CursorLine = lipgloss.NewStyle().Background(lipgloss.Color("236"))
+
+	scope := cfg.Scope
+	if scope == nil {
+		scope = sitemap.NewScope()
+	}
+
+	saved, err := filter.LoadSaved(cfg.FiltersFile)
+	toast := ""
+	if err != nil {
+		toast = "filtros: " + err.Error()
+	}
+
 	return Model{
 		cfg:       cfg,
 		styles:    s,
 		keys:      km,
 		help:      help.New(),
 		intercept: false,
+		throttle:  cfg.Throttle,
 		flows:     map[int64]*proxy.Flow{},
 		hostOpen:  map[string]bool{},
 		list:      l,
 		detail:    d,
+		table:     newTable(),
+		cols:      defaultColumns(),
+		sortDesc:  true,
 		scr:       screenMain,
 		editor:    ed,
 		resp:      resp,
 		bpList:    bpl,
 		bpInput:   bpi,
+
+		filterInput:  fi,
+		filterList:   fl,
+		savedFilters: saved,
+
+		siteTree:   sitemap.New(),
+		scope:      scope,
+		siteList:   sl,
+		siteDetail: sd,
+		siteOpen:   map[string]bool{},
+
+		cmpResponse: true,
+		cmpView:     cv,
+
+		decInput: di,
+		decList:  dl,
+		decView:  dv,
+
+		jwtHeader: jh,
+		jwtClaims: jc,
+		jwtKey:    jk,
+		jwtOut:    jo,
+
+		seqInput: si,
+		seqView:  sv,
+
+		azTable: newTable(),
+		azView:  av,
+		azInput: ai,
+
+		macList:  ml,
+		macView:  mv,
+		macField: mf,
+
+		ckList:    ckl,
+		ckField:   ckf,
+		jarAttach: cfg.JarAttach,
+
+		toast:      toast,
+		toastUntil: time.Now().Add(5 * time.Second),
 	}
 }
 
 func (m Model) Init() tea.Cmd {
+	if m.cfg.Authz != nil {
+		return tea.Batch(listenForFlows(m.cfg.FlowCh), listenForAuthz(m.cfg.Authz))
+	}
 	return listenForFlows(m.cfg.FlowCh)
 }
 
@@ -199,14 +502,48 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.flows[msg.snap.Flow.ID] = msg.snap.Flow
 			m.rebuildList()
 			m.updateDetail()
+			if crawler.Feed(m.siteTree, msg.snap.Flow) && m.scr == screenSiteMap {
+				m.refreshSiteMap()
+			}
+			if m.cfg.Authz != nil {
+				m.cfg.Authz.Submit(msg.snap.Flow, renderRawRequest(msg.snap.Flow))
+			}
+			if m.scr == screenCookies && m.ckInput == ckInputNone {
+				m.refreshCookies()
+			}
 		}
 		return m, listenForFlows(m.cfg.FlowCh)
+	case crawlProgressMsg:
+		m.crawlStats = msg.stats
+		return m, listenForCrawl(msg.ch)
+	case crawlDoneMsg:
+		m.crawling = false
+		m.crawlCancel = nil
+		m.crawlStats = msg.stats
+		if msg.err != nil && msg.err != context.Canceled {
+			return m, toastCmd("crawler: " + msg.err.Error())
+		}
+		if msg.err == context.Canceled {
+			return m, toastCmd("crawler interrompido: " + msg.stats.String())
+		}
+		return m, toastCmd("crawler terminou: " + msg.stats.String())
+	case seqProgressMsg:
+		m.seqStats = msg.stats
+		m.refreshSequencer()
+		return m, listenForSequence(msg.ch)
+	case seqDoneMsg:
+		return m, m.finishSequence(msg)
+	case authzUpdateMsg:
+		return m, m.authzUpdated()
+	case macroDoneMsg:
+		return m, m.finishMacro(msg)
 	case rpRespMsg:
 		if msg.err != nil {
 			m.status = "erro: " + msg.err.Error()
 		} else {
-			m.status = msg.status
-			m.resp.SetContent(msg.body)
+			m.status = msg.res.Status
+			m.resp.SetContent(string(msg.res.Body))
+			m.rpLastRaw, m.rpLast = msg.raw, msg.res
 		}
 		return m, nil
 	case tea.KeyMsg:
@@ -219,6 +556,36 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		if m.scr == screenBreakpoints {
 			return m.updateBreakpoints(msg)
 		}
+		if m.scr == screenFilters {
+			return m.updateFilters(msg)
+		}
+		if m.scr == screenSiteMap {
+			return m.updateSiteMap(msg)
+		}
+		if m.scr == screenComparer {
+			return m.updateComparer(msg)
+		}
+		if m.scr == screenDecoder {
+			return m.updateDecoder(msg)
+		}
+		if m.scr == screenJWT {
+			return m.updateJWT(msg)
+		}
+		if m.scr == screenSequencer {
+			return m.updateSequencer(msg)
+		}
+		if m.scr == screenAuthz {
+			return m.updateAuthz(msg)
+		}
+		if m.scr == screenMacros {
+			return m.updateMacros(msg)
+		}
+		if m.scr == screenCookies {
+			return m.updateCookies(msg)
+		}
+		if m.filtering {
+			return m.updateFilterInput(msg)
+		}
 		return m.updateMain(msg)
 	}
 
@@ -232,7 +599,43 @@ func (m Model) updateMain(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	switch {
 	case key.Matches(msg, m.keys.Quit):
 		return m, tea.Quit
-	case key.Matches(msg, m.keys.Toggle):
+	case key.Matches(msg, m.keys.View):
+		m.tableMode = !m.tableMode
+		m.layout()
+		m.rebuildList()
+		m.updateDetail()
+		if m.tableMode {
+			return m, toastCmd("modo tabela")
+		}
+		return m, toastCmd("modo árvore")
+	case m.tableMode && key.Matches(msg, m.keys.ColPrev, m.keys.ColNext):
+		if key.Matches(msg, m.keys.ColPrev) {
+			m.moveColCursor(-1)
+		} else {
+			m.moveColCursor(1)
+		}
+		m.rebuildList()
+		return m, toastCmd(m.columnStatus())
+	case m.tableMode && key.Matches(msg, m.keys.Sort):
+		m.sortByCursor()
+		m.rebuildList()
+		m.updateDetail()
+		return m, nil
+	case m.tableMode && key.Matches(msg, m.keys.Narrow, m.keys.Widen):
+		if key.Matches(msg, m.keys.Narrow) {
+			m.resizeCursor(-2)
+		} else {
+			m.resizeCursor(2)
+		}
+		m.rebuildList()
+		return m, toastCmd(m.columnStatus())
+	case m.tableMode && key.Matches(msg, m.keys.HideCol):
+		if !m.toggleCursorColumn() {
+			return m, toastCmd("pelo menos uma coluna precisa ficar visível")
+		}
+		m.rebuildList()
+		return m, toastCmd(m.columnStatus())
+	case !m.tableMode && key.Matches(msg, m.keys.Toggle):
 		it := m.list.SelectedItem()
 		if gi, ok := it.(groupItem); ok {
 			m.hostOpen[gi.host] = !m.hostOpen[gi.host]
@@ -247,6 +650,15 @@ func (m Model) updateMain(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			m.cfg.SetIntercept(m.intercept)
 		}
 		return m, toastCmd(fmt.Sprintf("Intercept %v", onOff(m.intercept)))
+	case key.Matches(msg, m.keys.Throttle):
+		if m.cfg.CycleThrottle == nil {
+			return m, nil
+		}
+		m.throttle = m.cfg.CycleThrottle()
+		if m.throttle == "" {
+			return m, toastCmd("Rede normal")
+		}
+		return m, toastCmd("Rede: " + m.throttle)
 	case key.Matches(msg, m.keys.Forward):
 		f := m.selectedFlow()
 		if f != nil && f.Intercepted && f.Pending {
@@ -287,6 +699,19 @@ func (m Model) updateMain(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			return m, toastCmd("erro ao exportar")
 		}
 		return m, toastCmd("exportado: " + path)
+	case key.Matches(msg, m.keys.Mock):
+		f := m.selectedFlow()
+		if f == nil || m.cfg.ToggleFlowMock == nil {
+			return m, nil
+		}
+		added, err := m.cfg.ToggleFlowMock(f)
+		if err != nil {
+			return m, toastCmd("mock: " + err.Error())
+		}
+		if added {
+			return m, toastCmd(fmt.Sprintf("mock criado a partir de #%d", f.ID))
+		}
+		return m, toastCmd(fmt.Sprintf("mock de #%d removido", f.ID))
 	case key.Matches(msg, m.keys.Repeater):
 		f := m.selectedFlow()
 		m.scr = screenRepeater
@@ -309,6 +734,102 @@ func (m Model) updateMain(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		m.editor.Focus()
 		m.layout()
 		return m, nil
+	case key.Matches(msg, m.keys.Filter):
+		m.filtering = true
+		m.filterInput.Placeholder = "host:api.example.com status:>=400 -ext:png dur:>500ms @salvo"
+		m.filterInput.SetValue("")
+		if m.filter != nil {
+			m.filterInput.SetValue(m.filter.Expr)
+		}
+		m.filterInput.Focus()
+		m.layout()
+		return m, nil
+	case key.Matches(msg, m.keys.SavedFilters):
+		m.scr = screenFilters
+		m.filterAdding = false
+		m.filterInput.SetValue("")
+		m.filterInput.Blur()
+		m.refreshFilters()
+		m.layout()
+		return m, nil
+	case key.Matches(msg, m.keys.Compare):
+		f := m.selectedFlow()
+		if f == nil {
+			return m, nil
+		}
+		if m.cmpA != nil && m.cmpB == nil && m.cmpA.label == fmt.Sprintf("#%d", f.ID) {
+			return m, toastCmd("escolha outro fluxo para o B")
+		}
+		cmd, ready := m.addToComparer(flowEntry(f))
+		if !ready {
+			return m, cmd
+		}
+		m.scr = screenComparer
+		m.layout()
+		m.refreshComparer()
+		return m, nil
+	case key.Matches(msg, m.keys.Decoder):
+		var sources []decSource
+		m.decFlowID = 0
+		if f := m.selectedFlow(); f != nil {
+			sources = flowSources(f)
+			m.decFlowID = f.ID
+		}
+		m.openDecoder(screenMain, sources)
+		return m, nil
+	case key.Matches(msg, m.keys.JWT):
+		f := m.selectedFlow()
+		if f == nil {
+			return m, nil
+		}
+		found := flowJWTs(f)
+		if len(found) == 0 {
+			return m, toastCmd("nenhum JWT neste fluxo")
+		}
+		m.openJWTEditor(f, found)
+		return m, nil
+	case key.Matches(msg, m.keys.Sequencer):
+		f := m.selectedFlow()
+		if f == nil || f.Method == http.MethodConnect {
+			return m, nil
+		}
+		m.openSequencer(f)
+		return m, nil
+	case key.Matches(msg, m.keys.Macros):
+		if m.cfg.Macros == nil {
+			return m, nil
+		}
+		m.openMacros()
+		return m, nil
+	case key.Matches(msg, m.keys.Record):
+		f := m.selectedFlow()
+		if f == nil || m.cfg.Macros == nil || f.Method == http.MethodConnect {
+			return m, nil
+		}
+		return m, m.recordMacroStep(f)
+	case key.Matches(msg, m.keys.Curl):
+		f := m.selectedFlow()
+		if f == nil || f.Method == http.MethodConnect {
+			return m, nil
+		}
+		return m, copyCurlCmd(fmt.Sprint(f.ID), renderRawRequest(f), false)
+	case key.Matches(msg, m.keys.Cookies):
+		if m.cfg.Jar == nil {
+			return m, nil
+		}
+		m.openCookies()
+		return m, nil
+	case key.Matches(msg, m.keys.Authz):
+		if m.cfg.Authz == nil {
+			return m, nil
+		}
+		m.openAuthz()
+		return m, nil
+	case key.Matches(msg, m.keys.SiteMap):
+		m.scr = screenSiteMap
+		m.layout()
+		m.refreshSiteMap()
+		return m, nil
 	case key.Matches(msg, m.keys.Breakpoints):
 		m.scr = screenBreakpoints
 		m.bpAdding = false
@@ -320,7 +841,11 @@ func (m Model) updateMain(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	}
 
 	var cmd tea.Cmd
-	m.list, cmd = m.list.Update(msg)
+	if m.tableMode {
+		m.table, cmd = m.table.Update(msg)
+	} else {
+		m.list, cmd = m.list.Update(msg)
+	}
 	m.updateDetail()
 	return m, cmd
 }
@@ -329,13 +854,56 @@ func (m Model) updateRepeater(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	switch {
 	case key.Matches(msg, m.keys.Back):
 		m.scr = screenMain
+		m.rpInsecure = false
 		m.editor.Blur()
 		m.layout()
 		return m, nil
 	case key.Matches(msg, m.keys.Send):
 		raw := m.editor.Value()
 		m.status = "enviando..."
-		return m, sendRepeaterCmd(raw)
+		send := repeater.Send
+		if m.cfg.Macros != nil {
+			send = m.cfg.Macros.Send
+		}
+		if m.jarAttach && m.cfg.Jar != nil {
+			send = jarSend(m.cfg.Jar, send)
+		}
+		opts := m.cfg.Repeater
+		if m.rpInsecure {
+			opts = insecureOptions(opts)
+		}
+		return m, sendRepeaterCmd(raw, send, opts)
+	case key.Matches(msg, m.keys.CopyCurl):
+		return m, copyCurlCmd("repeater", m.editor.Value(), m.rpInsecure)
+	case key.Matches(msg, m.keys.ImportCurl):
+		return m, m.importCurl()
+	case key.Matches(msg, m.keys.AttachCookies):
+		if m.cfg.Jar == nil {
+			return m, nil
+		}
+		return m, m.toggleJarAttach()
+	case key.Matches(msg, m.keys.CompareResult):
+		if m.rpLast == nil {
+			return m, toastCmd("envie a requisição antes de comparar")
+		}
+		cmd, ready := m.addToComparer(repeaterEntry(m.rpLastRaw, m.rpLast))
+		if !ready {
+			return m, cmd
+		}
+		m.editor.Blur()
+		m.scr = screenComparer
+		m.layout()
+		m.refreshComparer()
+		return m, nil
+	case key.Matches(msg, m.keys.DecodeLine):
+		lines := strings.Split(m.editor.Value(), "\n")
+		m.decLine = m.editor.Line()
+		line := ""
+		if m.decLine < len(lines) {
+			line = lines[m.decLine]
+		}
+		m.openDecoder(m.scr, lineSources(line))
+		return m, nil
 	}
 
 	var cmd tea.Cmd
@@ -461,10 +1029,10 @@ func (m Model) updateBreakpoints(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	return m, cmd
 }
 
-func sendRepeaterCmd(raw string) tea.Cmd {
+func sendRepeaterCmd(raw string, send func(string, repeater.Options) (*repeater.Result, error), opts repeater.Options) tea.Cmd {
 	return func() tea.Msg {
-		status, body, err := repeater.SendRaw(raw, 15*time.Second)
-		return rpRespMsg{status: status, body: body, err: err}
+		res, err := send(raw, opts)
+		return rpRespMsg{raw: raw, res: res, err: err}
 	}
 }
 
@@ -480,6 +1048,24 @@ func (m Model) View() string {
 		return m.viewEdit()
 	case screenBreakpoints:
 		return m.viewBreakpoints()
+	case screenFilters:
+		return m.viewFilters()
+	case screenSiteMap:
+		return m.viewSiteMap()
+	case screenComparer:
+		return m.viewComparer()
+	case screenDecoder:
+		return m.viewDecoder()
+	case screenJWT:
+		return m.viewJWT()
+	case screenSequencer:
+		return m.viewSequencer()
+	case screenAuthz:
+		return m.viewAuthz()
+	case screenMacros:
+		return m.viewMacros()
+	case screenCookies:
+		return m.viewCookies()
 	default:
 		return m.viewMain()
 	}
@@ -552,6 +1138,99 @@ func (m *Model) layout() {
 		return
 	}
 
+	if m.scr == screenCookies {
+		m.ckList.SetSize(contentW, contentH-5)
+		m.ckField.SetWidth(contentW)
+		return
+	}
+
+	if m.scr == screenFilters {
+		m.filterList.SetSize(contentW, contentH-5)
+		m.filterInput.SetWidth(contentW)
+		return
+	}
+
+	if m.scr == screenComparer {
+		m.cmpView.Width = contentW - 2
+		m.cmpView.Height = contentH - 4
+		return
+	}
+
+	if m.scr == screenDecoder {
+		inputH := 5
+		leftW := 34
+		m.decInput.SetWidth(contentW - 4)
+		m.decInput.SetHeight(inputH)
+		m.decList.SetSize(leftW, contentH-inputH-6)
+		m.decView.Width = contentW - leftW - 4
+		m.decView.Height = contentH - inputH - 6
+		return
+	}
+
+	if m.scr == screenMacros {
+		leftW := 36
+		m.macList.SetSize(leftW-2, contentH-7)
+		m.macView.Width = contentW - leftW - 6
+		m.macView.Height = contentH - 7
+		m.macField.SetWidth(contentW - 4)
+		return
+	}
+
+	if m.scr == screenAuthz {
+		tableH := (contentH - 9) * 3 / 5
+		m.azTable.SetWidth(contentW - 4)
+		m.azTable.SetHeight(tableH)
+		m.azView.Width = contentW - 4
+		m.azView.Height = contentH - 9 - tableH
+		m.azInput.SetWidth(contentW - 4)
+		return
+	}
+
+	if m.scr == screenSequencer {
+		m.seqInput.SetWidth(contentW - 4)
+		m.seqView.Width = contentW - 4
+		m.seqView.Height = contentH - 7
+		return
+	}
+
+	if m.scr == screenJWT {
+		leftW := contentW / 2
+		claimsH := contentH - 17
+		if claimsH < 4 {
+			claimsH = 4
+		}
+		m.jwtHeader.SetWidth(leftW - 4)
+		m.jwtHeader.SetHeight(5)
+		m.jwtClaims.SetWidth(leftW - 4)
+		m.jwtClaims.SetHeight(claimsH)
+		m.jwtKey.SetWidth(leftW - 4)
+		m.jwtOut.Width = contentW - leftW - 4
+		m.jwtOut.Height = contentH - 4
+		return
+	}
+
+	if m.scr == screenSiteMap {
+		leftW := contentW / 2
+		m.siteList.SetSize(leftW, contentH-3)
+		m.siteDetail.Width = contentW - leftW
+		m.siteDetail.Height = contentH - 3
+		return
+	}
+
+	if m.filtering {
+		contentH -= 3
+		m.filterInput.SetWidth(contentW - 4)
+	}
+
+	if m.tableMode {
+		tableH := (contentH - 3) * 3 / 5
+		m.table.SetWidth(contentW - 4)
+		m.table.SetHeight(tableH - 2)
+		m.detail.Width = contentW - 2
+		m.detail.Height = contentH - 3 - tableH - 2
+		return
+	}
+
 	leftW := contentW / 3
 	rightW := contentW - leftW
 	if leftW < 28 {
@@ -571,10 +1250,20 @@ func (m *Model) rebuildList() {
 		flows  []*proxy.Flow
 	}
 
+	if m.tableMode {
+		m.rebuildTable()
+		return
+	}
+
 	selectedKind, selectedHost, selectedID := m.selectedKey()
 
 	byHost := map[string]*group{}
+	shown := 0
 	for _, f := range m.flows {
+		if !m.filter.Match(f) {
+			continue
+		}
+		shown++
 		h := normalizeHost(f)
 		g := byHost[h]
 		if g == nil {
@@ -594,6 +1283,11 @@ func (m *Model) rebuildList() {
 	}
 	sort.Slice(groups, func(i, j int) bool { return groups[i].lastID > groups[j].lastID })
 
+	m.list.Title = "Histórico"
+	if m.filter != nil {
+		m.list.Title = fmt.Sprintf("Histórico (%d/%d)", shown, len(m.flows))
+	}
+
 	items := make([]list.Item, 0, len(m.flows)+len(groups))
 	for _, g := range groups {
 		open := m.hostOpen[g.host]
@@ -605,6 +1299,12 @@ func (m *Model) rebuildList() {
 		for _, f := range g.flows {
 			title := fmt.Sprintf("  %d  %s %s", f.ID, padRight(f.Method, 6), shortURL(pathFromURL(f.URL)))
 			desc := fmt.Sprintf("%s | %s", statusLabel(f), durationLabel(f))
+			if f.Method == http.MethodConnect {
+				title = fmt.Sprintf("  %d  %s %s", f.ID, padRight(f.Method, 6), shortURL(f.URL))
+			}
+			if f.Tunnel {
+				desc += fmt.Sprintf(" | ↑%s ↓%s", byteSize(f.BytesSent), byteSize(f.BytesReceived))
+			}
 			items = append(items, flowItem{id: f.ID, host: g.host, title: title, desc: desc})
 		}
 	}
@@ -656,6 +1356,32 @@ func (m *Model) updateDetail() {
 	b.WriteString(m.styles.title.Render(fmt.Sprintf("#%d", f.ID)))
 	b.WriteString("\n")
 	b.WriteString(fmt.Sprintf("%s %s\n", f.Method, f.URL))
+	if f.MappedTo != "" {
+		b.WriteString(m.styles.dim.Render("mapeado para: " + f.MappedTo))
+		b.WriteString("\n")
+	}
+	if f.Session != "" {
+		b.WriteString(m.styles.dim.Render("sessão: " + f.Session))
+		b.WriteString("\n")
+	}
+	if f.Throttle != "" {
+		b.WriteString(m.styles.dim.Render("rede: " + f.Throttle))
+		b.WriteString("\n")
+	}
+	if f.MockID != 0 {
+		b.WriteString(m.styles.badgeOn.Render(fmt.Sprintf("MOCK #%d", f.MockID)))
+		b.WriteString("\n")
+	}
+	if f.Tunnel {
+		label := "TÚNEL"
+		if f.Passthrough {
+			label = "TÚNEL (passthrough)"
+		}
+		b.WriteString(m.styles.badgeOn.Render(label))
+		b.WriteString(" ")
+		b.WriteString(m.styles.dim.Render(fmt.Sprintf("enviados %s | recebidos %s | %s", byteSize(f.BytesSent), byteSize(f.BytesReceived), durationLabel(f))))
+		b.WriteString("\n")
+	}
 	if f.Intercepted && f.Pending {
 		b.WriteString(m.styles.badgeWarn.Render("PENDENTE"))
 		b.WriteString(" ")
@@ -675,6 +1401,15 @@ func (m *Model) updateDetail() {
 		b.WriteString(renderBodyPreview(f.RequestBody, f.ReqTruncated))
 	}
 	b.WriteString("\n\n")
+	if found := flowJWTs(f); len(found) > 0 {
+		b.WriteString(m.renderJWTs(found))
+		b.WriteString(m.styles.dim.Render("(J abre o editor de JWT)"))
+		b.WriteString("\n\n")
+	}
+	b.WriteString(m.styles.dim.Render("Timing / conexão"))
+	b.WriteString("\n")
+	b.WriteString(renderTiming(f))
+	b.WriteString("\n")
 	b.WriteString(m.styles.dim.Render("Response"))
 	b.WriteString("\n")
 	if f.StatusCode != 0 {
@@ -692,9 +1427,20 @@ func (m Model) viewMain() string {
 	header := m.viewHeader()
 	footer := m.viewFooter()
 
-	left := m.styles.border.Width(m.list.Width()).Height(m.list.Height()).Render(m.list.View())
-	right := m.styles.border.Width(m.detail.Width).Height(m.detail.Height).Render(m.detail.View())
-	row := lipgloss.JoinHorizontal(lipgloss.Top, left, right)
+	var row string
+	if m.tableMode {
+		top := m.styles.border.Render(m.table.View())
+		bottom := m.styles.border.Width(m.detail.Width).Height(m.detail.Height).Render(m.detail.View())
+		row = lipgloss.JoinVertical(lipgloss.Left, top, bottom)
+	} else {
+		left := m.styles.border.Width(m.list.Width()).Height(m.list.Height()).Render(m.list.View())
+		right := m.styles.border.Width(m.detail.Width).Height(m.detail.Height).Render(m.detail.View())
+		row = lipgloss.JoinHorizontal(lipgloss.Top, left, right)
+	}
+	if m.filtering {
+		input := m.styles.border.Render(m.filterInput.View())
+		return m.styles.app.Render(lipgloss.JoinVertical(lipgloss.Left, header, row, input, footer))
+	}
 
 	return m.styles.app.Render(lipgloss.JoinVertical(lipgloss.Left, header, row, footer))
 }
@@ -705,6 +1451,12 @@ func (m Model) viewRepeater() string {
 		" ",
 		m.styles.dim.Render(m.status),
 	)
+	if m.jarAttach && m.cfg.Jar != nil {
+		header = lipgloss.JoinHorizontal(lipgloss.Left, header, " ", m.styles.badgeOn.Render("COOKIES DO JAR"))
+	}
+	if m.rpInsecure {
+		header = lipgloss.JoinHorizontal(lipgloss.Left, header, " ", m.styles.badgeWarn.Render("TLS SEM VERIFICAÇÃO"))
+	}
 
 	editor := m.styles.border.Render(m.editor.View())
 	resp := m.styles.border.Render(m.resp.View())
@@ -750,9 +1502,16 @@ func (m Model) viewHeader() string {
 	if m.intercept {
 		badge = m.styles.badgeOn.Render("INTERCEPT ON")
 	}
+	netBadge := m.styles.badgeOff.Render("REDE OK")
+	if m.throttle != "" {
+		netBadge = m.styles.badgeWarn.Render("REDE " + strings.ToUpper(m.throttle))
+	}
 	title := m.styles.title.Render("burpui")
 	addr := m.styles.dim.Render("proxy: " + m.cfg.ListenAddr)
-	return lipgloss.JoinHorizontal(lipgloss.Left, title, " ", badge, "  ", addr)
+	if fb := m.filterBadge(); fb != "" {
+		return lipgloss.JoinHorizontal(lipgloss.Left, title, " ", badge, " ", netBadge, " ", fb, "  ", addr)
+	}
+	return lipgloss.JoinHorizontal(lipgloss.Left, title, " ", badge, " ", netBadge, "  ", addr)
 }
 
 func (m Model) viewFooter() string {
@@ -763,13 +1522,35 @@ func (m Model) viewFooter() string {
 	} else {
 		switch m.scr {
 		case screenMain:
-			toast = m.renderBar(m.styles.statusDim, "i intercept | enter expande | e edit | f forward | d drop | r repeater | c compose | b breakpoints | x export | q sair")
+			if m.tableMode {
+				toast = m.renderBar(m.styles.statusDim, "v árvore | [ ] coluna | s ordena | - + largura | h mostra/oculta | / filtro | r repeater | x export | q sair")
+				break
+			}
+			toast = m.renderBar(m.styles.statusDim, "v tabela | i intercept | enter expande | e edit | f forward | d drop | r repeater | c compose | b breakpoints | m mock | n rede | / filtro | F filtros | M site map | C comparer | D decoder | J jwt | S sequencer | A authz | K macros | R grava passo | O cookies | Y copia cURL | x export | q sair")
 		case screenRepeater, screenCompose:
-			toast = m.renderBar(m.styles.statusDim, "Ctrl+S envia | Esc volta")
+			toast = m.renderBar(m.styles.statusDim, "Ctrl+S envia | Ctrl+R comparer | Ctrl+O decoder (linha) | Ctrl+T cookies do jar | Ctrl+Y copia cURL | Ctrl+L importa cURL | Esc volta")
+		case screenCookies:
+			toast = m.renderBar(m.styles.statusDim, "a adiciona | e edita | del remove | Ctrl+X esvazia | Ctrl+T anexar no Repeater | esc volta")
+		case screenMacros:
+			toast = m.renderBar(m.styles.statusDim, "enter executa | a nova | x extração | u regra | = variável | del remove | Ctrl+S salva | esc volta")
+		case screenAuthz:
+			toast = m.renderBar(m.styles.statusDim, "enter reenvia | r repeater | a sessão | 1-9 liga/desliga sessão | f só suspeitas | p pausa | x exporta CSV | esc volta")
+		case screenSequencer:
+			toast = m.renderBar(m.styles.statusDim, "enter inicia/para a coleta | Ctrl+S salva os tokens | pgup/pgdn rola | esc volta")
+		case screenJWT:
+			toast = m.renderBar(m.styles.statusDim, "tab header/payload/chave | Ctrl+G alg | Ctrl+X alg none | Ctrl+L próximo token | Ctrl+S manda pro Repeater | esc volta")
+		case screenDecoder:
+			toast = m.renderBar(m.styles.statusDim, "tab foco | enter adiciona | del remove | Ctrl+G auto | Ctrl+L origem | Ctrl+X limpa | Ctrl+S aplica | pgup/pgdn rola | esc volta")
+		case screenComparer:
+			toast = m.renderBar(m.styles.statusDim, "t request/response | w palavras/bytes | s inverte A/B | ↑↓ rola | esc volta")
 		case screenEdit:
 			toast = m.renderBar(m.styles.statusDim, "Ctrl+S aplica/forward | Esc volta")
 		case screenBreakpoints:
 			toast = m.renderBar(m.styles.statusDim, "a add | enter toggle | del remove | esc volta")
+		case screenFilters:
+			toast = m.renderBar(m.styles.statusDim, "enter aplica | a salva | del remove | esc volta")
+		case screenSiteMap:
+			toast = m.renderBar(m.styles.statusDim, "enter expande | r repeater | i inclui no escopo | o exclui do escopo | c crawl | x exporta endpoints | esc volta")
 		default:
 			toast = m.renderBar(m.styles.statusDim, "q sair")
 		}
@@ -820,6 +1601,9 @@ func pathFromURL(raw string) string {
 }
 
 func (m *Model) selectedFlowID() int64 {
+	if m.tableMode {
+		return m.tableSelectedID()
+	}
 	it := m.list.SelectedItem()
 	if it == nil {
 		return 0
@@ -845,7 +1629,7 @@ func exportFlow(f *proxy.Flow) (string, error) {
 	}
 	name := fmt.Sprintf("%d-%s.txt", f.ID, time.Now().Format("20060102-150405"))
 	path := filepath.Join(dir, name)
-	raw := renderRawRequest(f) + "\n\n" + renderRawResponse(f)
+	raw := renderRawRequest(f) + "\n\n" + renderRawResponse(f) + "\n\n" + renderTiming(f)
 	if err := os.WriteFile(path, []byte(raw), 0o644); err != nil {
 		return "", err
 	}
@@ -900,6 +1684,60 @@ func renderRawResponse(f *proxy.Flow) string {
 	return b.String()
 }
 
+func renderTiming(f *proxy.Flow) string {
+	var b strings.Builder
+	t := f.Timing
+	b.WriteString(fmt.Sprintf("DNS: %s | Connect: %s | TLS: %s | Espera (TTFB): %s | Transferência: %s | Total: %s\n",
+		ms(t.DNS), ms(t.Connect), ms(t.TLS), ms(t.Wait), ms(t.Transfer), ms(f.Duration)))
+	if f.ClientAddr != "" {
+		b.WriteString("Cliente: " + f.ClientAddr + "\n")
+	}
+	if f.RemoteAddr != "" {
+		reuse := "nova"
+		if f.ConnReused {
+			reuse = "reusada"
+		}
+		b.WriteString(fmt.Sprintf("Remoto: %s (conexão %s)\n", f.RemoteAddr, reuse))
+	}
+	if f.ClientHello != nil {
+		b.WriteString("ClientHello: " + f.ClientHello.String() + "\n")
+	}
+	if f.TLSVersion != "" {
+		alpn := f.ALPN
+		if alpn == "" {
+			alpn = "-"
+		}
+		b.WriteString(fmt.Sprintf("TLS: %s | %s | ALPN: %s\n", f.TLSVersion, f.TLSCipher, alpn))
+	}
+	for i, c := range f.UpstreamCerts {
+		b.WriteString(fmt.Sprintf("Cert[%d]: %s\n", i, c.Subject))
+		b.WriteString(fmt.Sprintf("  emissor: %s\n", c.Issuer))
+		if len(c.DNSNames) > 0 {
+			b.WriteString("  SANs: " + strings.Join(c.DNSNames, ", ") + "\n")
+		}
+		b.WriteString(fmt.Sprintf("  validade: %s → %s\n", c.NotBefore.Format("2006-01-02"), c.NotAfter.Format("2006-01-02")))
+		b.WriteString("  SHA-256: " + c.SHA256 + "\n")
+	}
+	return b.String()
+}
+
+func byteSize(n int64) string {
+	switch {
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1fMB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.1fKB", float64(n)/(1<<10))
+	}
+	return fmt.Sprintf("%dB", n)
+}
+
+func ms(d time.Duration) string {
+	if d < time.Millisecond {
+		return fmt.Sprintf("%dµs", d.Microseconds())
+	}
+	return fmt.Sprintf("%.1fms", float64(d)/float64(time.Millisecond))
+}
+
 func renderHeaders(h http.Header) string {
 	if h == nil {
 		return ""
@@ -948,6 +1786,12 @@ func statusLabel(f *proxy.Flow) string {
 	if f.Error != "" {
 		return "err"
 	}
+	if f.Tunnel {
+		return "túnel"
+	}
+	if f.Method == http.MethodConnect {
+		return "mitm"
+	}
 	if f.StatusCode != 0 {
 		return fmt.Sprintf("%d", f.StatusCode)
 	}